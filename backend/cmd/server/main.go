@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"miningpet/internal/database"
 	"miningpet/internal/handlers"
@@ -43,6 +44,22 @@ func main() {
 	}
 
 	petService := services.NewPetService()
+	// 先于数据库关闭停止后台任务
+	defer petService.Stop()
+
+	// 可选的外部叙述服务，未配置时使用本地模板叙述事件
+	if narratorURL := os.Getenv("NARRATOR_URL"); narratorURL != "" {
+		timeout := services.DefaultNarratorTimeout
+		if value := os.Getenv("NARRATOR_TIMEOUT"); value != "" {
+			if parsed, err := time.ParseDuration(value); err == nil {
+				timeout = parsed
+			} else {
+				log.Printf("Warning: invalid NARRATOR_TIMEOUT %q, using %v", value, timeout)
+			}
+		}
+		petService.SetNarrator(services.NewHTTPNarrator(narratorURL, timeout, services.NewTemplateNarrator()))
+		log.Printf("Using narrator service at %s", narratorURL)
+	}
 	petHandler := handlers.NewPetHandler(petService)
 	hub := websocket.NewHub(petService)
 
@@ -71,6 +88,8 @@ func main() {
 		api.GET("/pets/:id", petHandler.GetPet)
 		api.GET("/pets/:id/status", petHandler.GetPetStatus)
 		api.GET("/pets/:id/friends", petHandler.GetPetFriends)
+		api.GET("/pets/:id/ledger", petHandler.GetPetLedger)
+		api.GET("/pets/:id/bank", petHandler.GetPetBank)
 		
 		// 宠物行为操作
 		api.POST("/pets/:id/explore", petHandler.StartExploration)
@@ -79,8 +98,66 @@ func main() {
 		api.POST("/pets/:id/socialize", petHandler.SocializePet)
 		api.POST("/pets/:id/command", petHandler.ExecuteCommand)
 		
+		// 主人安排的行动计划
+		api.GET("/pets/:id/queue", petHandler.GetActionQueue)
+		api.POST("/pets/:id/queue", petHandler.EnqueueActions)
+		api.PUT("/pets/:id/queue", petHandler.ReorderActionQueue)
+		api.DELETE("/pets/:id/queue", petHandler.ClearActionQueue)
+		api.DELETE("/pets/:id/queue/:step", petHandler.RemoveQueuedAction)
+		
+		// AI 决策策略
+		api.GET("/ai/strategies", petHandler.GetStrategies)
+		api.PUT("/pets/:id/strategy", petHandler.SetPetStrategy)
+		api.GET("/pets/:id/ai/explain", petHandler.ExplainPetAI)
+		
+		// 主人设定的行为策略
+		api.GET("/pets/:id/policy", petHandler.GetPetPolicy)
+		api.PUT("/pets/:id/policy", petHandler.UpdatePetPolicy)
+		
+		// 主人编写的自动化规则
+		api.GET("/pets/:id/rules", petHandler.GetRules)
+		api.POST("/pets/:id/rules", petHandler.AddRule)
+		api.PUT("/pets/:id/rules/:rule", petHandler.UpdateRule)
+		api.DELETE("/pets/:id/rules/:rule", petHandler.RemoveRule)
+		
+		// 宠物记忆
+		api.GET("/pets/:id/memories", petHandler.GetPetMemories)
+		
+		// 宠物的目标
+		api.PUT("/pets/:id/goal", petHandler.SetPetGoal)
+		api.DELETE("/pets/:id/goal", petHandler.ClearPetGoal)
+		
 		// 事件
 		api.GET("/events", petHandler.GetEvents)
+		
+		// 经济遥测
+		api.GET("/economy/timeseries", petHandler.GetEconomyTimeseries)
+		
+		// 主人钱包与转账
+		api.GET("/wallets/:owner", petHandler.GetWallet)
+		api.POST("/wallets/:owner/deposit", petHandler.DepositToWallet)
+		api.POST("/wallets/:owner/withdraw", petHandler.WithdrawFromWallet)
+		api.POST("/wallets/:owner/transfers", petHandler.TransferCoins)
+		api.GET("/wallets/:owner/transfers", petHandler.GetTransfers)
+		
+		// 拍卖行
+		api.GET("/auctions", petHandler.GetAuctions)
+		api.POST("/auctions", petHandler.CreateAuction)
+		api.GET("/auctions/:id", petHandler.GetAuction)
+		api.POST("/auctions/:id/bids", petHandler.PlaceBid)
+		
+		// 世界
+		api.GET("/world/map", petHandler.GetWorldMap)
+		api.GET("/world/conditions", petHandler.GetWorldConditions)
+		api.GET("/world/boss", petHandler.GetWorldBoss)
+		api.POST("/world/boss", petHandler.SpawnWorldBoss)
+		api.GET("/world/events", petHandler.GetWorldEvents)
+		
+		// 世界事件管理
+		api.GET("/admin/world-events", petHandler.GetWorldEventHistory)
+		api.POST("/admin/world-events", petHandler.CreateWorldEvent)
+		api.POST("/admin/world-events/preview", petHandler.PreviewWorldEvent)
+		api.DELETE("/admin/world-events/:id", petHandler.CancelWorldEvent)
 	}
 
 	r.GET("/ws", hub.HandleWebSocket)
@@ -98,9 +98,12 @@ func (sm *StateManager) UpdatePlayerState(petID string, updateFunc func(*PlayerS
 	sm.mutex.Lock()
 	state, exists := sm.states[petID]
 	if !exists {
+		// GetPlayerState 会自行加锁，先释放避免死锁
+		sm.mutex.Unlock()
 		state = sm.GetPlayerState(petID)
+		sm.mutex.Lock()
 	}
-	
+
 	// 执行更新并获取变更记录
 	changes := updateFunc(state)
 	state.LastActivity = time.Now()
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"miningpet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ActionRepository 进行中行动数据访问层
+type ActionRepository struct {
+	db *gorm.DB
+}
+
+// NewActionRepository 创建行动仓库
+func NewActionRepository() *ActionRepository {
+	return &ActionRepository{db: DB}
+}
+
+// GetActiveActions 获取所有尚未完成的行动
+func (r *ActionRepository) GetActiveActions() ([]*models.PetAction, error) {
+	var rows []DBPetAction
+	if err := r.db.Where("active = ?", true).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active actions: %w", err)
+	}
+
+	actions := make([]*models.PetAction, 0, len(rows))
+	for i := range rows {
+		action, err := ConvertFromDBPetAction(&rows[i])
+		if err != nil {
+			log.Printf("Skipping unreadable action for pet %s: %v", rows[i].PetID, err)
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
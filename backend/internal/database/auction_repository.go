@@ -0,0 +1,91 @@
+package database
+
+import (
+	"fmt"
+	"miningpet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AuctionRepository 拍卖行数据访问层
+// 拍卖与出价随相关宠物的快照一起经由批量写入落库，这里只负责读取
+type AuctionRepository struct {
+	db *gorm.DB
+}
+
+// NewAuctionRepository 创建拍卖仓库
+func NewAuctionRepository() *AuctionRepository {
+	return &AuctionRepository{db: DB}
+}
+
+func convertAuctions(dbAuctions []DBAuction) ([]*models.Auction, error) {
+	auctions := make([]*models.Auction, 0, len(dbAuctions))
+	for i := range dbAuctions {
+		auction, err := ConvertFromDBAuction(&dbAuctions[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert auction %s: %w", dbAuctions[i].ID, err)
+		}
+		auctions = append(auctions, auction)
+	}
+	return auctions, nil
+}
+
+// GetOpenAuctions 获取所有进行中的拍卖
+func (r *AuctionRepository) GetOpenAuctions() ([]*models.Auction, error) {
+	var dbAuctions []DBAuction
+	if err := r.db.Where("status = ?", string(models.AuctionOpen)).Find(&dbAuctions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open auctions: %w", err)
+	}
+	return convertAuctions(dbAuctions)
+}
+
+// GetAuction 根据ID获取拍卖，不存在时返回 nil
+func (r *AuctionRepository) GetAuction(id string) (*models.Auction, error) {
+	var dbAuction DBAuction
+	err := r.db.Where("id = ?", id).First(&dbAuction).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auction: %w", err)
+	}
+	return ConvertFromDBAuction(&dbAuction)
+}
+
+// GetAuctions 按状态分页获取拍卖（按结束时间倒序），status 为空时返回全部
+func (r *AuctionRepository) GetAuctions(status string, limit, offset int) ([]*models.Auction, int64, error) {
+	query := r.db.Model(&DBAuction{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count auctions: %w", err)
+	}
+
+	var dbAuctions []DBAuction
+	if err := query.Order("ends_at DESC").Limit(limit).Offset(offset).Find(&dbAuctions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get auctions: %w", err)
+	}
+
+	auctions, err := convertAuctions(dbAuctions)
+	if err != nil {
+		return nil, 0, err
+	}
+	return auctions, total, nil
+}
+
+// GetBids 获取拍卖的出价记录（按时间倒序）
+func (r *AuctionRepository) GetBids(auctionID string) ([]*models.Bid, error) {
+	var dbBids []DBAuctionBid
+	if err := r.db.Where("auction_id = ?", auctionID).Order("created_at DESC").Find(&dbBids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bids: %w", err)
+	}
+
+	bids := make([]*models.Bid, len(dbBids))
+	for i := range dbBids {
+		bids[i] = ConvertFromDBAuctionBid(&dbBids[i])
+	}
+	return bids, nil
+}
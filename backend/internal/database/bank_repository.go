@@ -0,0 +1,61 @@
+package database
+
+import (
+	"fmt"
+	"miningpet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BankRepository 银行数据访问层
+// 账户与贷款的修改随宠物快照一起经由批量写入落库，这里只负责读取
+type BankRepository struct {
+	db *gorm.DB
+}
+
+// NewBankRepository 创建银行仓库
+func NewBankRepository() *BankRepository {
+	return &BankRepository{db: DB}
+}
+
+// GetAllAccounts 获取所有银行账户
+func (r *BankRepository) GetAllAccounts() ([]*models.BankAccount, error) {
+	var dbAccounts []DBBankAccount
+	if err := r.db.Find(&dbAccounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bank accounts: %w", err)
+	}
+
+	accounts := make([]*models.BankAccount, len(dbAccounts))
+	for i := range dbAccounts {
+		accounts[i] = ConvertFromDBBankAccount(&dbAccounts[i])
+	}
+	return accounts, nil
+}
+
+// GetOpenLoans 获取所有未还清的贷款
+func (r *BankRepository) GetOpenLoans() ([]*models.Loan, error) {
+	var dbLoans []DBLoan
+	if err := r.db.Where("status <> ?", string(models.LoanRepaid)).Find(&dbLoans).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open loans: %w", err)
+	}
+
+	loans := make([]*models.Loan, len(dbLoans))
+	for i := range dbLoans {
+		loans[i] = ConvertFromDBLoan(&dbLoans[i])
+	}
+	return loans, nil
+}
+
+// GetLoansByPet 获取宠物最近的贷款记录
+func (r *BankRepository) GetLoansByPet(petID string, limit int) ([]*models.Loan, error) {
+	var dbLoans []DBLoan
+	if err := r.db.Where("pet_id = ?", petID).Order("issued_at DESC").Limit(limit).Find(&dbLoans).Error; err != nil {
+		return nil, fmt.Errorf("failed to get loans: %w", err)
+	}
+
+	loans := make([]*models.Loan, len(dbLoans))
+	for i := range dbLoans {
+		loans[i] = ConvertFromDBLoan(&dbLoans[i])
+	}
+	return loans, nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"fmt"
+	"miningpet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BossRepository 世界首领数据访问层
+type BossRepository struct {
+	db *gorm.DB
+}
+
+// NewBossRepository 创建世界首领仓库
+func NewBossRepository() *BossRepository {
+	return &BossRepository{db: DB}
+}
+
+// GetActiveBoss 获取进行中的首领战，没有时返回 nil
+func (r *BossRepository) GetActiveBoss() (*models.WorldBoss, error) {
+	var dbBoss DBWorldBoss
+	err := r.db.Where("status = ?", string(models.BossActive)).Order("spawned_at DESC").First(&dbBoss).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active boss: %w", err)
+	}
+	return ConvertFromDBWorldBoss(&dbBoss), nil
+}
+
+// GetRecentBosses 获取最近的首领战
+func (r *BossRepository) GetRecentBosses(limit int) ([]*models.WorldBoss, error) {
+	var dbBosses []DBWorldBoss
+	if err := r.db.Order("spawned_at DESC").Limit(limit).Find(&dbBosses).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bosses: %w", err)
+	}
+
+	bosses := make([]*models.WorldBoss, len(dbBosses))
+	for i := range dbBosses {
+		bosses[i] = ConvertFromDBWorldBoss(&dbBosses[i])
+	}
+	return bosses, nil
+}
+
+// GetContributions 获取首领战的伤害贡献（按伤害倒序）
+func (r *BossRepository) GetContributions(bossID string) ([]*models.BossContribution, error) {
+	var rows []DBBossContribution
+	if err := r.db.Where("boss_id = ?", bossID).Order("damage DESC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get boss contributions: %w", err)
+	}
+
+	contributions := make([]*models.BossContribution, len(rows))
+	for i := range rows {
+		contributions[i] = ConvertFromDBBossContribution(&rows[i])
+	}
+	return contributions, nil
+}
+
+// SaveBossState 在同一事务中保存首领状态及伤害贡献
+func (r *BossRepository) SaveBossState(boss *models.WorldBoss, contributions []*models.BossContribution) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(ConvertToDBWorldBoss(boss)).Error; err != nil {
+			return fmt.Errorf("failed to save boss: %w", err)
+		}
+		for _, contribution := range contributions {
+			if err := tx.Save(ConvertToDBBossContribution(contribution)).Error; err != nil {
+				return fmt.Errorf("failed to save boss contribution: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"encoding/json"
 	"miningpet/internal/models"
 	"time"
 )
@@ -12,6 +13,7 @@ func ConvertToDBPet(pet *models.Pet) (*DBPet, error) {
 		Name:         pet.Name,
 		Owner:        pet.Owner,
 		Personality:  string(pet.Personality),
+		Strategy:     pet.Strategy,
 		Level:        pet.Level,
 		Experience:   pet.Experience,
 		Health:       pet.Health,
@@ -27,16 +29,45 @@ func ConvertToDBPet(pet *models.Pet) (*DBPet, error) {
 		Location:     pet.Location,
 		Status:       string(pet.Status),
 		LastActivity: pet.LastActivity,
+		SimulatedAt:  pet.SimulatedAt,
 		CreatedAt:    pet.CreatedAt,
 		UpdatedAt:    time.Now(),
 	}
 
 	// 设置JSON字段
-	if err := dbPet.SetMemory(pet.Memory); err != nil {
+	if err := dbPet.SetFriends(pet.Friends); err != nil {
 		return nil, err
 	}
 
-	if err := dbPet.SetFriends(pet.Friends); err != nil {
+	if err := dbPet.SetInventory(pet.Inventory); err != nil {
+		return nil, err
+	}
+
+	if err := dbPet.SetActionQueue(pet.ActionQueue); err != nil {
+		return nil, err
+	}
+
+	if err := dbPet.SetRules(pet.Rules); err != nil {
+		return nil, err
+	}
+
+	if err := dbPet.SetEmotions(pet.Emotions); err != nil {
+		return nil, err
+	}
+
+	if err := dbPet.SetLearned(pet.Learned); err != nil {
+		return nil, err
+	}
+
+	if err := dbPet.SetGoal(pet.Goal); err != nil {
+		return nil, err
+	}
+
+	if err := dbPet.SetPolicy(pet.Policy); err != nil {
+		return nil, err
+	}
+
+	if err := dbPet.SetTraits(pet.Traits); err != nil {
 		return nil, err
 	}
 
@@ -45,12 +76,47 @@ func ConvertToDBPet(pet *models.Pet) (*DBPet, error) {
 
 // ConvertFromDBPet 将数据库模型转换为内存宠物模型
 func ConvertFromDBPet(dbPet *DBPet) (*models.Pet, error) {
-	memory, err := dbPet.GetMemory()
+	friends, err := dbPet.GetFriends()
 	if err != nil {
 		return nil, err
 	}
 
-	friends, err := dbPet.GetFriends()
+	inventory, err := dbPet.GetInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	actionQueue, err := dbPet.GetActionQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := dbPet.GetRules()
+	if err != nil {
+		return nil, err
+	}
+
+	emotions, err := dbPet.GetEmotions()
+	if err != nil {
+		return nil, err
+	}
+
+	learned, err := dbPet.GetLearned()
+	if err != nil {
+		return nil, err
+	}
+
+	goal, err := dbPet.GetGoal()
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := dbPet.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	traits, err := dbPet.GetTraits()
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +126,9 @@ func ConvertFromDBPet(dbPet *DBPet) (*models.Pet, error) {
 		Name:         dbPet.Name,
 		Owner:        dbPet.Owner,
 		Personality:  models.PetPersonality(dbPet.Personality),
+		Traits:       traits,
+		Strategy:     dbPet.Strategy,
+		Policy:       policy,
 		Level:        dbPet.Level,
 		Experience:   dbPet.Experience,
 		Health:       dbPet.Health,
@@ -69,14 +138,21 @@ func ConvertFromDBPet(dbPet *DBPet) (*models.Pet, error) {
 		Hunger:       dbPet.Hunger,
 		Social:       dbPet.Social,
 		Mood:         models.PetMood(dbPet.Mood),
+		Emotions:     emotions,
 		Attack:       dbPet.Attack,
 		Defense:      dbPet.Defense,
 		Coins:        dbPet.Coins,
 		Location:     dbPet.Location,
 		Status:       models.PetStatus(dbPet.Status),
-		Memory:       memory,
+		Memories:     make([]models.Memory, 0),
 		Friends:      friends,
+		Inventory:    inventory,
+		ActionQueue:  actionQueue,
+		Rules:        rules,
+		Learned:      learned,
+		Goal:         goal,
 		LastActivity: dbPet.LastActivity,
+		SimulatedAt:  dbPet.SimulatedAt,
 		CreatedAt:    dbPet.CreatedAt,
 	}
 
@@ -152,4 +228,400 @@ func ConvertFromDBEvent(dbEvent *DBEvent) (*models.Event, error) {
 	}
 
 	return event, nil
-}
\ No newline at end of file
+}
+
+// ConvertToDBLedgerEntry 将账本分录转换为数据库模型
+func ConvertToDBLedgerEntry(entry *models.LedgerEntry) *DBLedgerEntry {
+	return &DBLedgerEntry{
+		ID:            entry.ID,
+		PetID:         entry.PetID,
+		DebitAccount:  string(entry.Debit),
+		CreditAccount: string(entry.Credit),
+		Amount:        entry.Amount,
+		Reason:        string(entry.Reason),
+		Memo:          entry.Memo,
+		CreatedAt:     entry.CreatedAt,
+	}
+}
+
+// ConvertFromDBLedgerEntry 将数据库模型转换为账本分录
+func ConvertFromDBLedgerEntry(dbEntry *DBLedgerEntry) *models.LedgerEntry {
+	return &models.LedgerEntry{
+		ID:        dbEntry.ID,
+		PetID:     dbEntry.PetID,
+		Debit:     models.LedgerAccount(dbEntry.DebitAccount),
+		Credit:    models.LedgerAccount(dbEntry.CreditAccount),
+		Amount:    dbEntry.Amount,
+		Reason:    models.LedgerReason(dbEntry.Reason),
+		Memo:      dbEntry.Memo,
+		CreatedAt: dbEntry.CreatedAt,
+	}
+}
+
+// ConvertFromDBWallet 将数据库模型转换为钱包
+func ConvertFromDBWallet(dbWallet *DBWallet) *models.Wallet {
+	return &models.Wallet{
+		Owner:     dbWallet.Owner,
+		Balance:   dbWallet.Balance,
+		UpdatedAt: dbWallet.UpdatedAt,
+	}
+}
+
+// ConvertToDBTransfer 将转账记录转换为数据库模型
+func ConvertToDBTransfer(transfer *models.Transfer) *DBTransfer {
+	return &DBTransfer{
+		ID:        transfer.ID,
+		FromOwner: transfer.FromOwner,
+		ToOwner:   transfer.ToOwner,
+		Amount:    transfer.Amount,
+		Fee:       transfer.Fee,
+		Memo:      transfer.Memo,
+		CreatedAt: transfer.CreatedAt,
+	}
+}
+
+// ConvertFromDBTransfer 将数据库模型转换为转账记录
+func ConvertFromDBTransfer(dbTransfer *DBTransfer) *models.Transfer {
+	return &models.Transfer{
+		ID:        dbTransfer.ID,
+		FromOwner: dbTransfer.FromOwner,
+		ToOwner:   dbTransfer.ToOwner,
+		Amount:    dbTransfer.Amount,
+		Fee:       dbTransfer.Fee,
+		Memo:      dbTransfer.Memo,
+		CreatedAt: dbTransfer.CreatedAt,
+	}
+}
+
+// ConvertToDBBankAccount 将银行账户转换为数据库模型
+func ConvertToDBBankAccount(account *models.BankAccount) *DBBankAccount {
+	return &DBBankAccount{
+		PetID:          account.PetID,
+		Balance:        account.Balance,
+		InterestEarned: account.InterestEarned,
+		LastInterestAt: account.LastInterestAt,
+		UpdatedAt:      account.UpdatedAt,
+	}
+}
+
+// ConvertFromDBBankAccount 将数据库模型转换为银行账户
+func ConvertFromDBBankAccount(dbAccount *DBBankAccount) *models.BankAccount {
+	return &models.BankAccount{
+		PetID:          dbAccount.PetID,
+		Balance:        dbAccount.Balance,
+		InterestEarned: dbAccount.InterestEarned,
+		LastInterestAt: dbAccount.LastInterestAt,
+		UpdatedAt:      dbAccount.UpdatedAt,
+	}
+}
+
+// ConvertToDBLoan 将贷款转换为数据库模型
+func ConvertToDBLoan(loan *models.Loan) *DBLoan {
+	dbLoan := &DBLoan{
+		ID:            loan.ID,
+		PetID:         loan.PetID,
+		Principal:     loan.Principal,
+		Outstanding:   loan.Outstanding,
+		DailyRate:     loan.DailyRate,
+		Status:        string(loan.Status),
+		IssuedAt:      loan.IssuedAt,
+		DueAt:         loan.DueAt,
+		LastAccruedAt: loan.LastAccruedAt,
+	}
+	if loan.RepaidAt != nil {
+		repaidAt := *loan.RepaidAt
+		dbLoan.RepaidAt = &repaidAt
+	}
+	return dbLoan
+}
+
+// ConvertFromDBLoan 将数据库模型转换为贷款
+func ConvertFromDBLoan(dbLoan *DBLoan) *models.Loan {
+	return &models.Loan{
+		ID:            dbLoan.ID,
+		PetID:         dbLoan.PetID,
+		Principal:     dbLoan.Principal,
+		Outstanding:   dbLoan.Outstanding,
+		DailyRate:     dbLoan.DailyRate,
+		Status:        models.LoanStatus(dbLoan.Status),
+		IssuedAt:      dbLoan.IssuedAt,
+		DueAt:         dbLoan.DueAt,
+		LastAccruedAt: dbLoan.LastAccruedAt,
+		RepaidAt:      dbLoan.RepaidAt,
+	}
+}
+
+// ConvertToDBAuction 将拍卖转换为数据库模型
+func ConvertToDBAuction(auction *models.Auction) (*DBAuction, error) {
+	item, err := json.Marshal(auction.Item)
+	if err != nil {
+		return nil, err
+	}
+
+	dbAuction := &DBAuction{
+		ID:                auction.ID,
+		SellerID:          auction.SellerID,
+		SellerName:        auction.SellerName,
+		Item:              string(item),
+		StartingBid:       auction.StartingBid,
+		BuyoutPrice:       auction.BuyoutPrice,
+		CurrentBid:        auction.CurrentBid,
+		HighestBidderID:   auction.HighestBidderID,
+		HighestBidderName: auction.HighestBidderName,
+		BidCount:          auction.BidCount,
+		Commission:        auction.Commission,
+		Status:            string(auction.Status),
+		CreatedAt:         auction.CreatedAt,
+		EndsAt:            auction.EndsAt,
+	}
+	if auction.SettledAt != nil {
+		settledAt := *auction.SettledAt
+		dbAuction.SettledAt = &settledAt
+	}
+	return dbAuction, nil
+}
+
+// ConvertFromDBAuction 将数据库模型转换为拍卖
+func ConvertFromDBAuction(dbAuction *DBAuction) (*models.Auction, error) {
+	var item models.Item
+	if err := json.Unmarshal([]byte(dbAuction.Item), &item); err != nil {
+		return nil, err
+	}
+
+	return &models.Auction{
+		ID:                dbAuction.ID,
+		SellerID:          dbAuction.SellerID,
+		SellerName:        dbAuction.SellerName,
+		Item:              item,
+		StartingBid:       dbAuction.StartingBid,
+		BuyoutPrice:       dbAuction.BuyoutPrice,
+		CurrentBid:        dbAuction.CurrentBid,
+		HighestBidderID:   dbAuction.HighestBidderID,
+		HighestBidderName: dbAuction.HighestBidderName,
+		BidCount:          dbAuction.BidCount,
+		Commission:        dbAuction.Commission,
+		Status:            models.AuctionStatus(dbAuction.Status),
+		CreatedAt:         dbAuction.CreatedAt,
+		EndsAt:            dbAuction.EndsAt,
+		SettledAt:         dbAuction.SettledAt,
+	}, nil
+}
+
+// ConvertToDBAuctionBid 将出价记录转换为数据库模型
+func ConvertToDBAuctionBid(bid *models.Bid) *DBAuctionBid {
+	return &DBAuctionBid{
+		ID:        bid.ID,
+		AuctionID: bid.AuctionID,
+		PetID:     bid.PetID,
+		PetName:   bid.PetName,
+		Amount:    bid.Amount,
+		Buyout:    bid.Buyout,
+		CreatedAt: bid.CreatedAt,
+	}
+}
+
+// ConvertFromDBAuctionBid 将数据库模型转换为出价记录
+func ConvertFromDBAuctionBid(dbBid *DBAuctionBid) *models.Bid {
+	return &models.Bid{
+		ID:        dbBid.ID,
+		AuctionID: dbBid.AuctionID,
+		PetID:     dbBid.PetID,
+		PetName:   dbBid.PetName,
+		Amount:    dbBid.Amount,
+		Buyout:    dbBid.Buyout,
+		CreatedAt: dbBid.CreatedAt,
+	}
+}
+
+// ConvertToDBWorldBoss 将世界首领转换为数据库模型
+func ConvertToDBWorldBoss(boss *models.WorldBoss) *DBWorldBoss {
+	dbBoss := &DBWorldBoss{
+		ID:         boss.ID,
+		TemplateID: boss.TemplateID,
+		Name:       boss.Name,
+		Location:   boss.Location,
+		MaxHP:      boss.MaxHP,
+		HP:         boss.HP,
+		Attack:     boss.Attack,
+		Defense:    boss.Defense,
+		RewardPool: boss.RewardPool,
+		ExpPool:    boss.ExpPool,
+		Status:     string(boss.Status),
+		SpawnedAt:  boss.SpawnedAt,
+		ExpiresAt:  boss.ExpiresAt,
+	}
+	if boss.EndedAt != nil {
+		endedAt := *boss.EndedAt
+		dbBoss.EndedAt = &endedAt
+	}
+	return dbBoss
+}
+
+// ConvertFromDBWorldBoss 将数据库模型转换为世界首领
+func ConvertFromDBWorldBoss(dbBoss *DBWorldBoss) *models.WorldBoss {
+	return &models.WorldBoss{
+		ID:         dbBoss.ID,
+		TemplateID: dbBoss.TemplateID,
+		Name:       dbBoss.Name,
+		Location:   dbBoss.Location,
+		MaxHP:      dbBoss.MaxHP,
+		HP:         dbBoss.HP,
+		Attack:     dbBoss.Attack,
+		Defense:    dbBoss.Defense,
+		RewardPool: dbBoss.RewardPool,
+		ExpPool:    dbBoss.ExpPool,
+		Status:     models.BossStatus(dbBoss.Status),
+		SpawnedAt:  dbBoss.SpawnedAt,
+		ExpiresAt:  dbBoss.ExpiresAt,
+		EndedAt:    dbBoss.EndedAt,
+	}
+}
+
+// ConvertToDBBossContribution 将伤害贡献转换为数据库模型
+func ConvertToDBBossContribution(contribution *models.BossContribution) *DBBossContribution {
+	return &DBBossContribution{
+		BossID:  contribution.BossID,
+		PetID:   contribution.PetID,
+		PetName: contribution.PetName,
+		Owner:   contribution.Owner,
+		Damage:  contribution.Damage,
+		Hits:    contribution.Hits,
+		Reward:  contribution.Reward,
+	}
+}
+
+// ConvertFromDBBossContribution 将数据库模型转换为伤害贡献
+func ConvertFromDBBossContribution(dbContribution *DBBossContribution) *models.BossContribution {
+	return &models.BossContribution{
+		BossID:  dbContribution.BossID,
+		PetID:   dbContribution.PetID,
+		PetName: dbContribution.PetName,
+		Owner:   dbContribution.Owner,
+		Damage:  dbContribution.Damage,
+		Hits:    dbContribution.Hits,
+		Reward:  dbContribution.Reward,
+	}
+}
+
+// ConvertToDBWorldEvent 将世界事件转换为数据库模型
+func ConvertToDBWorldEvent(event *models.WorldEvent) (*DBWorldEvent, error) {
+	modifiers, err := json.Marshal(event.Modifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DBWorldEvent{
+		ID:          event.ID,
+		CalendarID:  event.CalendarID,
+		Name:        event.Name,
+		Description: event.Description,
+		Location:    event.Location,
+		Modifiers:   string(modifiers),
+		Status:      string(event.Status),
+		StartAt:     event.StartAt,
+		EndAt:       event.EndAt,
+		CreatedBy:   event.CreatedBy,
+		CreatedAt:   event.CreatedAt,
+		UpdatedAt:   event.UpdatedAt,
+	}, nil
+}
+
+// ConvertFromDBWorldEvent 将数据库模型转换为世界事件
+func ConvertFromDBWorldEvent(dbEvent *DBWorldEvent) (*models.WorldEvent, error) {
+	var modifiers models.WorldEventModifiers
+	if dbEvent.Modifiers != "" {
+		if err := json.Unmarshal([]byte(dbEvent.Modifiers), &modifiers); err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.WorldEvent{
+		ID:          dbEvent.ID,
+		CalendarID:  dbEvent.CalendarID,
+		Name:        dbEvent.Name,
+		Description: dbEvent.Description,
+		Location:    dbEvent.Location,
+		Modifiers:   modifiers,
+		Status:      models.WorldEventStatus(dbEvent.Status),
+		StartAt:     dbEvent.StartAt,
+		EndAt:       dbEvent.EndAt,
+		CreatedBy:   dbEvent.CreatedBy,
+		CreatedAt:   dbEvent.CreatedAt,
+		UpdatedAt:   dbEvent.UpdatedAt,
+	}, nil
+}
+
+// ConvertToDBPetAction 将进行中的行动转换为数据库模型，finishedAt 为空表示仍在进行
+func ConvertToDBPetAction(action *models.PetAction, finishedAt *time.Time) (*DBPetAction, error) {
+	params, err := json.Marshal(action.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DBPetAction{
+		PetID:      action.PetID,
+		ID:         action.ID,
+		Type:       action.Type,
+		Status:     string(action.Status),
+		Reason:     action.Reason,
+		Params:     string(params),
+		StartedAt:  action.StartedAt,
+		Duration:   action.Duration,
+		Active:     finishedAt == nil,
+		FinishedAt: finishedAt,
+	}, nil
+}
+
+// ConvertFromDBPetAction 将数据库模型转换为进行中的行动
+func ConvertFromDBPetAction(dbAction *DBPetAction) (*models.PetAction, error) {
+	var params map[string]interface{}
+	if dbAction.Params != "" {
+		if err := json.Unmarshal([]byte(dbAction.Params), &params); err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.PetAction{
+		ID:        dbAction.ID,
+		PetID:     dbAction.PetID,
+		Type:      dbAction.Type,
+		Status:    models.PetStatus(dbAction.Status),
+		Reason:    dbAction.Reason,
+		Params:    params,
+		StartedAt: dbAction.StartedAt,
+		Duration:  dbAction.Duration,
+	}, nil
+}
+
+// ConvertToDBPetMemory 将宠物记忆转换为数据库模型
+func ConvertToDBPetMemory(memory *models.Memory, forgotten bool) *DBPetMemory {
+	return &DBPetMemory{
+		ID:          memory.ID,
+		PetID:       memory.PetID,
+		Kind:        string(memory.Kind),
+		Summary:     memory.Summary,
+		Location:    memory.Location,
+		PartnerID:   memory.PartnerID,
+		PartnerName: memory.PartnerName,
+		Valence:     memory.Valence,
+		Importance:  memory.Importance,
+		Forgotten:   forgotten,
+		CreatedAt:   memory.CreatedAt,
+	}
+}
+
+// ConvertFromDBPetMemory 将数据库模型转换为宠物记忆
+func ConvertFromDBPetMemory(dbMemory *DBPetMemory) models.Memory {
+	return models.Memory{
+		ID:          dbMemory.ID,
+		PetID:       dbMemory.PetID,
+		Kind:        models.MemoryKind(dbMemory.Kind),
+		Summary:     dbMemory.Summary,
+		Location:    dbMemory.Location,
+		PartnerID:   dbMemory.PartnerID,
+		PartnerName: dbMemory.PartnerName,
+		Valence:     dbMemory.Valence,
+		Importance:  dbMemory.Importance,
+		CreatedAt:   dbMemory.CreatedAt,
+	}
+}
@@ -2,9 +2,11 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/glebarez/sqlite"
@@ -16,13 +18,18 @@ var DB *gorm.DB
 
 // Initialize 初始化数据库连接
 func Initialize() error {
+	return InitializeAt("data")
+}
+
+// InitializeAt 在指定目录中初始化数据库连接，测试用它把数据库放到临时目录
+func InitializeAt(dataDir string) error {
 	// 确保data目录存在
-	if err := os.MkdirAll("data", 0755); err != nil {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return fmt.Errorf("failed to create data directory: %w", err)
 	}
 
 	// 数据库文件路径
-	dbPath := "data/petminer.db"
+	dbPath := filepath.Join(dataDir, "petminer.db")
 	
 	// 配置GORM logger
 	newLogger := logger.New(
@@ -78,10 +85,15 @@ func Migrate() error {
 	log.Println("Running database migrations...")
 
 	// 自动迁移数据库表
-	if err := DB.AutoMigrate(&DBPet{}, &DBEvent{}); err != nil {
+	if err := DB.AutoMigrate(&DBPet{}, &DBEvent{}, &DBLedgerEntry{}, &DBEconomyBucket{}, &DBWallet{}, &DBTransfer{}, &DBBankAccount{}, &DBLoan{}, &DBAuction{}, &DBAuctionBid{}, &DBLocationResource{}, &DBWorldBoss{}, &DBBossContribution{}, &DBWorldEvent{}, &DBPetAction{}, &DBPetMemory{}); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	// 旧版文字记忆要在宠物加载前转成结构化记忆
+	if err := MigrateLegacyMemories(); err != nil {
+		return fmt.Errorf("failed to migrate legacy memories: %w", err)
+	}
+
 	log.Println("Database migrations completed successfully")
 	
 	// 初始化批量写入管理器
@@ -183,21 +195,50 @@ type BatchWrite interface {
 	Execute(tx *gorm.DB) error
 }
 
+// CommitHook 需要在批次成功提交后执行后续操作的写入，批次回滚或被丢弃时不会调用
+type CommitHook interface {
+	Committed()
+}
+
+// FailHook 需要在批次回滚或被丢弃后补救的写入，例如把未落库的变化放回待写队列
+type FailHook interface {
+	Failed(err error)
+}
+
+// errQueueFull 写入队列已满，写入被丢弃
+var errQueueFull = errors.New("write queue is full")
+
+// writesFailed 通知批次中的写入没有落库
+func writesFailed(writes []BatchWrite, err error) {
+	for _, write := range writes {
+		if hook, ok := write.(FailHook); ok {
+			hook.Failed(err)
+		}
+	}
+}
+
 // BatchWriteManager 批量写入管理器
 type BatchWriteManager struct {
+	db         *gorm.DB
 	writeQueue chan BatchWrite
 	batchSize  int
 	flushTime  time.Duration
 	quit       chan bool
+	flushReq   chan chan struct{}
+	// done 在写入协程退出后关闭
+	done chan struct{}
 }
 
-// NewBatchWriteManager 创建批量写入管理器
-func NewBatchWriteManager(batchSize int, flushTime time.Duration) *BatchWriteManager {
+// NewBatchWriteManager 创建写入 db 的批量写入管理器
+func NewBatchWriteManager(db *gorm.DB, batchSize int, flushTime time.Duration) *BatchWriteManager {
 	manager := &BatchWriteManager{
+		db:         db,
 		writeQueue: make(chan BatchWrite, batchSize*2),
 		batchSize:  batchSize,
 		flushTime:  flushTime,
 		quit:       make(chan bool),
+		flushReq:   make(chan chan struct{}),
+		done:       make(chan struct{}),
 	}
 	
 	go manager.processBatchWrites()
@@ -210,16 +251,29 @@ func (bm *BatchWriteManager) AddWrite(write BatchWrite) {
 	case bm.writeQueue <- write:
 	default:
 		log.Println("Warning: write queue is full, dropping write operation")
+		writesFailed([]BatchWrite{write}, errQueueFull)
 	}
 }
 
-// Stop 停止批量写入管理器
+// Flush 立即写入队列中已有的操作，并等待写入完成
+func (bm *BatchWriteManager) Flush() {
+	done := make(chan struct{})
+	select {
+	case bm.flushReq <- done:
+		<-done
+	case <-bm.quit:
+	}
+}
+
+// Stop 停止批量写入管理器，等待队列中已有的写入落库后返回
 func (bm *BatchWriteManager) Stop() {
 	close(bm.quit)
+	<-bm.done
 }
 
 // processBatchWrites 处理批量写入
 func (bm *BatchWriteManager) processBatchWrites() {
+	defer close(bm.done)
 	ticker := time.NewTicker(bm.flushTime)
 	defer ticker.Stop()
 
@@ -230,7 +284,7 @@ func (bm *BatchWriteManager) processBatchWrites() {
 			return
 		}
 
-		if err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := bm.db.Transaction(func(tx *gorm.DB) error {
 			for _, write := range batch {
 				if err := write.Execute(tx); err != nil {
 					return err
@@ -239,11 +293,29 @@ func (bm *BatchWriteManager) processBatchWrites() {
 			return nil
 		}); err != nil {
 			log.Printf("Error executing batch write: %v", err)
+			writesFailed(batch, err)
+		} else {
+			for _, write := range batch {
+				if hook, ok := write.(CommitHook); ok {
+					hook.Committed()
+				}
+			}
 		}
 
 		batch = batch[:0] // 清空批次但保留容量
 	}
 
+	drain := func() {
+		for {
+			select {
+			case write := <-bm.writeQueue:
+				batch = append(batch, write)
+			default:
+				return
+			}
+		}
+	}
+
 	for {
 		select {
 		case write := <-bm.writeQueue:
@@ -255,7 +327,14 @@ func (bm *BatchWriteManager) processBatchWrites() {
 		case <-ticker.C:
 			flush()
 
+		case done := <-bm.flushReq:
+			// 先取出已入队的写入，保证调用 Flush 之前提交的操作都已落库
+			drain()
+			flush()
+			close(done)
+
 		case <-bm.quit:
+			drain()
 			flush() // 最后一次刷新
 			return
 		}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EconomyRepository 经济遥测数据访问层
+type EconomyRepository struct {
+	db *gorm.DB
+}
+
+// NewEconomyRepository 创建经济遥测仓库
+func NewEconomyRepository() *EconomyRepository {
+	return &EconomyRepository{db: DB}
+}
+
+// AddToBuckets 将增量累加到对应时间桶（不存在则创建）
+func (r *EconomyRepository) AddToBuckets(deltas []*DBEconomyBucket) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, delta := range deltas {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "bucket_start"}, {Name: "flow"}, {Name: "source"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"amount": gorm.Expr("amount + ?", delta.Amount),
+					"count":  gorm.Expr("count + ?", delta.Count),
+				}),
+			}).Create(delta).Error
+			if err != nil {
+				return fmt.Errorf("failed to upsert economy bucket: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetBuckets 获取时间范围 [from, to) 内的所有时间桶
+func (r *EconomyRepository) GetBuckets(from, to time.Time) ([]DBEconomyBucket, error) {
+	var buckets []DBEconomyBucket
+	if err := r.db.Where("bucket_start >= ? AND bucket_start < ?", from.UTC(), to.UTC()).
+		Order("bucket_start ASC").
+		Find(&buckets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get economy buckets: %w", err)
+	}
+	return buckets, nil
+}
+
+// GetFlowTotalsSince 获取某时间点之后各流向的总量
+func (r *EconomyRepository) GetFlowTotalsSince(since time.Time) (map[string]int64, error) {
+	var rows []struct {
+		Flow  string
+		Total int64
+	}
+	if err := r.db.Model(&DBEconomyBucket{}).
+		Select("flow, SUM(amount) AS total").
+		Where("bucket_start >= ?", since.UTC()).
+		Group("flow").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum economy flows: %w", err)
+	}
+
+	totals := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		totals[row.Flow] = row.Total
+	}
+	return totals, nil
+}
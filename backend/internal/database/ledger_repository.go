@@ -0,0 +1,259 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"miningpet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// LedgerRepository 账本数据访问层
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+// NewLedgerRepository 创建账本仓库
+func NewLedgerRepository() *LedgerRepository {
+	return &LedgerRepository{db: DB}
+}
+
+// CreateEntries 同步写入分录
+func (r *LedgerRepository) CreateEntries(entries []*models.LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	dbEntries := make([]*DBLedgerEntry, len(entries))
+	for i, entry := range entries {
+		dbEntries[i] = ConvertToDBLedgerEntry(entry)
+	}
+
+	if err := r.db.Create(dbEntries).Error; err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	return nil
+}
+
+// GetEntriesByAccount 分页获取某账户的分录（按时间倒序）
+func (r *LedgerRepository) GetEntriesByAccount(account models.LedgerAccount, limit, offset int) ([]*models.LedgerEntry, int64, error) {
+	query := r.db.Model(&DBLedgerEntry{}).
+		Where("debit_account = ? OR credit_account = ?", string(account), string(account))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count ledger entries: %w", err)
+	}
+
+	var dbEntries []DBLedgerEntry
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&dbEntries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get ledger entries: %w", err)
+	}
+
+	entries := make([]*models.LedgerEntry, len(dbEntries))
+	for i := range dbEntries {
+		entries[i] = ConvertFromDBLedgerEntry(&dbEntries[i])
+	}
+
+	return entries, total, nil
+}
+
+// accountSum 按账户汇总的金额
+type accountSum struct {
+	Account string
+	Total   int
+}
+
+// GetAccountBalances 计算指定前缀下所有账户的账本余额（仅包含有分录的账户）
+func (r *LedgerRepository) GetAccountBalances(prefix string) (map[models.LedgerAccount]int, error) {
+	var debits, credits []accountSum
+
+	if err := r.db.Model(&DBLedgerEntry{}).
+		Select("debit_account AS account, SUM(amount) AS total").
+		Where("debit_account LIKE ?", prefix+"%").
+		Group("debit_account").
+		Scan(&debits).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum ledger debits: %w", err)
+	}
+
+	if err := r.db.Model(&DBLedgerEntry{}).
+		Select("credit_account AS account, SUM(amount) AS total").
+		Where("credit_account LIKE ?", prefix+"%").
+		Group("credit_account").
+		Scan(&credits).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum ledger credits: %w", err)
+	}
+
+	balances := make(map[models.LedgerAccount]int)
+	for _, sum := range debits {
+		balances[models.LedgerAccount(sum.Account)] += sum.Total
+	}
+	for _, sum := range credits {
+		balances[models.LedgerAccount(sum.Account)] -= sum.Total
+	}
+
+	return balances, nil
+}
+
+// GetPetBalances 计算所有宠物账户的账本余额（仅包含有分录的账户）
+func (r *LedgerRepository) GetPetBalances() (map[string]int, error) {
+	accounts, err := r.GetAccountBalances(string(models.PetAccount("")))
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]int, len(accounts))
+	for account, balance := range accounts {
+		if petID, ok := account.PetID(); ok {
+			balances[petID] = balance
+		}
+	}
+	return balances, nil
+}
+
+// GetPersistedPetCoins 获取数据库中每只宠物的金币数
+func (r *LedgerRepository) GetPersistedPetCoins() (map[string]int, error) {
+	var rows []struct {
+		ID    string
+		Coins int
+	}
+	if err := r.db.Model(&DBPet{}).Select("id, coins").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pet coins: %w", err)
+	}
+
+	coins := make(map[string]int, len(rows))
+	for _, row := range rows {
+		coins[row.ID] = row.Coins
+	}
+	return coins, nil
+}
+
+// LedgerChanges 需要与宠物快照一起落库的账本变化
+type LedgerChanges struct {
+	Entries      []*models.LedgerEntry
+	WalletDeltas []WalletDelta
+	// Records 其他相关记录的快照（按主键覆盖写入）
+	Records []interface{}
+}
+
+// LedgerBatchWrite 宠物快照、钱包增量、相关记录与其分录在同一事务中写入
+type LedgerBatchWrite struct {
+	Pet          *DBPet
+	Entries      []*DBLedgerEntry
+	WalletDeltas []WalletDelta
+	Records      []interface{}
+	// OnCommit 写入成功提交后调用，可为空
+	OnCommit func()
+	// OnFail 写入回滚或被丢弃后调用，可为空
+	OnFail func()
+}
+
+// Committed 实现 CommitHook
+func (lbw *LedgerBatchWrite) Committed() {
+	if lbw.OnCommit != nil {
+		lbw.OnCommit()
+	}
+}
+
+// Failed 实现 FailHook
+func (lbw *LedgerBatchWrite) Failed(err error) {
+	if lbw.OnFail != nil {
+		lbw.OnFail()
+	}
+}
+
+// Execute 执行账本批量写入
+func (lbw *LedgerBatchWrite) Execute(tx *gorm.DB) error {
+	if lbw.Pet != nil {
+		// Select("*") 保证金币等字段为0时也会写入
+		if err := tx.Model(lbw.Pet).Select("*").Updates(lbw.Pet).Error; err != nil {
+			return fmt.Errorf("failed to update pet: %w", err)
+		}
+	}
+
+	for _, delta := range lbw.WalletDeltas {
+		if err := applyWalletDelta(tx, delta); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range lbw.Records {
+		if err := tx.Save(record).Error; err != nil {
+			return fmt.Errorf("failed to save record: %w", err)
+		}
+	}
+
+	if len(lbw.Entries) > 0 {
+		if err := tx.Create(lbw.Entries).Error; err != nil {
+			return fmt.Errorf("failed to create ledger entries: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newLedgerBatchWrite 在入队时对宠物做快照，避免写入时读到后续未记账的变化
+func newLedgerBatchWrite(pet *models.Pet, entries []*models.LedgerEntry) (*LedgerBatchWrite, error) {
+	dbPet, err := ConvertToDBPet(pet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pet: %w", err)
+	}
+
+	dbEntries := make([]*DBLedgerEntry, len(entries))
+	for i, entry := range entries {
+		dbEntries[i] = ConvertToDBLedgerEntry(entry)
+	}
+
+	return &LedgerBatchWrite{Pet: dbPet, Entries: dbEntries}, nil
+}
+
+// CreatePetWithLedger 在同一事务中创建宠物及其初始分录
+func (r *PetRepository) CreatePetWithLedger(pet *models.Pet, entries []*models.LedgerEntry) error {
+	write, err := newLedgerBatchWrite(pet, entries)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(write.Pet).Error; err != nil {
+			return fmt.Errorf("failed to create pet: %w", err)
+		}
+		if len(write.Entries) > 0 {
+			if err := tx.Create(write.Entries).Error; err != nil {
+				return fmt.Errorf("failed to create ledger entries: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// UpdatePetWithLedger 将宠物快照和待写的账本变化加入批量写入队列，onCommit 在成功落库后调用，
+// onFail 在没能落库时调用，调用方应把 changes 放回待写队列，随下一次保存重试
+func (r *PetRepository) UpdatePetWithLedger(pet *models.Pet, changes LedgerChanges, onCommit, onFail func()) {
+	write, err := newLedgerBatchWrite(pet, changes.Entries)
+	if err != nil {
+		log.Printf("Failed to prepare ledger write: %v", err)
+		if onFail != nil {
+			onFail()
+		}
+		return
+	}
+	write.WalletDeltas = changes.WalletDeltas
+	write.Records = changes.Records
+	write.OnCommit = onCommit
+	write.OnFail = onFail
+
+	if PetBatchManager != nil {
+		PetBatchManager.AddWrite(write)
+		return
+	}
+
+	// 降级到同步写入
+	if err := r.db.Transaction(write.Execute); err != nil {
+		log.Printf("Failed to update pet with ledger: %v", err)
+		write.Failed(err)
+		return
+	}
+	write.Committed()
+}
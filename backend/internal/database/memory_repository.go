@@ -0,0 +1,124 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"miningpet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// legacyMemoryImportance 旧版文字记忆迁移后的重要程度，它们没有情绪，会随时间慢慢被忘掉
+const legacyMemoryImportance = 30
+
+// MemoryRepository 宠物记忆数据访问层
+type MemoryRepository struct {
+	db *gorm.DB
+}
+
+// NewMemoryRepository 创建记忆仓库
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{db: DB}
+}
+
+// MemoryQuery 记忆查询条件，空字段表示不限制
+type MemoryQuery struct {
+	Kind      string
+	Location  string
+	PartnerID string
+	// Forgotten 只查询已被遗忘的记忆
+	Forgotten bool
+	Limit     int
+}
+
+// GetRetainedMemories 获取所有宠物仍记得的记忆，按宠物分组，每组按时间先后排序
+func (r *MemoryRepository) GetRetainedMemories() (map[string][]models.Memory, error) {
+	var rows []DBPetMemory
+	if err := r.db.Where("forgotten = ?", false).Order("created_at ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get memories: %w", err)
+	}
+
+	memories := make(map[string][]models.Memory)
+	for i := range rows {
+		memories[rows[i].PetID] = append(memories[rows[i].PetID], ConvertFromDBPetMemory(&rows[i]))
+	}
+	return memories, nil
+}
+
+// QueryMemories 按条件查询宠物的记忆，最近的在前
+func (r *MemoryRepository) QueryMemories(petID string, query MemoryQuery) ([]models.Memory, error) {
+	db := r.db.Where("pet_id = ? AND forgotten = ?", petID, query.Forgotten)
+	if query.Kind != "" {
+		db = db.Where("kind = ?", query.Kind)
+	}
+	if query.Location != "" {
+		db = db.Where("location = ?", query.Location)
+	}
+	if query.PartnerID != "" {
+		db = db.Where("partner_id = ?", query.PartnerID)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var rows []DBPetMemory
+	if err := db.Order("created_at DESC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to query memories: %w", err)
+	}
+
+	memories := make([]models.Memory, 0, len(rows))
+	for i := range rows {
+		memories = append(memories, ConvertFromDBPetMemory(&rows[i]))
+	}
+	return memories, nil
+}
+
+// MigrateLegacyMemories 把宠物表旧 memory 列中的文字记忆转成 legacy 记忆并清空该列，可以重复执行。
+// 旧记忆没有记录时间，按原来的先后顺序排在宠物最后活动之前
+func MigrateLegacyMemories() error {
+	var pets []DBPet
+	if err := DB.Select("id", "memory", "last_activity").Where("memory <> '' AND memory <> '[]'").Find(&pets).Error; err != nil {
+		return fmt.Errorf("failed to get legacy memories: %w", err)
+	}
+
+	migrated := 0
+	for i := range pets {
+		pet := &pets[i]
+		memory, err := pet.GetMemory()
+		if err != nil {
+			log.Printf("Warning: skipping unreadable legacy memory of pet %s: %v", pet.ID, err)
+			continue
+		}
+
+		rows := make([]DBPetMemory, 0, len(memory))
+		for j, summary := range memory {
+			rows = append(rows, DBPetMemory{
+				ID:         uuid.New().String(),
+				PetID:      pet.ID,
+				Kind:       string(models.MemoryLegacy),
+				Summary:    summary,
+				Importance: legacyMemoryImportance,
+				CreatedAt:  pet.LastActivity.Add(-time.Duration(len(memory)-j) * time.Second),
+			})
+		}
+		err = DB.Transaction(func(tx *gorm.DB) error {
+			if len(rows) > 0 {
+				if err := tx.Create(&rows).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Model(&DBPet{}).Where("id = ?", pet.ID).Update("memory", "").Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate legacy memories of pet %s: %w", pet.ID, err)
+		}
+		migrated += len(rows)
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d legacy memories of %d pets", migrated, len(pets))
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package database
 import (
 	"time"
 	"encoding/json"
+
+	"miningpet/internal/models"
 )
 
 // DBPet 数据库宠物模型
@@ -11,6 +13,9 @@ type DBPet struct {
 	Name         string    `gorm:"size:50;not null" json:"name"`
 	Owner        string    `gorm:"size:50;not null;index" json:"owner"`
 	Personality  string    `gorm:"size:20;not null" json:"personality"`
+	Traits       string    `gorm:"type:text" json:"traits"`       // JSON存储
+	Strategy     string    `gorm:"size:30" json:"strategy"`
+	Policy       string    `gorm:"type:text" json:"policy"`       // JSON存储
 	Level        int       `gorm:"default:1" json:"level"`
 	Experience   int       `gorm:"default:0" json:"experience"`
 	Health       int       `gorm:"default:100" json:"health"`
@@ -20,14 +25,21 @@ type DBPet struct {
 	Hunger       int       `gorm:"default:80" json:"hunger"`
 	Social       int       `gorm:"default:50" json:"social"`
 	Mood         string    `gorm:"size:20;default:'普通'" json:"mood"`
+	Emotions     string    `gorm:"type:text" json:"emotions"`     // JSON存储
 	Attack       int       `gorm:"default:10" json:"attack"`
 	Defense      int       `gorm:"default:5" json:"defense"`
 	Coins        int       `gorm:"default:0" json:"coins"`
 	Location     string    `gorm:"size:100;default:'起始村庄'" json:"location"`
 	Status       string    `gorm:"size:20;default:'等待中'" json:"status"`
-	Memory       string    `gorm:"type:text" json:"memory"`       // JSON存储
+	Memory       string    `gorm:"type:text" json:"-"`            // 旧版文字记忆，启动时迁移到 pet_memories 后清空
 	Friends      string    `gorm:"type:text" json:"friends"`      // JSON存储
+	Inventory    string    `gorm:"type:text" json:"inventory"`    // JSON存储
+	ActionQueue  string    `gorm:"type:text" json:"action_queue"` // JSON存储
+	Rules        string    `gorm:"type:text" json:"rules"`        // JSON存储
+	Learned      string    `gorm:"type:text" json:"learned"`      // JSON存储
+	Goal         string    `gorm:"type:text" json:"goal"`         // JSON存储
 	LastActivity time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"last_activity"`
+	SimulatedAt  time.Time `json:"simulated_at"` // 旧数据为零值，离线结算时退回 LastActivity
 	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
@@ -44,6 +56,183 @@ type DBEvent struct {
 	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 }
 
+// DBLedgerEntry 数据库账本分录模型（只追加，不修改）
+type DBLedgerEntry struct {
+	ID            string    `gorm:"primaryKey;size:36" json:"id"`
+	PetID         string    `gorm:"size:36;index" json:"pet_id"`
+	DebitAccount  string    `gorm:"size:80;not null;index" json:"debit_account"`
+	CreditAccount string    `gorm:"size:80;not null;index" json:"credit_account"`
+	Amount        int       `gorm:"not null" json:"amount"`
+	Reason        string    `gorm:"size:30;not null;index" json:"reason"`
+	Memo          string    `gorm:"type:text" json:"memo"`
+	CreatedAt     time.Time `gorm:"not null;index" json:"created_at"`
+}
+
+// DBEconomyBucket 经济遥测时间桶（按时间、流向、来源聚合）
+type DBEconomyBucket struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	BucketStart time.Time `gorm:"not null;uniqueIndex:idx_economy_bucket" json:"bucket_start"`
+	Flow        string    `gorm:"size:10;not null;uniqueIndex:idx_economy_bucket" json:"flow"`
+	Source      string    `gorm:"size:30;not null;uniqueIndex:idx_economy_bucket" json:"source"`
+	Amount      int64     `gorm:"not null;default:0" json:"amount"`
+	Count       int64     `gorm:"not null;default:0" json:"count"`
+}
+
+// DBWallet 数据库主人钱包模型
+type DBWallet struct {
+	Owner     string    `gorm:"primaryKey;size:50" json:"owner"`
+	Balance   int       `gorm:"not null;default:0" json:"balance"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// DBTransfer 数据库转账记录模型
+type DBTransfer struct {
+	ID        string    `gorm:"primaryKey;size:64" json:"id"`
+	FromOwner string    `gorm:"size:50;not null;index" json:"from_owner"`
+	ToOwner   string    `gorm:"size:50;not null;index" json:"to_owner"`
+	Amount    int       `gorm:"not null" json:"amount"`
+	Fee       int       `gorm:"not null;default:0" json:"fee"`
+	Memo      string    `gorm:"size:200" json:"memo"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+}
+
+// DBBankAccount 数据库银行存款账户模型
+type DBBankAccount struct {
+	PetID          string    `gorm:"primaryKey;size:36" json:"pet_id"`
+	Balance        int       `gorm:"not null;default:0" json:"balance"`
+	InterestEarned int       `gorm:"not null;default:0" json:"interest_earned"`
+	LastInterestAt time.Time `gorm:"not null" json:"last_interest_at"`
+	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// DBLoan 数据库贷款模型
+type DBLoan struct {
+	ID            string     `gorm:"primaryKey;size:36" json:"id"`
+	PetID         string     `gorm:"size:36;not null;index" json:"pet_id"`
+	Principal     int        `gorm:"not null" json:"principal"`
+	Outstanding   int        `gorm:"not null" json:"outstanding"`
+	DailyRate     int        `gorm:"not null" json:"daily_rate"`
+	Status        string     `gorm:"size:20;not null;index" json:"status"`
+	IssuedAt      time.Time  `gorm:"not null" json:"issued_at"`
+	DueAt         time.Time  `gorm:"not null" json:"due_at"`
+	LastAccruedAt time.Time  `gorm:"not null" json:"last_accrued_at"`
+	RepaidAt      *time.Time `json:"repaid_at"`
+}
+
+// DBAuction 数据库拍卖模型
+type DBAuction struct {
+	ID                string     `gorm:"primaryKey;size:36" json:"id"`
+	SellerID          string     `gorm:"size:36;not null;index" json:"seller_id"`
+	SellerName        string     `gorm:"size:50;not null" json:"seller_name"`
+	Item              string     `gorm:"type:text;not null" json:"item"` // JSON存储
+	StartingBid       int        `gorm:"not null" json:"starting_bid"`
+	BuyoutPrice       int        `gorm:"not null;default:0" json:"buyout_price"`
+	CurrentBid        int        `gorm:"not null;default:0" json:"current_bid"`
+	HighestBidderID   string     `gorm:"size:36;index" json:"highest_bidder_id"`
+	HighestBidderName string     `gorm:"size:50" json:"highest_bidder_name"`
+	BidCount          int        `gorm:"not null;default:0" json:"bid_count"`
+	Commission        int        `gorm:"not null;default:0" json:"commission"`
+	Status            string     `gorm:"size:20;not null;index" json:"status"`
+	CreatedAt         time.Time  `gorm:"not null" json:"created_at"`
+	EndsAt            time.Time  `gorm:"not null;index" json:"ends_at"`
+	SettledAt         *time.Time `json:"settled_at"`
+}
+
+// DBAuctionBid 数据库出价记录模型（只追加，不修改）
+type DBAuctionBid struct {
+	ID        string    `gorm:"primaryKey;size:36" json:"id"`
+	AuctionID string    `gorm:"size:36;not null;index" json:"auction_id"`
+	PetID     string    `gorm:"size:36;not null;index" json:"pet_id"`
+	PetName   string    `gorm:"size:50;not null" json:"pet_name"`
+	Amount    int       `gorm:"not null" json:"amount"`
+	Buyout    bool      `gorm:"not null;default:false" json:"buyout"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
+
+// DBLocationResource 数据库地点资源模型
+type DBLocationResource struct {
+	Location        string    `gorm:"primaryKey;size:100" json:"location"`
+	Ore             int       `gorm:"not null;default:0" json:"ore"`
+	Treasure        int       `gorm:"not null;default:0" json:"treasure"`
+	OreRegenAt      time.Time `gorm:"not null" json:"ore_regen_at"`
+	TreasureRegenAt time.Time `gorm:"not null" json:"treasure_regen_at"`
+	UpdatedAt       time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// DBWorldBoss 数据库世界首领模型
+type DBWorldBoss struct {
+	ID         string     `gorm:"primaryKey;size:36" json:"id"`
+	TemplateID string     `gorm:"size:50;not null" json:"template_id"`
+	Name       string     `gorm:"size:50;not null" json:"name"`
+	Location   string     `gorm:"size:100;not null" json:"location"`
+	MaxHP      int        `gorm:"not null" json:"max_hp"`
+	HP         int        `gorm:"not null" json:"hp"`
+	Attack     int        `gorm:"not null" json:"attack"`
+	Defense    int        `gorm:"not null" json:"defense"`
+	RewardPool int        `gorm:"not null" json:"reward_pool"`
+	ExpPool    int        `gorm:"not null" json:"exp_pool"`
+	Status     string     `gorm:"size:20;not null;index" json:"status"`
+	SpawnedAt  time.Time  `gorm:"not null;index" json:"spawned_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+}
+
+// DBBossContribution 数据库首领伤害贡献模型
+type DBBossContribution struct {
+	BossID  string `gorm:"primaryKey;size:36" json:"boss_id"`
+	PetID   string `gorm:"primaryKey;size:36" json:"pet_id"`
+	PetName string `gorm:"size:50;not null" json:"pet_name"`
+	Owner   string `gorm:"size:50;not null" json:"owner"`
+	Damage  int    `gorm:"not null;default:0" json:"damage"`
+	Hits    int    `gorm:"not null;default:0" json:"hits"`
+	Reward  int    `gorm:"not null;default:0" json:"reward"`
+}
+
+// DBWorldEvent 数据库世界事件模型
+type DBWorldEvent struct {
+	ID          string    `gorm:"primaryKey;size:64" json:"id"`
+	CalendarID  string    `gorm:"size:50;index" json:"calendar_id"`
+	Name        string    `gorm:"size:50;not null" json:"name"`
+	Description string    `gorm:"size:255" json:"description"`
+	Location    string    `gorm:"size:100" json:"location"`
+	Modifiers   string    `gorm:"type:text" json:"modifiers"` // JSON存储
+	Status      string    `gorm:"size:20;not null;index" json:"status"`
+	StartAt     time.Time `gorm:"not null;index" json:"start_at"`
+	EndAt       time.Time `gorm:"not null;index" json:"end_at"`
+	CreatedBy   string    `gorm:"size:50" json:"created_by"`
+	CreatedAt   time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"not null" json:"updated_at"`
+}
+
+// DBPetAction 数据库进行中行动模型，每只宠物一行
+type DBPetAction struct {
+	PetID      string     `gorm:"primaryKey;size:36" json:"pet_id"`
+	ID         string     `gorm:"size:36;not null" json:"id"`
+	Type       string     `gorm:"size:20;not null" json:"type"`
+	Status     string     `gorm:"size:20;not null" json:"status"`
+	Reason     string     `gorm:"size:255" json:"reason"`
+	Params     string     `gorm:"type:text" json:"params"` // JSON存储
+	StartedAt  time.Time  `gorm:"not null" json:"started_at"`
+	Duration   int        `gorm:"not null" json:"duration"`
+	Active     bool       `gorm:"not null;index" json:"active"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// DBPetMemory 数据库宠物记忆模型，被遗忘的记忆保留下来作为历史
+type DBPetMemory struct {
+	ID          string    `gorm:"primaryKey;size:36" json:"id"`
+	PetID       string    `gorm:"size:36;not null;index" json:"pet_id"`
+	Kind        string    `gorm:"size:20;not null;index" json:"kind"`
+	Summary     string    `gorm:"type:text" json:"summary"`
+	Location    string    `gorm:"size:100;index" json:"location"`
+	PartnerID   string    `gorm:"size:36" json:"partner_id"`
+	PartnerName string    `gorm:"size:50" json:"partner_name"`
+	Valence     int       `gorm:"not null" json:"valence"`
+	Importance  int       `gorm:"not null" json:"importance"`
+	Forgotten   bool      `gorm:"not null;index" json:"forgotten"`
+	CreatedAt   time.Time `gorm:"not null;index" json:"created_at"`
+}
+
 // TableName 指定表名
 func (DBPet) TableName() string {
 	return "pets"
@@ -53,20 +242,64 @@ func (DBEvent) TableName() string {
 	return "events"
 }
 
-// 辅助方法：JSON序列化/反序列化
-func (p *DBPet) SetMemory(memory []string) error {
-	if memory == nil {
-		p.Memory = "[]"
-		return nil
-	}
-	data, err := json.Marshal(memory)
-	if err != nil {
-		return err
-	}
-	p.Memory = string(data)
-	return nil
+func (DBLedgerEntry) TableName() string {
+	return "ledger_entries"
+}
+
+func (DBEconomyBucket) TableName() string {
+	return "economy_buckets"
+}
+
+func (DBWallet) TableName() string {
+	return "wallets"
+}
+
+func (DBTransfer) TableName() string {
+	return "transfers"
+}
+
+func (DBBankAccount) TableName() string {
+	return "bank_accounts"
+}
+
+func (DBLoan) TableName() string {
+	return "loans"
+}
+
+func (DBAuction) TableName() string {
+	return "auctions"
+}
+
+func (DBAuctionBid) TableName() string {
+	return "auction_bids"
+}
+
+func (DBLocationResource) TableName() string {
+	return "location_resources"
+}
+
+func (DBWorldBoss) TableName() string {
+	return "world_bosses"
 }
 
+func (DBBossContribution) TableName() string {
+	return "boss_contributions"
+}
+
+func (DBWorldEvent) TableName() string {
+	return "world_events"
+}
+
+func (DBPetAction) TableName() string {
+	return "pet_actions"
+}
+
+func (DBPetMemory) TableName() string {
+	return "pet_memories"
+}
+
+// 辅助方法：JSON序列化/反序列化
+// GetMemory 读取旧版文字记忆，只在迁移时使用
 func (p *DBPet) GetMemory() ([]string, error) {
 	if p.Memory == "" {
 		return []string{}, nil
@@ -98,6 +331,179 @@ func (p *DBPet) GetFriends() ([]string, error) {
 	return friends, err
 }
 
+func (p *DBPet) SetInventory(items []models.Item) error {
+	if items == nil {
+		p.Inventory = "[]"
+		return nil
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	p.Inventory = string(data)
+	return nil
+}
+
+func (p *DBPet) GetInventory() ([]models.Item, error) {
+	if p.Inventory == "" {
+		return []models.Item{}, nil
+	}
+	var items []models.Item
+	err := json.Unmarshal([]byte(p.Inventory), &items)
+	return items, err
+}
+
+func (p *DBPet) SetActionQueue(queue []models.QueuedAction) error {
+	if queue == nil {
+		p.ActionQueue = "[]"
+		return nil
+	}
+	data, err := json.Marshal(queue)
+	if err != nil {
+		return err
+	}
+	p.ActionQueue = string(data)
+	return nil
+}
+
+func (p *DBPet) GetActionQueue() ([]models.QueuedAction, error) {
+	if p.ActionQueue == "" {
+		return []models.QueuedAction{}, nil
+	}
+	var queue []models.QueuedAction
+	err := json.Unmarshal([]byte(p.ActionQueue), &queue)
+	return queue, err
+}
+
+func (p *DBPet) SetRules(rules []models.AutomationRule) error {
+	if rules == nil {
+		p.Rules = "[]"
+		return nil
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	p.Rules = string(data)
+	return nil
+}
+
+func (p *DBPet) GetRules() ([]models.AutomationRule, error) {
+	if p.Rules == "" {
+		return []models.AutomationRule{}, nil
+	}
+	var rules []models.AutomationRule
+	err := json.Unmarshal([]byte(p.Rules), &rules)
+	return rules, err
+}
+
+func (p *DBPet) SetEmotions(emotions models.Emotions) error {
+	if emotions == nil {
+		p.Emotions = "[]"
+		return nil
+	}
+	data, err := json.Marshal(emotions)
+	if err != nil {
+		return err
+	}
+	p.Emotions = string(data)
+	return nil
+}
+
+func (p *DBPet) GetEmotions() (models.Emotions, error) {
+	if p.Emotions == "" {
+		return models.Emotions{}, nil
+	}
+	var emotions models.Emotions
+	err := json.Unmarshal([]byte(p.Emotions), &emotions)
+	return emotions, err
+}
+
+func (p *DBPet) SetLearned(learned models.LearnedWeights) error {
+	if learned == nil {
+		p.Learned = "{}"
+		return nil
+	}
+	data, err := json.Marshal(learned)
+	if err != nil {
+		return err
+	}
+	p.Learned = string(data)
+	return nil
+}
+
+func (p *DBPet) GetLearned() (models.LearnedWeights, error) {
+	learned := make(models.LearnedWeights)
+	if p.Learned == "" {
+		return learned, nil
+	}
+	err := json.Unmarshal([]byte(p.Learned), &learned)
+	return learned, err
+}
+
+func (p *DBPet) SetGoal(goal *models.PetGoal) error {
+	if goal == nil {
+		p.Goal = ""
+		return nil
+	}
+	data, err := json.Marshal(goal)
+	if err != nil {
+		return err
+	}
+	p.Goal = string(data)
+	return nil
+}
+
+// GetGoal 没有目标时返回 nil
+func (p *DBPet) GetGoal() (*models.PetGoal, error) {
+	if p.Goal == "" {
+		return nil, nil
+	}
+	var goal models.PetGoal
+	if err := json.Unmarshal([]byte(p.Goal), &goal); err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (p *DBPet) SetPolicy(policy models.PetPolicy) error {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	p.Policy = string(data)
+	return nil
+}
+
+func (p *DBPet) SetTraits(traits models.PersonalityTraits) error {
+	data, err := json.Marshal(traits)
+	if err != nil {
+		return err
+	}
+	p.Traits = string(data)
+	return nil
+}
+
+// GetTraits 旧数据没有性格特质时按性格标签生成
+func (p *DBPet) GetTraits() (models.PersonalityTraits, error) {
+	if p.Traits == "" {
+		return models.TraitsFor(models.PetPersonality(p.Personality)), nil
+	}
+	var traits models.PersonalityTraits
+	err := json.Unmarshal([]byte(p.Traits), &traits)
+	return traits, err
+}
+
+// GetPolicy 旧数据没有策略时返回默认策略
+func (p *DBPet) GetPolicy() (models.PetPolicy, error) {
+	policy := models.DefaultPetPolicy()
+	if p.Policy == "" {
+		return policy, nil
+	}
+	err := json.Unmarshal([]byte(p.Policy), &policy)
+	return policy, err
+}
+
 func (e *DBEvent) SetEventData(data interface{}) error {
 	if data == nil {
 		e.Data = "{}"
@@ -159,6 +159,18 @@ func (r *EventRepository) CreateEventBatch(event *models.Event) {
 	}
 }
 
+// UpdateEventMessageBatch 改写事件消息，与事件创建走同一个批量队列，保证排在创建之后
+func (r *EventRepository) UpdateEventMessageBatch(eventID, message string) {
+	if EventBatchManager != nil {
+		EventBatchManager.AddWrite(&EventMessageBatchWrite{EventID: eventID, Message: message})
+	} else {
+		// 降级到同步写入
+		if err := r.db.Model(&DBEvent{}).Where("id = ?", eventID).Update("message", message).Error; err != nil {
+			log.Printf("Failed to update event message: %v", err)
+		}
+	}
+}
+
 // GetEventsByPetID 根据宠物ID获取事件
 func (r *EventRepository) GetEventsByPetID(petID string, limit int) ([]*models.Event, error) {
 	var dbEvents []DBEvent
@@ -247,6 +259,20 @@ func (ebw *EventBatchWrite) Execute(tx *gorm.DB) error {
 	return nil
 }
 
+// EventMessageBatchWrite 事件消息改写操作，事件没有落库时不影响任何记录
+type EventMessageBatchWrite struct {
+	EventID string
+	Message string
+}
+
+// Execute 执行事件消息改写
+func (emw *EventMessageBatchWrite) Execute(tx *gorm.DB) error {
+	if err := tx.Model(&DBEvent{}).Where("id = ?", emw.EventID).Update("message", emw.Message).Error; err != nil {
+		return fmt.Errorf("failed to update event message: %w", err)
+	}
+	return nil
+}
+
 // PetBatchWrite 宠物批量写入操作
 type PetBatchWrite struct {
 	Pet *models.Pet
@@ -275,10 +301,20 @@ var (
 // InitializeBatchManagers 初始化批量写入管理器
 func InitializeBatchManagers() {
 	// 事件批量写入：每50条或每2秒刷新一次
-	EventBatchManager = NewBatchWriteManager(50, 2*time.Second)
+	EventBatchManager = NewBatchWriteManager(DB, 50, 2*time.Second)
 	
 	// 宠物批量写入：每20条或每5秒刷新一次（宠物更新频率较低）
-	PetBatchManager = NewBatchWriteManager(20, 5*time.Second)
+	PetBatchManager = NewBatchWriteManager(DB, 20, 5*time.Second)
+}
+
+// FlushBatchManagers 立即写入所有批量写入管理器中排队的操作
+func FlushBatchManagers() {
+	if PetBatchManager != nil {
+		PetBatchManager.Flush()
+	}
+	if EventBatchManager != nil {
+		EventBatchManager.Flush()
+	}
 }
 
 // CloseBatchManagers 关闭批量写入管理器
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"fmt"
+	"miningpet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WalletDelta 钱包余额增量
+// 钱包同时被批量写入和同步转账修改，使用增量而非快照保证写入顺序无关
+type WalletDelta struct {
+	Owner string
+	Delta int
+}
+
+// applyWalletDelta 累加钱包余额（钱包不存在则创建）
+func applyWalletDelta(tx *gorm.DB, delta WalletDelta) error {
+	wallet := &DBWallet{Owner: delta.Owner, Balance: delta.Delta, UpdatedAt: time.Now()}
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "owner"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"balance":    gorm.Expr("balance + ?", delta.Delta),
+			"updated_at": wallet.UpdatedAt,
+		}),
+	}).Create(wallet).Error
+	if err != nil {
+		return fmt.Errorf("failed to update wallet: %w", err)
+	}
+	return nil
+}
+
+// WalletRepository 钱包数据访问层
+type WalletRepository struct {
+	db *gorm.DB
+}
+
+// NewWalletRepository 创建钱包仓库
+func NewWalletRepository() *WalletRepository {
+	return &WalletRepository{db: DB}
+}
+
+// GetAllWallets 获取所有钱包
+func (r *WalletRepository) GetAllWallets() ([]*models.Wallet, error) {
+	var dbWallets []DBWallet
+	if err := r.db.Find(&dbWallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallets: %w", err)
+	}
+
+	wallets := make([]*models.Wallet, len(dbWallets))
+	for i := range dbWallets {
+		wallets[i] = ConvertFromDBWallet(&dbWallets[i])
+	}
+	return wallets, nil
+}
+
+// GetTransfer 根据ID获取转账记录
+func (r *WalletRepository) GetTransfer(id string) (*models.Transfer, error) {
+	var dbTransfer DBTransfer
+	if err := r.db.Where("id = ?", id).First(&dbTransfer).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get transfer: %w", err)
+	}
+	return ConvertFromDBTransfer(&dbTransfer), nil
+}
+
+// GetTransfersByOwner 分页获取主人相关的转账记录（按时间倒序）
+func (r *WalletRepository) GetTransfersByOwner(owner string, limit, offset int) ([]*models.Transfer, int64, error) {
+	query := r.db.Model(&DBTransfer{}).Where("from_owner = ? OR to_owner = ?", owner, owner)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count transfers: %w", err)
+	}
+
+	var dbTransfers []DBTransfer
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&dbTransfers).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get transfers: %w", err)
+	}
+
+	transfers := make([]*models.Transfer, len(dbTransfers))
+	for i := range dbTransfers {
+		transfers[i] = ConvertFromDBTransfer(&dbTransfers[i])
+	}
+	return transfers, total, nil
+}
+
+// CreateTransfer 在同一事务中写入转账记录、双方钱包余额变化及分录
+func (r *WalletRepository) CreateTransfer(transfer *models.Transfer, entries []*models.LedgerEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ConvertToDBTransfer(transfer)).Error; err != nil {
+			return fmt.Errorf("failed to create transfer: %w", err)
+		}
+
+		deltas := []WalletDelta{
+			{Owner: transfer.FromOwner, Delta: -(transfer.Amount + transfer.Fee)},
+			{Owner: transfer.ToOwner, Delta: transfer.Amount},
+		}
+		for _, delta := range deltas {
+			if err := applyWalletDelta(tx, delta); err != nil {
+				return err
+			}
+		}
+
+		dbEntries := make([]*DBLedgerEntry, len(entries))
+		for i, entry := range entries {
+			dbEntries[i] = ConvertToDBLedgerEntry(entry)
+		}
+		if err := tx.Create(dbEntries).Error; err != nil {
+			return fmt.Errorf("failed to create ledger entries: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetPersistedWalletBalances 获取数据库中每个钱包的余额
+func (r *WalletRepository) GetPersistedWalletBalances() (map[string]int, error) {
+	var dbWallets []DBWallet
+	if err := r.db.Select("owner, balance").Find(&dbWallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallet balances: %w", err)
+	}
+
+	balances := make(map[string]int, len(dbWallets))
+	for _, wallet := range dbWallets {
+		balances[wallet.Owner] = wallet.Balance
+	}
+	return balances, nil
+}
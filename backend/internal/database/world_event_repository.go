@@ -0,0 +1,75 @@
+package database
+
+import (
+	"fmt"
+	"miningpet/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorldEventRepository 世界事件数据访问层
+type WorldEventRepository struct {
+	db *gorm.DB
+}
+
+// NewWorldEventRepository 创建世界事件仓库
+func NewWorldEventRepository() *WorldEventRepository {
+	return &WorldEventRepository{db: DB}
+}
+
+// GetEventsEndingAfter 获取在指定时间之后才结束的事件（含已取消的，用于日历去重）
+func (r *WorldEventRepository) GetEventsEndingAfter(t time.Time) ([]*models.WorldEvent, error) {
+	var rows []DBWorldEvent
+	if err := r.db.Where("end_at > ? OR status = ?", t, string(models.WorldEventActive)).
+		Order("start_at ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get world events: %w", err)
+	}
+	return convertWorldEvents(rows)
+}
+
+// GetEvents 按开始时间倒序分页获取事件，status 为空时不过滤
+func (r *WorldEventRepository) GetEvents(status string, limit, offset int) ([]*models.WorldEvent, error) {
+	query := r.db.Order("start_at DESC").Limit(limit).Offset(offset)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var rows []DBWorldEvent
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get world events: %w", err)
+	}
+	return convertWorldEvents(rows)
+}
+
+// SaveEvents 在同一事务中保存多个事件
+func (r *WorldEventRepository) SaveEvents(events []models.WorldEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range events {
+			row, err := ConvertToDBWorldEvent(&events[i])
+			if err != nil {
+				return fmt.Errorf("failed to convert world event %s: %w", events[i].ID, err)
+			}
+			if err := tx.Save(row).Error; err != nil {
+				return fmt.Errorf("failed to save world event: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func convertWorldEvents(rows []DBWorldEvent) ([]*models.WorldEvent, error) {
+	events := make([]*models.WorldEvent, 0, len(rows))
+	for i := range rows {
+		event, err := ConvertFromDBWorldEvent(&rows[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert world event %s: %w", rows[i].ID, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
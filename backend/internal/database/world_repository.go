@@ -0,0 +1,63 @@
+package database
+
+import (
+	"fmt"
+	"miningpet/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// WorldRepository 世界资源数据访问层
+type WorldRepository struct {
+	db *gorm.DB
+}
+
+// NewWorldRepository 创建世界资源仓库
+func NewWorldRepository() *WorldRepository {
+	return &WorldRepository{db: DB}
+}
+
+// GetAllResources 获取所有地点的资源存量
+func (r *WorldRepository) GetAllResources() ([]*models.LocationResources, error) {
+	var rows []DBLocationResource
+	if err := r.db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get location resources: %w", err)
+	}
+
+	resources := make([]*models.LocationResources, len(rows))
+	for i, row := range rows {
+		resources[i] = &models.LocationResources{
+			Location:        row.Location,
+			Ore:             row.Ore,
+			Treasure:        row.Treasure,
+			OreRegenAt:      row.OreRegenAt,
+			TreasureRegenAt: row.TreasureRegenAt,
+			UpdatedAt:       row.UpdatedAt,
+		}
+	}
+	return resources, nil
+}
+
+// SaveResources 在同一事务中保存多个地点的资源存量
+func (r *WorldRepository) SaveResources(resources []models.LocationResources) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, resource := range resources {
+			row := &DBLocationResource{
+				Location:        resource.Location,
+				Ore:             resource.Ore,
+				Treasure:        resource.Treasure,
+				OreRegenAt:      resource.OreRegenAt,
+				TreasureRegenAt: resource.TreasureRegenAt,
+				UpdatedAt:       resource.UpdatedAt,
+			}
+			if err := tx.Save(row).Error; err != nil {
+				return fmt.Errorf("failed to save location resources: %w", err)
+			}
+		}
+		return nil
+	})
+}
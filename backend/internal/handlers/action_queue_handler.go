@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"miningpet/internal/models"
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type EnqueueActionsRequest struct {
+	Plan  string                `json:"plan"`
+	Steps []models.QueuedAction `json:"steps"`
+}
+
+type ReorderQueueRequest struct {
+	Order []string `json:"order" binding:"required"`
+}
+
+// queueErrorStatus 行动计划错误对应的状态码
+func queueErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrPetNotFound), errors.Is(err, services.ErrQueueStepNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrQueueFull), errors.Is(err, services.ErrQueueStepRunning):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// GetActionQueue 获取宠物的行动计划
+func (h *PetHandler) GetActionQueue(c *gin.Context) {
+	queue, err := h.petService.GetActionQueue(c.Param("id"))
+	if err != nil {
+		c.JSON(queueErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"queue": queue})
+}
+
+// EnqueueActions 向行动计划追加步骤，支持计划文本或步骤列表
+func (h *PetHandler) EnqueueActions(c *gin.Context) {
+	var req EnqueueActionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	queue, err := h.petService.EnqueueActions(c.Param("id"), req.Plan, req.Steps)
+	if err != nil {
+		c.JSON(queueErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"queue": queue})
+}
+
+// ReorderActionQueue 重排尚未开始的计划步骤
+func (h *PetHandler) ReorderActionQueue(c *gin.Context) {
+	var req ReorderQueueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	queue, err := h.petService.ReorderActionQueue(c.Param("id"), req.Order)
+	if err != nil {
+		c.JSON(queueErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"queue": queue})
+}
+
+// ClearActionQueue 清空尚未开始的计划步骤
+func (h *PetHandler) ClearActionQueue(c *gin.Context) {
+	queue, err := h.petService.ClearActionQueue(c.Param("id"))
+	if err != nil {
+		c.JSON(queueErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"queue": queue})
+}
+
+// RemoveQueuedAction 移除一步尚未开始的计划
+func (h *PetHandler) RemoveQueuedAction(c *gin.Context) {
+	queue, err := h.petService.RemoveQueuedAction(c.Param("id"), c.Param("step"))
+	if err != nil {
+		c.JSON(queueErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"queue": queue})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ExplainPetAI 解释宠物此刻的候选行为、优先级构成和选择概率，并附上最近的决策记录
+func (h *PetHandler) ExplainPetAI(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		limit = 10
+	}
+
+	explanation, err := h.petService.ExplainDecision(c.Param("id"), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrPetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAuctionRequest struct {
+	PetID           string `json:"pet_id" binding:"required"`
+	ItemID          string `json:"item_id" binding:"required"`
+	Quantity        int    `json:"quantity"`
+	StartingBid     int    `json:"starting_bid" binding:"required"`
+	BuyoutPrice     int    `json:"buyout_price"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+type PlaceBidRequest struct {
+	PetID  string `json:"pet_id" binding:"required"`
+	Amount int    `json:"amount" binding:"required"`
+}
+
+// auctionError 将拍卖行错误映射为HTTP状态码
+func auctionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPetNotFound), errors.Is(err, services.ErrAuctionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAuctionClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// GetAuctions 分页获取拍卖列表，status 默认为 open
+func (h *PetHandler) GetAuctions(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		offset = 0
+	}
+
+	status := c.DefaultQuery("status", "open")
+	if status == "all" {
+		status = ""
+	}
+
+	auctions, err := h.petService.GetAuctions(status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, auctions)
+}
+
+// GetAuction 获取拍卖详情
+func (h *PetHandler) GetAuction(c *gin.Context) {
+	auction, err := h.petService.GetAuction(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrAuctionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, auction)
+}
+
+// CreateAuction 上架拍卖
+func (h *PetHandler) CreateAuction(c *gin.Context) {
+	var req CreateAuctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	auction, err := h.petService.CreateAuction(services.CreateAuctionRequest{
+		PetID:       req.PetID,
+		ItemID:      req.ItemID,
+		Quantity:    req.Quantity,
+		StartingBid: req.StartingBid,
+		BuyoutPrice: req.BuyoutPrice,
+		Duration:    time.Duration(req.DurationMinutes) * time.Minute,
+	})
+	if err != nil {
+		auctionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, auction)
+}
+
+// PlaceBid 对拍卖出价
+func (h *PetHandler) PlaceBid(c *gin.Context) {
+	var req PlaceBidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	auction, err := h.petService.PlaceBid(c.Param("id"), req.PetID, req.Amount)
+	if err != nil {
+		auctionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, auction)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPetBank 获取宠物的银行存款与贷款
+func (h *PetHandler) GetPetBank(c *gin.Context) {
+	petID := c.Param("id")
+
+	info, err := h.petService.GetBankInfo(petID)
+	if err != nil {
+		if errors.Is(err, services.ErrPetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SpawnBossRequest struct {
+	Template string `json:"template"`
+	Location string `json:"location"`
+}
+
+// GetWorldBoss 获取当前世界首领、伤害排行和最近的首领战
+func (h *PetHandler) GetWorldBoss(c *gin.Context) {
+	boss, err := h.petService.GetWorldBoss()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, boss)
+}
+
+// SpawnWorldBoss 手动召唤世界首领，模板和地点留空时随机
+func (h *PetHandler) SpawnWorldBoss(c *gin.Context) {
+	var req SpawnBossRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	boss, err := h.petService.SpawnBoss(req.Template, req.Location)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrBossActive) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, boss)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetEconomyTimeseries 获取金币产出、消耗及货币总量的时间序列
+// 查询参数：interval（默认1h，需为5m的倍数）、from/to（RFC3339，默认最近24小时）
+func (h *PetHandler) GetEconomyTimeseries(c *gin.Context) {
+	interval, err := time.ParseDuration(c.DefaultQuery("interval", "1h"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid interval"})
+		return
+	}
+
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return
+		}
+	}
+
+	from := to.Add(-24 * time.Hour)
+	if fromStr := c.Query("from"); fromStr != "" {
+		if from, err = time.Parse(time.RFC3339, fromStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return
+		}
+	}
+
+	series, err := h.petService.GetEconomyTimeseries(from, to, interval)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidTimeseries) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"miningpet/internal/models"
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SetGoalRequest struct {
+	Kind     models.GoalKind `json:"kind" binding:"required"`
+	Target   int             `json:"target"`
+	Item     string          `json:"item"`
+	Location string          `json:"location"`
+}
+
+// SetPetGoal 主人为宠物指定目标，宠物会规划行动去达成
+func (h *PetHandler) SetPetGoal(c *gin.Context) {
+	var req SetGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, err := h.petService.SetPetGoal(c.Param("id"), req.Kind, req.Target, req.Item, req.Location)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrPetNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pet_id": c.Param("id"), "goal": goal})
+}
+
+// ClearPetGoal 让宠物放弃当前目标
+func (h *PetHandler) ClearPetGoal(c *gin.Context) {
+	if err := h.petService.ClearPetGoal(c.Param("id")); err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, services.ErrNoGoal) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "goal cleared"})
+}
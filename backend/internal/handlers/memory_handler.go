@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPetMemories 查询宠物的记忆，可按类别、地点和交往对象筛选
+func (h *PetHandler) GetPetMemories(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		limit = 20
+	}
+
+	memories, err := h.petService.QueryMemories(c.Param("id"), services.MemoryFilter{
+		Kind:      c.Query("kind"),
+		Location:  c.Query("location"),
+		PartnerID: c.Query("partner"),
+		Forgotten: c.Query("forgotten") == "true",
+		SortBy:    c.Query("sort"),
+		Limit:     limit,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrPetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pet_id": c.Param("id"), "memories": memories, "count": len(memories)})
+}
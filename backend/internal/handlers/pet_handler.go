@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"miningpet/internal/models"
 	"miningpet/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -18,8 +20,11 @@ func NewPetHandler(petService *services.PetService) *PetHandler {
 	}
 }
 
+// CreatePetRequest 主人可以指定性格标签或完整的性格特质，都不指定时性格随机
 type CreatePetRequest struct {
-	OwnerName string `json:"owner_name" binding:"required"`
+	OwnerName   string                    `json:"owner_name" binding:"required"`
+	Personality models.PetPersonality     `json:"personality"`
+	Traits      *models.PersonalityTraits `json:"traits"`
 }
 
 func (h *PetHandler) CreatePet(c *gin.Context) {
@@ -29,7 +34,26 @@ func (h *PetHandler) CreatePet(c *gin.Context) {
 		return
 	}
 
-	pet, err := h.petService.CreatePet(req.OwnerName)
+	traits := models.RandomTraits()
+	switch {
+	case req.Traits != nil && req.Personality != "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "personality 和 traits 只能指定一个"})
+		return
+	case req.Traits != nil:
+		if err := req.Traits.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		traits = *req.Traits
+	case req.Personality != "":
+		if !models.IsPersonality(req.Personality) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "未知的性格: " + string(req.Personality)})
+			return
+		}
+		traits = models.TraitsFor(req.Personality)
+	}
+
+	pet, err := h.petService.CreatePetWithTraits(req.OwnerName, traits)
 	if err != nil {
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
@@ -179,4 +203,30 @@ func (h *PetHandler) GetPetFriends(c *gin.Context) {
 		"friends": pet.Friends,
 		"count":   len(pet.Friends),
 	})
-}
\ No newline at end of file
+}
+
+// GetPetLedger 分页获取宠物的金币账本
+func (h *PetHandler) GetPetLedger(c *gin.Context) {
+	petID := c.Param("id")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		offset = 0
+	}
+
+	ledger, err := h.petService.GetPetLedger(petID, limit, offset)
+	if err != nil {
+		if errors.Is(err, services.ErrPetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
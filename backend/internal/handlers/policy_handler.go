@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"miningpet/internal/models"
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPetPolicy 获取宠物的行为策略设置
+func (h *PetHandler) GetPetPolicy(c *gin.Context) {
+	policy, err := h.petService.GetPetPolicy(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pet_id": c.Param("id"), "policy": policy})
+}
+
+// UpdatePetPolicy 修改宠物的行为策略，只需提供要修改的字段
+func (h *PetHandler) UpdatePetPolicy(c *gin.Context) {
+	var req models.PolicyUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := h.petService.UpdatePetPolicy(c.Param("id"), req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrPetNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pet_id": c.Param("id"), "policy": policy})
+}
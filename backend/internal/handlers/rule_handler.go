@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AddRuleRequest struct {
+	Rule     string `json:"rule" binding:"required"`
+	Priority int    `json:"priority"`
+	Cooldown int    `json:"cooldown"`
+}
+
+// ruleErrorStatus 自动化规则错误对应的状态码
+func ruleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrPetNotFound), errors.Is(err, services.ErrRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrTooManyRules):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// GetRules 获取宠物的自动化规则
+func (h *PetHandler) GetRules(c *gin.Context) {
+	rules, err := h.petService.GetRules(c.Param("id"))
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// AddRule 添加一条自动化规则，规则文本在服务端解析和校验
+func (h *PetHandler) AddRule(c *gin.Context) {
+	var req AddRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.petService.AddRule(c.Param("id"), req.Rule, req.Priority, req.Cooldown)
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"rule": rule})
+}
+
+// UpdateRule 启用、停用规则或修改其优先级和冷却时间
+func (h *PetHandler) UpdateRule(c *gin.Context) {
+	var req services.RuleUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.petService.UpdateRule(c.Param("id"), c.Param("rule"), req)
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rule": rule})
+}
+
+// RemoveRule 删除一条自动化规则
+func (h *PetHandler) RemoveRule(c *gin.Context) {
+	if err := h.petService.RemoveRule(c.Param("id"), c.Param("rule")); err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "rule removed"})
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SetStrategyRequest struct {
+	Strategy string `json:"strategy" binding:"required"`
+}
+
+// GetStrategies 获取所有已注册的 AI 决策策略
+func (h *PetHandler) GetStrategies(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"default": services.DefaultStrategy, "strategies": services.ListStrategies()})
+}
+
+// SetPetStrategy 为宠物选择 AI 决策策略
+func (h *PetHandler) SetPetStrategy(c *gin.Context) {
+	var req SetStrategyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pet, err := h.petService.SetPetStrategy(c.Param("id"), req.Strategy)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrPetNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pet_id": pet.ID, "strategy": req.Strategy})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type WalletAmountRequest struct {
+	Amount int `json:"amount" binding:"required"`
+}
+
+type TransferCoinsRequest struct {
+	TransferID string `json:"transfer_id" binding:"required"`
+	ToOwner    string `json:"to_owner" binding:"required"`
+	Amount     int    `json:"amount" binding:"required"`
+	Memo       string `json:"memo"`
+}
+
+// walletError 将钱包服务错误映射为HTTP状态码
+func walletError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTransferConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// GetWallet 获取主人钱包
+func (h *PetHandler) GetWallet(c *gin.Context) {
+	wallet, err := h.petService.GetWallet(c.Param("owner"))
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// DepositToWallet 把宠物金币存入主人钱包
+func (h *PetHandler) DepositToWallet(c *gin.Context) {
+	var req WalletAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wallet, err := h.petService.DepositToWallet(c.Param("owner"), req.Amount)
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// WithdrawFromWallet 从主人钱包取出金币给宠物
+func (h *PetHandler) WithdrawFromWallet(c *gin.Context) {
+	var req WalletAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wallet, err := h.petService.WithdrawFromWallet(c.Param("owner"), req.Amount)
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// TransferCoins 向其他主人转账
+func (h *PetHandler) TransferCoins(c *gin.Context) {
+	var req TransferCoinsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, duplicate, err := h.petService.TransferCoins(services.TransferRequest{
+		TransferID: req.TransferID,
+		FromOwner:  c.Param("owner"),
+		ToOwner:    req.ToOwner,
+		Amount:     req.Amount,
+		Memo:       req.Memo,
+	})
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if duplicate {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"transfer": transfer, "duplicate": duplicate})
+}
+
+// GetTransfers 获取主人的转账记录
+func (h *PetHandler) GetTransfers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		offset = 0
+	}
+
+	transfers, err := h.petService.GetTransfers(c.Param("owner"), limit, offset)
+	if err != nil {
+		walletError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"miningpet/internal/models"
+	"miningpet/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type WorldEventRequest struct {
+	Name        string                     `json:"name" binding:"required"`
+	Description string                     `json:"description"`
+	Location    string                     `json:"location"`
+	Modifiers   models.WorldEventModifiers `json:"modifiers"`
+	StartAt     time.Time                  `json:"start_at"`
+	EndAt       time.Time                  `json:"end_at" binding:"required"`
+	CreatedBy   string                     `json:"created_by"`
+}
+
+func (r WorldEventRequest) toService() services.WorldEventRequest {
+	return services.WorldEventRequest{
+		Name:        r.Name,
+		Description: r.Description,
+		Location:    r.Location,
+		Modifiers:   r.Modifiers,
+		StartAt:     r.StartAt,
+		EndAt:       r.EndAt,
+		CreatedBy:   r.CreatedBy,
+	}
+}
+
+// GetWorldEvents 获取进行中和即将开始的世界事件
+func (h *PetHandler) GetWorldEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, h.petService.GetWorldEvents())
+}
+
+// GetWorldEventHistory 分页获取世界事件记录（管理）
+func (h *PetHandler) GetWorldEventHistory(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		offset = 0
+	}
+
+	events, err := h.petService.GetWorldEventHistory(c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// PreviewWorldEvent 预览世界事件的效果，不会保存（管理）
+func (h *PetHandler) PreviewWorldEvent(c *gin.Context) {
+	var req WorldEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preview, err := h.petService.PreviewWorldEvent(req.toService())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// CreateWorldEvent 创建世界事件（管理）
+func (h *PetHandler) CreateWorldEvent(c *gin.Context) {
+	var req WorldEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event, err := h.petService.CreateWorldEvent(req.toService())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// CancelWorldEvent 取消世界事件（管理）
+func (h *PetHandler) CancelWorldEvent(c *gin.Context) {
+	event, err := h.petService.CancelWorldEvent(c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWorldEventNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrWorldEventClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWorldMap 获取世界地图：各地点的资源存量、在场宠物和产出系数
+func (h *PetHandler) GetWorldMap(c *gin.Context) {
+	c.JSON(http.StatusOK, h.petService.GetWorldMap())
+}
+
+// GetWorldConditions 获取游戏时钟和各地点的天气
+func (h *PetHandler) GetWorldConditions(c *gin.Context) {
+	c.JSON(http.StatusOK, h.petService.GetWorldConditions())
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+)
+
+const escrowAccountPrefix = "escrow:"
+
+// EscrowAccount 返回拍卖的托管账户，最高出价在拍卖结束前存放在这里
+func EscrowAccount(auctionID string) LedgerAccount {
+	return LedgerAccount(escrowAccountPrefix + auctionID)
+}
+
+const (
+	ReasonAuctionBid        LedgerReason = "auction_bid"        // 出价者 -> 托管
+	ReasonAuctionRefund     LedgerReason = "auction_refund"     // 托管 -> 被超过的出价者
+	ReasonAuctionSale       LedgerReason = "auction_sale"       // 托管 -> 卖家
+	ReasonAuctionCommission LedgerReason = "auction_commission" // 托管 -> 国库（拍卖行佣金）
+)
+
+// AuctionStatus 拍卖状态
+type AuctionStatus string
+
+const (
+	AuctionOpen    AuctionStatus = "open"
+	AuctionSold    AuctionStatus = "sold"
+	AuctionExpired AuctionStatus = "expired" // 到期无人出价，物品退回卖家
+)
+
+// Auction 拍卖
+type Auction struct {
+	ID                string        `json:"id"`
+	SellerID          string        `json:"seller_id"`
+	SellerName        string        `json:"seller_name"`
+	Item              Item          `json:"item"` // 拍卖期间物品由拍卖行保管
+	StartingBid       int           `json:"starting_bid"`
+	BuyoutPrice       int           `json:"buyout_price,omitempty"` // 0 表示不支持一口价
+	CurrentBid        int           `json:"current_bid"`
+	HighestBidderID   string        `json:"highest_bidder_id,omitempty"`
+	HighestBidderName string        `json:"highest_bidder_name,omitempty"`
+	BidCount          int           `json:"bid_count"`
+	Commission        int           `json:"commission"`
+	Status            AuctionStatus `json:"status"`
+	CreatedAt         time.Time     `json:"created_at"`
+	EndsAt            time.Time     `json:"ends_at"`
+	SettledAt         *time.Time    `json:"settled_at,omitempty"`
+}
+
+// MinNextBid 下一次出价的最低金额（每次至少加价5%）
+func (a *Auction) MinNextBid() int {
+	if a.HighestBidderID == "" {
+		return a.StartingBid
+	}
+	increment := a.CurrentBid * 5 / 100
+	if increment < 1 {
+		increment = 1
+	}
+	return a.CurrentBid + increment
+}
+
+// Bid 出价记录
+type Bid struct {
+	ID        string    `json:"id"`
+	AuctionID string    `json:"auction_id"`
+	PetID     string    `json:"pet_id"`
+	PetName   string    `json:"pet_name"`
+	Amount    int       `json:"amount"`
+	Buyout    bool      `json:"buyout,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
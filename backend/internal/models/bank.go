@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+// AccountBank 银行放贷资金账户：贷款从这里发放，还款回到这里
+const AccountBank LedgerAccount = "bank"
+
+const bankDepositAccountPrefix = "bank_deposit:"
+
+// BankDepositAccount 返回宠物在银行的存款账户
+func BankDepositAccount(petID string) LedgerAccount {
+	return LedgerAccount(bankDepositAccountPrefix + petID)
+}
+
+const loanReceivableAccountPrefix = "loan_receivable:"
+
+// LoanReceivableAccount 返回国库对宠物贷款利息的应收账户
+func LoanReceivableAccount(petID string) LedgerAccount {
+	return LedgerAccount(loanReceivableAccountPrefix + petID)
+}
+
+const (
+	ReasonBankDeposit   LedgerReason = "bank_deposit"   // 宠物 -> 银行存款
+	ReasonBankWithdraw  LedgerReason = "bank_withdraw"  // 银行存款 -> 宠物
+	ReasonInterest      LedgerReason = "interest"       // 存款利息
+	ReasonLoan          LedgerReason = "loan"           // 银行放贷
+	ReasonLoanRepayment LedgerReason = "loan_repayment" // 主动还款
+	ReasonLoanGarnish   LedgerReason = "loan_garnish"   // 逾期贷款从奖励中扣款
+	ReasonLoanInterest  LedgerReason = "loan_interest"  // 贷款计息：国库 -> 应收
+)
+
+// LoanStatus 贷款状态
+type LoanStatus string
+
+const (
+	LoanActive  LoanStatus = "active"
+	LoanOverdue LoanStatus = "overdue"
+	LoanRepaid  LoanStatus = "repaid"
+)
+
+// BankAccount 宠物的银行存款账户
+type BankAccount struct {
+	PetID          string    `json:"pet_id"`
+	Balance        int       `json:"balance"`
+	InterestEarned int       `json:"interest_earned"`
+	LastInterestAt time.Time `json:"last_interest_at"` // 上一次结息的时间
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Loan 宠物的贷款
+type Loan struct {
+	ID            string     `json:"id"`
+	PetID         string     `json:"pet_id"`
+	Principal     int        `json:"principal"`
+	Outstanding   int        `json:"outstanding"` // 尚未归还的本息
+	DailyRate     int        `json:"daily_rate"`  // 每个游戏日的利率（百分比）
+	Status        LoanStatus `json:"status"`
+	IssuedAt      time.Time  `json:"issued_at"`
+	DueAt         time.Time  `json:"due_at"`
+	LastAccruedAt time.Time  `json:"last_accrued_at"` // 上一次计息的时间
+	RepaidAt      *time.Time `json:"repaid_at,omitempty"`
+}
+
+// IsOpen 贷款是否尚未还清
+func (l *Loan) IsOpen() bool {
+	return l.Status != LoanRepaid
+}
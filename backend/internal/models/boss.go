@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+)
+
+// ReasonBossReward 世界首领的击杀奖励
+const ReasonBossReward LedgerReason = "boss_reward"
+
+// BossStatus 世界首领状态
+type BossStatus string
+
+const (
+	BossActive   BossStatus = "active"
+	BossDefeated BossStatus = "defeated"
+	BossEscaped  BossStatus = "escaped" // 限时内未被击败
+)
+
+// BossTemplate 世界首领设定
+type BossTemplate struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	MaxHP      int    `json:"max_hp"`
+	Attack     int    `json:"attack"`
+	Defense    int    `json:"defense"`
+	RewardPool int    `json:"reward_pool"` // 按伤害比例分配的金币
+	ExpPool    int    `json:"exp_pool"`    // 按伤害比例分配的经验
+}
+
+var BossTemplates = []BossTemplate{
+	{ID: "crystal_golem", Name: "水晶巨像", MaxHP: 1200, Attack: 25, Defense: 10, RewardPool: 3000, ExpPool: 600},
+	{ID: "shadow_dragon", Name: "暗影巨龙", MaxHP: 2500, Attack: 40, Defense: 15, RewardPool: 6000, ExpPool: 1200},
+	{ID: "swamp_hydra", Name: "沼泽九头蛇", MaxHP: 1800, Attack: 30, Defense: 8, RewardPool: 4000, ExpPool: 900},
+}
+
+// FindBossTemplate 按ID或名称查找首领设定
+func FindBossTemplate(idOrName string) (BossTemplate, bool) {
+	for _, template := range BossTemplates {
+		if template.ID == idOrName || template.Name == idOrName {
+			return template, true
+		}
+	}
+	return BossTemplate{}, false
+}
+
+// WorldBoss 一场世界首领战
+type WorldBoss struct {
+	ID         string     `json:"id"`
+	TemplateID string     `json:"template_id"`
+	Name       string     `json:"name"`
+	Location   string     `json:"location"`
+	MaxHP      int        `json:"max_hp"`
+	HP         int        `json:"hp"`
+	Attack     int        `json:"attack"`
+	Defense    int        `json:"defense"`
+	RewardPool int        `json:"reward_pool"`
+	ExpPool    int        `json:"exp_pool"`
+	Status     BossStatus `json:"status"`
+	SpawnedAt  time.Time  `json:"spawned_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+}
+
+// BossContribution 宠物对首领造成的累计伤害
+type BossContribution struct {
+	BossID  string `json:"boss_id"`
+	PetID   string `json:"pet_id"`
+	PetName string `json:"pet_name"`
+	Owner   string `json:"owner"`
+	Damage  int    `json:"damage"`
+	Hits    int    `json:"hits"`
+	Reward  int    `json:"reward,omitempty"`
+}
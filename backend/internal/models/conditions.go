@@ -0,0 +1,179 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeOfDay 游戏内的时段
+type TimeOfDay string
+
+const (
+	TimeDawn  TimeOfDay = "黎明"
+	TimeDay   TimeOfDay = "白天"
+	TimeDusk  TimeOfDay = "黄昏"
+	TimeNight TimeOfDay = "夜晚"
+)
+
+// Weather 地点天气
+type Weather string
+
+const (
+	WeatherClear Weather = "晴朗"
+	WeatherRain  Weather = "下雨"
+	WeatherStorm Weather = "暴风雨"
+	WeatherFog   Weather = "大雾"
+)
+
+// GameClock 游戏时钟
+type GameClock struct {
+	Day    int       `json:"day"`
+	Hour   int       `json:"hour"`
+	Minute int       `json:"minute"`
+	Period TimeOfDay `json:"period"`
+}
+
+// String 形如 "第12天 21:30"
+func (c GameClock) String() string {
+	return fmt.Sprintf("第%d天 %02d:%02d", c.Day, c.Hour, c.Minute)
+}
+
+// Climate 地点各种天气出现的概率（百分比），其余时间晴朗；室内地点不受天气和昼夜影响
+type Climate struct {
+	Rain      int  `json:"rain"`
+	Storm     int  `json:"storm"`
+	Fog       int  `json:"fog"`
+	Sheltered bool `json:"sheltered"`
+}
+
+var LocationClimates = map[string]Climate{
+	"起始村庄":   {Rain: 20, Storm: 5, Fog: 10},
+	"北方森林":   {Rain: 25, Storm: 5, Fog: 20},
+	"东部山脉":   {Rain: 15, Storm: 20, Fog: 15},
+	"南方沼泽":   {Rain: 35, Storm: 10, Fog: 25},
+	"西部草原":   {Rain: 20, Storm: 10, Fog: 5},
+	"神秘洞穴":   {Sheltered: true},
+	"古老废墟":   {Rain: 15, Storm: 5, Fog: 25},
+	"水晶矿洞":   {Sheltered: true},
+	"魔法森林":   {Rain: 20, Storm: 5, Fog: 30},
+	"暗影峡谷":   {Rain: 10, Storm: 15, Fog: 30},
+	"天空之城遗址": {Rain: 10, Storm: 30, Fog: 20},
+}
+
+// NightMonsters 只在夜晚的野外出没的怪物
+var NightMonsters = []Monster{
+	{Name: "夜枭", Health: 35, Attack: 14, Defense: 3, ExpReward: 25, CoinReward: 12},
+	{Name: "影狼", Health: 55, Attack: 18, Defense: 5, ExpReward: 40, CoinReward: 20},
+	{Name: "鬼火", Health: 25, Attack: 20, Defense: 1, ExpReward: 30, CoinReward: 18},
+}
+
+// Conditions 某地点当前的时间和天气
+type Conditions struct {
+	Location     string    `json:"location"`
+	Clock        GameClock `json:"clock"`
+	Weather      Weather   `json:"weather"`
+	WeatherUntil time.Time `json:"weather_until"` // 天气下一次变化的时间
+	Sheltered    bool      `json:"sheltered"`
+}
+
+// Night 野外是否处于夜晚
+func (c Conditions) Night() bool {
+	return !c.Sheltered && c.Clock.Period == TimeNight
+}
+
+// Label 用于事件消息的简短描述，如 "夜晚·暴风雨"
+func (c Conditions) Label() string {
+	if c.Sheltered {
+		return fmt.Sprintf("%s·室内", c.Clock.Period)
+	}
+	return fmt.Sprintf("%s·%s", c.Clock.Period, c.Weather)
+}
+
+// EncounterWeights 随机事件类型的权重
+func (c Conditions) EncounterWeights() map[EventType]int {
+	weights := map[EventType]int{
+		EventExplore:   10,
+		EventBattle:    10,
+		EventDiscovery: 10,
+		EventSocial:    10,
+		EventReward:    10,
+	}
+	if c.Sheltered {
+		return weights
+	}
+
+	switch c.Clock.Period {
+	case TimeNight:
+		// 夜里怪物更活跃，遇到其他宠物的机会更少
+		weights[EventBattle] += 6
+		weights[EventSocial] -= 5
+	case TimeDusk:
+		weights[EventBattle] += 3
+	case TimeDawn:
+		weights[EventSocial] += 3
+	}
+
+	switch c.Weather {
+	case WeatherRain:
+		// 雨水冲刷出地表的矿石
+		weights[EventDiscovery] += 4
+		weights[EventSocial] -= 4
+	case WeatherStorm:
+		weights[EventBattle] += 3
+		weights[EventSocial] -= 8
+		weights[EventReward] -= 4
+	case WeatherFog:
+		// 雾中容易迷路，也容易撞见被遗忘的东西
+		weights[EventExplore] += 4
+		weights[EventDiscovery] += 3
+		weights[EventSocial] -= 3
+	}
+
+	for eventType, weight := range weights {
+		if weight < 1 {
+			weights[eventType] = 1
+		}
+	}
+	return weights
+}
+
+// EnergyDrain 在野外行动时额外消耗的体力
+func (c Conditions) EnergyDrain() int {
+	if c.Sheltered {
+		return 0
+	}
+
+	drain := 0
+	if c.Clock.Period == TimeNight {
+		drain++
+	}
+	switch c.Weather {
+	case WeatherRain:
+		drain++
+	case WeatherStorm:
+		drain += 3
+	}
+	return drain
+}
+
+// ExploreModifier 天气和昼夜对探索意愿的影响：勇敢的宠物不怕夜路和风暴，谨慎的宠物更怕，好奇的宠物喜欢雾天
+func (c Conditions) ExploreModifier(traits PersonalityTraits) int {
+	if c.Sheltered {
+		return 0
+	}
+
+	modifier := 0
+	if c.Clock.Period == TimeNight {
+		modifier += -15 + traits.Weigh(map[Trait]int{TraitCourage: 40, TraitCaution: -40})
+	}
+
+	switch c.Weather {
+	case WeatherRain:
+		modifier -= 10
+	case WeatherStorm:
+		modifier += -30 + traits.Weigh(map[Trait]int{TraitCourage: 30})
+	case WeatherFog:
+		modifier += -10 + traits.Weigh(map[Trait]int{TraitCuriosity: 25})
+	}
+	return modifier
+}
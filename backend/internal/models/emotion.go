@@ -0,0 +1,118 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// EmotionKind 由事件引发的短暂情绪
+type EmotionKind string
+
+const (
+	EmotionJoy        EmotionKind = "joy"        // 喜悦：稀有发现、胜利、升级
+	EmotionFear       EmotionKind = "fear"       // 恐惧：战败、死里逃生
+	EmotionSadness    EmotionKind = "sadness"    // 难过：失去金币、放弃目标
+	EmotionLoneliness EmotionKind = "loneliness" // 孤独：长时间没有社交
+)
+
+const (
+	// EmotionThreshold 强度低于该值的情绪不再影响心情，并会被清理
+	EmotionThreshold = 0.05
+	// FeltEmotionThreshold 强度达到该值的情绪会体现在宠物的言行中
+	FeltEmotionThreshold = 0.3
+)
+
+// EmotionHalfLife 各种情绪强度减半所需的时间，恐惧和孤独比喜悦更持久
+var EmotionHalfLife = map[EmotionKind]time.Duration{
+	EmotionJoy:        20 * time.Minute,
+	EmotionFear:       40 * time.Minute,
+	EmotionSadness:    30 * time.Minute,
+	EmotionLoneliness: time.Hour,
+}
+
+// Emotion 宠物正在感受的一种情绪，强度 0-1，随时间衰减
+type Emotion struct {
+	Kind      EmotionKind `json:"kind"`
+	Intensity float64     `json:"intensity"` // FeltAt 时的强度
+	Cause     string      `json:"cause"`     // 最近一次引发该情绪的原因
+	FeltAt    time.Time   `json:"felt_at"`
+}
+
+// Current 衰减后的当前强度
+func (e Emotion) Current(now time.Time) float64 {
+	halfLife, exists := EmotionHalfLife[e.Kind]
+	if !exists || now.Before(e.FeltAt) {
+		return e.Intensity
+	}
+	return e.Intensity * math.Pow(0.5, float64(now.Sub(e.FeltAt))/float64(halfLife))
+}
+
+// Emotions 宠物当前的各种情绪，每种最多一条
+type Emotions []Emotion
+
+// Level 某种情绪的当前强度
+func (es Emotions) Level(kind EmotionKind, now time.Time) float64 {
+	for _, emotion := range es {
+		if emotion.Kind == kind {
+			return emotion.Current(now)
+		}
+	}
+	return 0
+}
+
+// Strongest 当前最强烈的情绪，没有达到 FeltEmotionThreshold 的情绪时返回 false
+func (es Emotions) Strongest(now time.Time) (Emotion, bool) {
+	var strongest Emotion
+	best := FeltEmotionThreshold
+	found := false
+	for _, emotion := range es {
+		if level := emotion.Current(now); level >= best {
+			strongest, best, found = emotion, level, true
+		}
+	}
+	if found {
+		strongest.Intensity, strongest.FeltAt = best, now
+	}
+	return strongest, found
+}
+
+// Feel 感受到一种情绪，与尚未消散的同种情绪叠加但不超过 1，同时清理已经消散的情绪
+func (p *Pet) Feel(kind EmotionKind, intensity float64, cause string, now time.Time) {
+	intensity = math.Max(0, math.Min(1, intensity))
+	current := p.Emotions.Level(kind, now)
+	feeling := Emotion{
+		Kind:      kind,
+		Intensity: math.Round((current+intensity*(1-current))*1000) / 1000,
+		Cause:     cause,
+		FeltAt:    now,
+	}
+
+	emotions := make(Emotions, 0, len(p.Emotions)+1)
+	for _, emotion := range p.Emotions {
+		if emotion.Kind != kind && emotion.Current(now) >= EmotionThreshold {
+			emotions = append(emotions, emotion)
+		}
+	}
+	p.Emotions = append(emotions, feeling)
+	p.updateMood()
+}
+
+// Soothe 平复一种情绪，amount 为减弱的比例
+func (p *Pet) Soothe(kind EmotionKind, amount float64, now time.Time) {
+	for i := range p.Emotions {
+		if p.Emotions[i].Kind == kind {
+			p.Emotions[i].Intensity = math.Round(p.Emotions[i].Current(now)*(1-amount)*1000) / 1000
+			p.Emotions[i].FeltAt = now
+		}
+	}
+	p.updateMood()
+}
+
+// emotionScore 情绪对心情得分的影响
+func (p *Pet) emotionScore(now time.Time) int {
+	score := 4*p.Emotions.Level(EmotionJoy, now) -
+		4*p.Emotions.Level(EmotionFear, now) -
+		3*p.Emotions.Level(EmotionSadness, now) -
+		3*p.Emotions.Level(EmotionLoneliness, now)
+	return int(math.Round(score))
+}
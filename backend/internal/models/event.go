@@ -14,6 +14,12 @@ const (
 	EventReward      EventType = "reward"
 	EventLevelUp     EventType = "level_up"
 	EventRareFind    EventType = "rare_find"
+	EventTransfer    EventType = "transfer"
+	EventBoss        EventType = "boss"
+	EventDigest      EventType = "digest"      // 离线期间的活动摘要
+	EventRule        EventType = "rule"        // 主人的自动化规则触发
+	EventGoal        EventType = "goal"        // 目标的确立、重新规划与达成
+	EventPersonality EventType = "personality" // 经历改变了宠物的性格
 )
 
 type Event struct {
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// GoalKind 宠物目标的类别
+type GoalKind string
+
+const (
+	// GoalSaveCoins 攒够一定金币，Item 不为空时攒钱是为了买这件装备
+	GoalSaveCoins GoalKind = "save_coins"
+	// GoalReachLevel 升到指定等级
+	GoalReachLevel GoalKind = "reach_level"
+	// GoalMakeFriend 在指定地点结识朋友
+	GoalMakeFriend GoalKind = "make_friend"
+)
+
+// GoalTimeout 目标迟迟没有达成时放弃
+const GoalTimeout = 6 * time.Hour
+
+// PlanStep 计划中的一步
+type PlanStep struct {
+	Action string                 `json:"action"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// PetGoal 宠物正在追求的目标，以及为达成目标规划的行动
+type PetGoal struct {
+	ID          string   `json:"id"`
+	Kind        GoalKind `json:"kind"`
+	Description string   `json:"description"`
+	// Target 目标金币数或等级
+	Target   int    `json:"target,omitempty"`
+	Item     string `json:"item,omitempty"`
+	Location string `json:"location,omitempty"`
+	// Plan 接下来的行动，执行一步移除一步，情况变化时重新规划
+	Plan    []PlanStep `json:"plan"`
+	Replans int        `json:"replans"`
+	// AssignedBy 主人指定的目标为 "owner"，宠物自己立下的目标为空
+	AssignedBy string    `json:"assigned_by,omitempty"`
+	AdoptedAt  time.Time `json:"adopted_at"`
+}
+
+// Expired 目标是否已经追求太久
+func (g *PetGoal) Expired(now time.Time) bool {
+	return now.Sub(g.AdoptedAt) > GoalTimeout
+}
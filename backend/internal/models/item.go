@@ -0,0 +1,146 @@
+package models
+
+const (
+	ItemTypeEquipment  = "equipment"  // 装备：持有即生效
+	ItemTypeTreasure   = "treasure"   // 珍宝：没有用途，可以在拍卖行出售
+	ItemTypeMaterial   = "material"   // 材料：用于制作
+	ItemTypeConsumable = "consumable" // 消耗品：使用后恢复属性
+)
+
+// ItemEffect 消耗品的效果
+type ItemEffect struct {
+	Health int `json:"health,omitempty"`
+	Energy int `json:"energy,omitempty"`
+	Hunger int `json:"hunger,omitempty"`
+}
+
+// ReasonGear 购买装备
+const ReasonGear LedgerReason = "gear"
+
+// GearCatalog 起始村庄商店出售的装备
+var GearCatalog = []Item{
+	{ID: "wooden_sword", Name: "木剑", Type: ItemTypeEquipment, Rarity: "common", Value: 60, Quantity: 1, Attack: 3},
+	{ID: "leather_armor", Name: "皮甲", Type: ItemTypeEquipment, Rarity: "common", Value: 80, Quantity: 1, Defense: 3},
+	{ID: "iron_sword", Name: "铁剑", Type: ItemTypeEquipment, Rarity: "uncommon", Value: 200, Quantity: 1, Attack: 8},
+	{ID: "iron_armor", Name: "铁甲", Type: ItemTypeEquipment, Rarity: "uncommon", Value: 260, Quantity: 1, Defense: 7},
+	{ID: "mithril_blade", Name: "秘银刃", Type: ItemTypeEquipment, Rarity: "rare", Value: 600, Quantity: 1, Attack: 18},
+}
+
+// RareFinds 探索中极少数情况下发现的珍宝
+var RareFinds = []Item{
+	{ID: "mystery_ore", Name: "神秘矿石", Type: ItemTypeTreasure, Rarity: "legendary", Value: 800, Quantity: 1},
+	{ID: "dragon_scale", Name: "龙鳞", Type: ItemTypeTreasure, Rarity: "legendary", Value: 1200, Quantity: 1},
+	{ID: "star_fragment", Name: "星辰碎片", Type: ItemTypeTreasure, Rarity: "epic", Value: 600, Quantity: 1},
+}
+
+// FindGear 按ID或名称查找商店装备
+func FindGear(idOrName string) (Item, bool) {
+	for _, gear := range GearCatalog {
+		if gear.ID == idOrName || gear.Name == idOrName {
+			return gear, true
+		}
+	}
+	return Item{}, false
+}
+
+// FindItem 查找背包中的物品
+func (p *Pet) FindItem(id string) *Item {
+	for i := range p.Inventory {
+		if p.Inventory[i].ID == id {
+			return &p.Inventory[i]
+		}
+	}
+	return nil
+}
+
+// AddItem 放入背包，同类物品叠加；装备的属性加成立即生效
+func (p *Pet) AddItem(item Item) {
+	if item.Quantity <= 0 {
+		item.Quantity = 1
+	}
+	if item.Type == ItemTypeEquipment {
+		p.Attack += item.Attack * item.Quantity
+		p.Defense += item.Defense * item.Quantity
+	}
+
+	if existing := p.FindItem(item.ID); existing != nil {
+		existing.Quantity += item.Quantity
+		return
+	}
+	p.Inventory = append(p.Inventory, item)
+}
+
+// RemoveItem 从背包取出指定数量的物品，数量不足时返回 false
+func (p *Pet) RemoveItem(id string, quantity int) (Item, bool) {
+	for i := range p.Inventory {
+		item := p.Inventory[i]
+		if item.ID != id {
+			continue
+		}
+		if quantity <= 0 || item.Quantity < quantity {
+			return Item{}, false
+		}
+
+		if item.Type == ItemTypeEquipment {
+			p.Attack -= item.Attack * quantity
+			p.Defense -= item.Defense * quantity
+		}
+
+		if item.Quantity == quantity {
+			p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
+		} else {
+			p.Inventory[i].Quantity -= quantity
+		}
+
+		item.Quantity = quantity
+		return item, true
+	}
+	return Item{}, false
+}
+
+// UseItem 使用一个消耗品，返回其效果
+func (p *Pet) UseItem(id string) (Item, bool) {
+	item := p.FindItem(id)
+	if item == nil || item.Type != ItemTypeConsumable || item.Effect == nil {
+		return Item{}, false
+	}
+
+	used, _ := p.RemoveItem(id, 1)
+	p.Heal(used.Effect.Health)
+	p.RestoreEnergy(used.Effect.Energy)
+	p.Feed(used.Effect.Hunger)
+	return used, true
+}
+
+// FindConsumable 查找能满足指定需求的消耗品，need 为 "health"、"energy" 或 "hunger"
+func (p *Pet) FindConsumable(need string) *Item {
+	for i := range p.Inventory {
+		item := &p.Inventory[i]
+		if item.Type != ItemTypeConsumable || item.Effect == nil {
+			continue
+		}
+		switch {
+		case need == "health" && item.Effect.Health > 0,
+			need == "energy" && item.Effect.Energy > 0,
+			need == "hunger" && item.Effect.Hunger > 0:
+			return item
+		}
+	}
+	return nil
+}
+
+// NextGear 商店中宠物尚未拥有的最便宜装备
+func (p *Pet) NextGear() (Item, bool) {
+	var next Item
+	found := false
+	for _, gear := range GearCatalog {
+		if p.FindItem(gear.ID) != nil {
+			continue
+		}
+		if !found || gear.Value < next.Value {
+			next = gear
+			found = true
+		}
+	}
+	return next, found
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// LearningHalfLife 学到的偏好在没有新经验时减半所需的时间
+const LearningHalfLife = 72 * time.Hour
+
+// minLearningRate 经验很多之后，新结果仍然占的最小比重，保证偏好能跟上变化
+const minLearningRate = 0.1
+
+// LearnedWeight 宠物从自身经历中学到的一项偏好，Value 在 [-1, 1] 之间，0 为中性
+type LearnedWeight struct {
+	Value     float64   `json:"value"`
+	Samples   int       `json:"samples"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LearnedWeights 按行为或"行为@地点"记录的偏好
+type LearnedWeights map[string]LearnedWeight
+
+// LearnedKey 偏好的键，location 为空时表示整类行为
+func LearnedKey(action, location string) string {
+	if location == "" {
+		return action
+	}
+	return action + "@" + location
+}
+
+// Value 偏好在 now 时的取值，随时间向中性衰减
+func (w LearnedWeights) Value(key string, now time.Time) float64 {
+	weight, exists := w[key]
+	if !exists {
+		return 0
+	}
+	elapsed := now.Sub(weight.UpdatedAt)
+	if elapsed <= 0 {
+		return weight.Value
+	}
+	return weight.Value * math.Pow(0.5, float64(elapsed)/float64(LearningHalfLife))
+}
+
+// Learn 用一次结果更新偏好：reward 在 [-1, 1] 之间，早期经验的影响更大
+func (w LearnedWeights) Learn(key string, reward float64, now time.Time) {
+	reward = math.Max(-1, math.Min(1, reward))
+	weight := w[key]
+	value := w.Value(key, now)
+	rate := math.Max(minLearningRate, 1/float64(weight.Samples+1))
+	w[key] = LearnedWeight{
+		Value:     value + rate*(reward-value),
+		Samples:   weight.Samples + 1,
+		UpdatedAt: now,
+	}
+}
+
+// Snapshot 所有偏好在 now 时的取值
+func (w LearnedWeights) Snapshot(now time.Time) map[string]float64 {
+	values := make(map[string]float64, len(w))
+	for key := range w {
+		values[key] = math.Round(w.Value(key, now)*1000) / 1000
+	}
+	return values
+}
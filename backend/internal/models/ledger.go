@@ -0,0 +1,85 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerAccount 账本账户
+type LedgerAccount string
+
+const (
+	AccountMint     LedgerAccount = "mint"     // 铸币账户：所有新产生金币的来源
+	AccountShop     LedgerAccount = "shop"     // 商店账户：购买食物等消费的去向
+	AccountTreasury LedgerAccount = "treasury" // 国库账户：手续费、佣金等的去向
+)
+
+const petAccountPrefix = "pet:"
+
+// PetAccount 返回宠物的金币账户
+func PetAccount(petID string) LedgerAccount {
+	return LedgerAccount(petAccountPrefix + petID)
+}
+
+// PetID 如果是宠物账户则返回宠物ID
+func (a LedgerAccount) PetID() (string, bool) {
+	if strings.HasPrefix(string(a), petAccountPrefix) {
+		return strings.TrimPrefix(string(a), petAccountPrefix), true
+	}
+	return "", false
+}
+
+// LedgerReason 金币流动原因
+type LedgerReason string
+
+const (
+	ReasonOpening   LedgerReason = "opening_balance" // 账本上线前已有的余额
+	ReasonStarter   LedgerReason = "starter"         // 新宠物初始金币
+	ReasonBattle    LedgerReason = "battle"
+	ReasonDiscovery LedgerReason = "discovery"
+	ReasonReward    LedgerReason = "reward"
+	ReasonRareFind  LedgerReason = "rare_find"
+	ReasonAddCoins  LedgerReason = "addcoins"
+	ReasonFood      LedgerReason = "food"
+)
+
+// LedgerEntry 不可变的复式记账分录
+// 借方(Debit)为金币流入的账户，贷方(Credit)为金币流出的账户
+type LedgerEntry struct {
+	ID        string        `json:"id"`
+	PetID     string        `json:"pet_id"`
+	Debit     LedgerAccount `json:"debit"`
+	Credit    LedgerAccount `json:"credit"`
+	Amount    int           `json:"amount"`
+	Reason    LedgerReason  `json:"reason"`
+	Memo      string        `json:"memo,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// NewLedgerEntry 创建一条分录
+func NewLedgerEntry(petID string, debit, credit LedgerAccount, amount int, reason LedgerReason, memo string) *LedgerEntry {
+	return &LedgerEntry{
+		ID:        uuid.New().String(),
+		PetID:     petID,
+		Debit:     debit,
+		Credit:    credit,
+		Amount:    amount,
+		Reason:    reason,
+		Memo:      memo,
+		CreatedAt: time.Now(),
+	}
+}
+
+// SignedAmount 返回分录对指定账户余额的影响
+func (e *LedgerEntry) SignedAmount(account LedgerAccount) int {
+	switch account {
+	case e.Debit:
+		return e.Amount
+	case e.Credit:
+		return -e.Amount
+	default:
+		return 0
+	}
+}
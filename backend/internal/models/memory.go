@@ -0,0 +1,80 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// MemoryKind 记忆的类别
+type MemoryKind string
+
+const (
+	MemoryBattleWon  MemoryKind = "battle_won"
+	MemoryBattleLost MemoryKind = "battle_lost"
+	MemoryDiscovery  MemoryKind = "discovery"
+	MemoryRareFind   MemoryKind = "rare_find"
+	MemoryMeeting    MemoryKind = "meeting"
+	MemoryBoss       MemoryKind = "boss"
+	MemoryLevelUp    MemoryKind = "level_up"
+	MemoryFled       MemoryKind = "fled"
+
+	MemoryGoalAchieved  MemoryKind = "goal_achieved"
+	MemoryGoalAbandoned MemoryKind = "goal_abandoned"
+
+	// MemoryLegacy 升级前以文字记下的记忆，迁移后没有地点和情绪
+	MemoryLegacy MemoryKind = "legacy"
+)
+
+const (
+	// MaxMemories 宠物同时记得的记忆条数，超出时忘掉印象最淡的一条
+	MaxMemories = 30
+	// MemoryHalfLife 平淡的记忆印象减半所需的时间，情绪越强烈的记忆褪色越慢
+	MemoryHalfLife = 48 * time.Hour
+)
+
+// Memory 宠物的一段经历：发生了什么、在哪里、和谁，以及当时的感受
+type Memory struct {
+	ID          string     `json:"id"`
+	PetID       string     `json:"pet_id"`
+	Kind        MemoryKind `json:"kind"`
+	Summary     string     `json:"summary"`
+	Location    string     `json:"location,omitempty"`
+	PartnerID   string     `json:"partner_id,omitempty"`
+	PartnerName string     `json:"partner_name,omitempty"`
+	// Valence 情绪效价 -100（非常糟糕）到 100（非常美好）
+	Valence int `json:"valence"`
+	// Importance 重要程度 1-100，决定记忆能保留多久
+	Importance int       `json:"importance"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Strength 记忆在 now 时的印象深浅
+func (m Memory) Strength(now time.Time) float64 {
+	halfLife := float64(MemoryHalfLife) * (1 + math.Abs(float64(m.Valence))/50)
+	age := math.Max(0, float64(now.Sub(m.CreatedAt)))
+	return float64(m.Importance) * math.Pow(0.5, age/halfLife)
+}
+
+// Feeling 记忆带来的情绪，等于效价乘以印象深浅的比例，范围 [-1, 1]
+func (m Memory) Feeling(now time.Time) float64 {
+	return float64(m.Valence) / 100 * m.Strength(now) / 100
+}
+
+// AddMemory 记住一段经历。记忆满了时忘掉印象最淡的一条（可能就是新的这条）并返回它
+func (p *Pet) AddMemory(memory Memory) *Memory {
+	p.Memories = append(p.Memories, memory)
+	if len(p.Memories) <= MaxMemories {
+		return nil
+	}
+
+	now := time.Now()
+	weakest := 0
+	for i, m := range p.Memories {
+		if m.Strength(now) < p.Memories[weakest].Strength(now) {
+			weakest = i
+		}
+	}
+	forgotten := p.Memories[weakest]
+	p.Memories = append(p.Memories[:weakest:weakest], p.Memories[weakest+1:]...)
+	return &forgotten
+}
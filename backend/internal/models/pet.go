@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +25,10 @@ const (
 	StatusFighting  PetStatus = "战斗中"
 	StatusResting   PetStatus = "休息中"
 	StatusSocializing PetStatus = "社交中"
+	StatusBanking   PetStatus = "银行办理中"
+	StatusCrafting  PetStatus = "制作中"
+	StatusForaging  PetStatus = "寻找食物"
+	StatusEating    PetStatus = "进食中"
 )
 
 type PetMood string
@@ -37,37 +42,51 @@ const (
 )
 
 type Pet struct {
-	ID           string         `json:"id"`
-	Name         string         `json:"name"`
-	Owner        string         `json:"owner"`
-	Personality  PetPersonality `json:"personality"`
-	Level        int            `json:"level"`
-	Experience   int            `json:"experience"`
-	Health       int            `json:"health"`
-	MaxHealth    int            `json:"max_health"`
-	Energy       int            `json:"energy"`        // 体力值 0-100
-	MaxEnergy    int            `json:"max_energy"`
-	Hunger       int            `json:"hunger"`        // 饱食度 0-100
-	Social       int            `json:"social"`        // 社交度 0-100
-	Mood         PetMood        `json:"mood"`          // 心情状态
-	Attack       int            `json:"attack"`
-	Defense      int            `json:"defense"`
-	Coins        int            `json:"coins"`
-	Location     string         `json:"location"`
-	Status       PetStatus      `json:"status"`
-	Memory       []string       `json:"memory"`        // 宠物记忆
-	Friends      []string       `json:"friends"`       // 朋友列表
-	LastActivity time.Time      `json:"last_activity"`
-	CreatedAt    time.Time      `json:"created_at"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Owner        string            `json:"owner"`
+	Personality  PetPersonality    `json:"personality"`
+	Traits       PersonalityTraits `json:"traits"`       // 多维性格，Personality 为其中的主导特质
+	Strategy     string            `json:"strategy"`     // AI 决策策略
+	Policy       PetPolicy         `json:"policy"`       // 主人设定的行为倾向
+	Level        int               `json:"level"`
+	Experience   int               `json:"experience"`
+	Health       int               `json:"health"`
+	MaxHealth    int               `json:"max_health"`
+	Energy       int               `json:"energy"`       // 体力值 0-100
+	MaxEnergy    int               `json:"max_energy"`
+	Hunger       int               `json:"hunger"`       // 饱食度 0-100
+	Social       int               `json:"social"`       // 社交度 0-100
+	Mood         PetMood           `json:"mood"`         // 心情状态
+	Emotions     Emotions          `json:"emotions"`     // 由事件引发、随时间消散的情绪
+	Attack       int               `json:"attack"`
+	Defense      int               `json:"defense"`
+	Coins        int               `json:"coins"`
+	Location     string            `json:"location"`
+	Status       PetStatus         `json:"status"`
+	Memories     []Memory          `json:"memories"`     // 宠物记得的经历
+	Friends      []string          `json:"friends"`      // 朋友列表
+	Inventory    []Item            `json:"inventory"`    // 背包
+	ActionQueue  []QueuedAction    `json:"action_queue"` // 主人安排的行动计划
+	Rules        []AutomationRule  `json:"rules"`        // 主人编写的自动化规则
+	Learned      LearnedWeights    `json:"learned"`      // 从自身经历中学到的偏好
+	Goal         *PetGoal          `json:"goal"`         // 正在追求的目标
+	LastActivity time.Time         `json:"last_activity"`
+	// SimulatedAt 属性衰减已推进到的时刻，重启时的离线结算从这里开始
+	SimulatedAt time.Time `json:"simulated_at"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Item struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Type     string `json:"type"`
-	Rarity   string `json:"rarity"`
-	Value    int    `json:"value"`
-	Quantity int    `json:"quantity"`
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Rarity   string      `json:"rarity"`
+	Value    int         `json:"value"`
+	Quantity int         `json:"quantity"`
+	Attack   int         `json:"attack,omitempty"`  // 装备攻击加成
+	Defense  int         `json:"defense,omitempty"` // 装备防御加成
+	Effect   *ItemEffect `json:"effect,omitempty"`  // 消耗品效果
 }
 
 type Inventory struct {
@@ -75,12 +94,13 @@ type Inventory struct {
 	Items []Item `json:"items"`
 }
 
+// NewPet 创建性格随机的宠物
 func NewPet(ownerName string) *Pet {
-	personalities := []PetPersonality{
-		PersonalityBrave, PersonalityGreedy, PersonalityFriendly,
-		PersonalityCautious, PersonalityCurious,
-	}
-	
+	return NewPetWithTraits(ownerName, RandomTraits())
+}
+
+// NewPetWithTraits 按指定的性格特质创建宠物，性格标签为主导特质
+func NewPetWithTraits(ownerName string, traits PersonalityTraits) *Pet {
 	petNames := []string{
 		"Lucky", "Brave", "Shadow", "Spark", "Whisper",
 		"Thunder", "Frost", "Blaze", "Swift", "Mystic",
@@ -90,7 +110,9 @@ func NewPet(ownerName string) *Pet {
 		ID:           uuid.New().String(),
 		Name:         petNames[len(ownerName)%len(petNames)],
 		Owner:        ownerName,
-		Personality:  personalities[len(ownerName)%len(personalities)],
+		Personality:  traits.Dominant(""),
+		Traits:       traits,
+		Policy:       DefaultPetPolicy(),
 		Level:        1,
 		Experience:   0,
 		Health:       100,
@@ -100,14 +122,20 @@ func NewPet(ownerName string) *Pet {
 		Hunger:       80,  // 稍微饿一点，需要关注
 		Social:       50,  // 中等社交需求
 		Mood:         MoodHappy,
+		Emotions:     make(Emotions, 0),
 		Attack:       10,
 		Defense:      5,
 		Coins:        0,
 		Location:     "起始村庄",
 		Status:       StatusIdle,
-		Memory:       make([]string, 0),
+		Memories:     make([]Memory, 0),
+		ActionQueue:  make([]QueuedAction, 0),
+		Rules:        make([]AutomationRule, 0),
+		Learned:      make(LearnedWeights),
 		Friends:      make([]string, 0),
+		Inventory:    make([]Item, 0),
 		LastActivity: time.Now(),
+		SimulatedAt:  time.Now(),
 		CreatedAt:    time.Now(),
 	}
 
@@ -233,14 +261,7 @@ func (p *Pet) DecreaseSocial(amount int) {
 	p.updateMood()
 }
 
-// 记忆和朋友管理
-func (p *Pet) AddMemory(memory string) {
-	p.Memory = append(p.Memory, memory)
-	if len(p.Memory) > 10 { // 只保留最近10条记忆
-		p.Memory = p.Memory[1:]
-	}
-}
-
+// 朋友管理
 func (p *Pet) AddFriend(friendName string) {
 	for _, friend := range p.Friends {
 		if friend == friendName {
@@ -284,6 +305,10 @@ func (p *Pet) updateMood() {
 		score -= 1
 	}
 	
+	// 事件引发的情绪叠加在身体状况之上
+	emotional := p.emotionScore(time.Now())
+	score += emotional
+	
 	// 根据得分设置心情
 	switch {
 	case score >= 3:
@@ -294,25 +319,32 @@ func (p *Pet) updateMood() {
 		p.Mood = MoodNeutral
 	case score >= -3:
 		p.Mood = MoodSad
+	case score-emotional >= -3:
+		// 身体并不差，是情绪让宠物低落
+		p.Mood = MoodSad
 	default:
 		p.Mood = MoodTired
 	}
 }
 
-// 获取心情影响的行为倾向
+// 获取心情影响的行为倾向，喜悦让宠物更积极，恐惧、难过和孤独让宠物更消沉
 func (p *Pet) GetMoodInfluence() float64 {
+	influence := 1.0
 	switch p.Mood {
 	case MoodExcited:
-		return 1.5
+		influence = 1.5
 	case MoodHappy:
-		return 1.2
-	case MoodNeutral:
-		return 1.0
+		influence = 1.2
 	case MoodSad:
-		return 0.8
+		influence = 0.8
 	case MoodTired:
-		return 0.6
-	default:
-		return 1.0
+		influence = 0.6
 	}
+	
+	now := time.Now()
+	influence += 0.2*p.Emotions.Level(EmotionJoy, now) -
+		0.3*p.Emotions.Level(EmotionFear, now) -
+		0.1*p.Emotions.Level(EmotionSadness, now) -
+		0.1*p.Emotions.Level(EmotionLoneliness, now)
+	return math.Max(0.4, math.Min(1.7, influence))
 }
\ No newline at end of file
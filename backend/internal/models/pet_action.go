@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+)
+
+// PetAction 宠物正在进行的限时行动，持久化后重启时可以继续或直接完成
+type PetAction struct {
+	ID        string                 `json:"id"`
+	PetID     string                 `json:"pet_id"`
+	Type      string                 `json:"type"`
+	Status    PetStatus              `json:"status"` // 行动期间宠物的状态
+	Reason    string                 `json:"reason,omitempty"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+	StartedAt time.Time              `json:"started_at"`
+	Duration  int                    `json:"duration"` // 秒
+}
+
+// EndsAt 行动预计完成的时间
+func (a *PetAction) EndsAt() time.Time {
+	return a.StartedAt.Add(time.Duration(a.Duration) * time.Second)
+}
+
+// Progress 行动在 now 时的完成比例，范围 [0, 1]
+func (a *PetAction) Progress(now time.Time) float64 {
+	if a.Duration <= 0 {
+		return 1
+	}
+	progress := now.Sub(a.StartedAt).Seconds() / float64(a.Duration)
+	if progress < 0 {
+		return 0
+	}
+	if progress > 1 {
+		return 1
+	}
+	return progress
+}
+
+// ActionProgress 行动进度快照，供状态查询和进度推送使用
+type ActionProgress struct {
+	PetID     string    `json:"pet_id"`
+	ActionID  string    `json:"action_id"`
+	Type      string    `json:"type"`
+	Status    PetStatus `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Duration  int       `json:"duration"`  // 秒
+	Percent   int       `json:"percent"`   // 0-100
+	Remaining int       `json:"remaining"` // 剩余秒数
+}
+
+// ProgressAt 行动在 now 时的进度快照
+func (a *PetAction) ProgressAt(now time.Time) ActionProgress {
+	remaining := int(a.EndsAt().Sub(now).Seconds() + 0.5)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return ActionProgress{
+		PetID:     a.PetID,
+		ActionID:  a.ID,
+		Type:      a.Type,
+		Status:    a.Status,
+		Reason:    a.Reason,
+		StartedAt: a.StartedAt,
+		EndsAt:    a.EndsAt(),
+		Duration:  a.Duration,
+		Percent:   int(a.Progress(now) * 100),
+		Remaining: remaining,
+	}
+}
+
+// QueuedAction 主人排入计划的一步行动，按顺序执行，执行中的一步留在队首直到结束
+type QueuedAction struct {
+	ID         string                 `json:"id"`
+	Command    string                 `json:"command"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	EnqueuedAt time.Time              `json:"enqueued_at"`
+	StartedAt  *time.Time             `json:"started_at,omitempty"`
+}
+
+// Running 这一步是否已经开始执行
+func (q QueuedAction) Running() bool {
+	return q.StartedAt != nil
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+)
+
+// 行为策略各项设置的取值范围
+const (
+	MaxSpendingLimit      = 10000
+	MinRestThreshold      = 10
+	MaxRestThreshold      = 90
+	MaxPreferredLocations = 3
+)
+
+// PetPolicy 主人为宠物设定的行为倾向，与性格一起影响 AI 决策
+type PetPolicy struct {
+	// RiskTolerance 冒险程度 0-100，50 为中性
+	RiskTolerance int `json:"risk_tolerance"`
+	// SpendingLimit 单次行为最多花费的金币，0 表示不限制
+	SpendingLimit int `json:"spending_limit"`
+	// RestThreshold 体力低于该百分比时倾向休息
+	RestThreshold int `json:"rest_threshold"`
+	// SocialAppetite 社交意愿 0-100，50 为中性
+	SocialAppetite int `json:"social_appetite"`
+	// PreferredLocations 偏爱的探索地点
+	PreferredLocations []string `json:"preferred_locations"`
+}
+
+// DefaultPetPolicy 不改变性格原有倾向的默认设置
+func DefaultPetPolicy() PetPolicy {
+	return PetPolicy{
+		RiskTolerance:      50,
+		SpendingLimit:      0,
+		RestThreshold:      30,
+		SocialAppetite:     50,
+		PreferredLocations: []string{},
+	}
+}
+
+// Validate 检查各项设置是否在允许范围内
+func (p PetPolicy) Validate() error {
+	if p.RiskTolerance < 0 || p.RiskTolerance > 100 {
+		return fmt.Errorf("risk_tolerance 应在 0-100 之间: %d", p.RiskTolerance)
+	}
+	if p.SpendingLimit < 0 || p.SpendingLimit > MaxSpendingLimit {
+		return fmt.Errorf("spending_limit 应在 0-%d 之间: %d", MaxSpendingLimit, p.SpendingLimit)
+	}
+	if p.RestThreshold < MinRestThreshold || p.RestThreshold > MaxRestThreshold {
+		return fmt.Errorf("rest_threshold 应在 %d-%d 之间: %d", MinRestThreshold, MaxRestThreshold, p.RestThreshold)
+	}
+	if p.SocialAppetite < 0 || p.SocialAppetite > 100 {
+		return fmt.Errorf("social_appetite 应在 0-100 之间: %d", p.SocialAppetite)
+	}
+	if len(p.PreferredLocations) > MaxPreferredLocations {
+		return fmt.Errorf("最多设置%d个偏爱地点", MaxPreferredLocations)
+	}
+	for _, location := range p.PreferredLocations {
+		if !isLocation(location) {
+			return fmt.Errorf("未知的地点: %s", location)
+		}
+	}
+	return nil
+}
+
+// AllowsSpending 一次花费是否在限额之内
+func (p PetPolicy) AllowsSpending(amount int) bool {
+	return p.SpendingLimit == 0 || amount <= p.SpendingLimit
+}
+
+// Prefers 是否偏爱该地点
+func (p PetPolicy) Prefers(location string) bool {
+	for _, preferred := range p.PreferredLocations {
+		if preferred == location {
+			return true
+		}
+	}
+	return false
+}
+
+// FleeHealthPercent 探索中生命低于该百分比时逃回，越爱冒险坚持得越久
+func (p PetPolicy) FleeHealthPercent() int {
+	return 40 - p.RiskTolerance*3/10
+}
+
+// PolicyUpdate 对行为策略的部分修改，未提供的字段保持不变
+type PolicyUpdate struct {
+	RiskTolerance      *int      `json:"risk_tolerance"`
+	SpendingLimit      *int      `json:"spending_limit"`
+	RestThreshold      *int      `json:"rest_threshold"`
+	SocialAppetite     *int      `json:"social_appetite"`
+	PreferredLocations *[]string `json:"preferred_locations"`
+}
+
+// Apply 返回应用修改后的新策略
+func (u PolicyUpdate) Apply(p PetPolicy) PetPolicy {
+	if u.RiskTolerance != nil {
+		p.RiskTolerance = *u.RiskTolerance
+	}
+	if u.SpendingLimit != nil {
+		p.SpendingLimit = *u.SpendingLimit
+	}
+	if u.RestThreshold != nil {
+		p.RestThreshold = *u.RestThreshold
+	}
+	if u.SocialAppetite != nil {
+		p.SocialAppetite = *u.SocialAppetite
+	}
+	if u.PreferredLocations != nil {
+		p.PreferredLocations = append([]string{}, *u.PreferredLocations...)
+	}
+	return p
+}
+
+func isLocation(location string) bool {
+	for _, candidate := range Locations {
+		if candidate == location {
+			return true
+		}
+	}
+	return false
+}
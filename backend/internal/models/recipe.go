@@ -0,0 +1,126 @@
+package models
+
+// ReasonCrafting 制作时支付的工本费
+const ReasonCrafting LedgerReason = "crafting"
+
+// Materials 探索中发现、可用于制作的材料
+var Materials = []Item{
+	{ID: "mystic_crystal", Name: "神秘水晶", Type: ItemTypeMaterial, Rarity: "uncommon", Value: 15, Quantity: 1},
+	{ID: "ancient_scroll", Name: "古老卷轴", Type: ItemTypeMaterial, Rarity: "uncommon", Value: 12, Quantity: 1},
+	{ID: "shiny_gem", Name: "闪光宝石", Type: ItemTypeMaterial, Rarity: "common", Value: 10, Quantity: 1},
+	{ID: "magic_potion", Name: "魔法药水", Type: ItemTypeMaterial, Rarity: "common", Value: 8, Quantity: 1},
+	{ID: "ancient_rune", Name: "远古符文", Type: ItemTypeMaterial, Rarity: "rare", Value: 25, Quantity: 1},
+	{ID: "rare_ore", Name: "珍稀矿石", Type: ItemTypeMaterial, Rarity: "rare", Value: 20, Quantity: 1},
+	{ID: "mystic_relic", Name: "神秘遗物", Type: ItemTypeMaterial, Rarity: "rare", Value: 30, Quantity: 1},
+}
+
+// FindMaterial 按ID查找材料
+func FindMaterial(id string) (Item, bool) {
+	for _, material := range Materials {
+		if material.ID == id {
+			return material, true
+		}
+	}
+	return Item{}, false
+}
+
+// FindMaterialByName 按名称查找材料
+func FindMaterialByName(name string) (Item, bool) {
+	for _, material := range Materials {
+		if material.Name == name {
+			return material, true
+		}
+	}
+	return Item{}, false
+}
+
+// RecipeInput 配方需要的材料
+type RecipeInput struct {
+	ItemID   string `json:"item_id"`
+	Quantity int    `json:"quantity"`
+}
+
+// Recipe 制作配方
+type Recipe struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Inputs     []RecipeInput `json:"inputs"`
+	Coins      int           `json:"coins"`       // 工本费
+	Duration   int           `json:"duration"`    // 秒
+	BaseChance int           `json:"base_chance"` // 达到最低等级时的成功率（百分比）
+	MinLevel   int           `json:"min_level"`
+	Output     Item          `json:"output"`
+}
+
+var Recipes = []Recipe{
+	{
+		ID: "healing_potion", Name: "治疗药剂",
+		Inputs: []RecipeInput{{ItemID: "magic_potion", Quantity: 1}, {ItemID: "shiny_gem", Quantity: 1}},
+		Coins:  10, Duration: 20, BaseChance: 80, MinLevel: 1,
+		Output: Item{ID: "healing_potion", Name: "治疗药剂", Type: ItemTypeConsumable, Rarity: "common", Value: 40, Quantity: 1, Effect: &ItemEffect{Health: 40}},
+	},
+	{
+		ID: "energy_crystal", Name: "能量水晶",
+		Inputs: []RecipeInput{{ItemID: "mystic_crystal", Quantity: 2}},
+		Coins:  15, Duration: 30, BaseChance: 70, MinLevel: 1,
+		Output: Item{ID: "energy_crystal", Name: "能量水晶", Type: ItemTypeConsumable, Rarity: "uncommon", Value: 50, Quantity: 1, Effect: &ItemEffect{Energy: 50}},
+	},
+	{
+		ID: "relic_feast", Name: "遗迹盛宴",
+		Inputs: []RecipeInput{{ItemID: "mystic_relic", Quantity: 1}, {ItemID: "magic_potion", Quantity: 1}},
+		Coins:  5, Duration: 25, BaseChance: 75, MinLevel: 1,
+		Output: Item{ID: "relic_feast", Name: "遗迹盛宴", Type: ItemTypeConsumable, Rarity: "uncommon", Value: 45, Quantity: 1, Effect: &ItemEffect{Hunger: 60}},
+	},
+	{
+		ID: "rune_amulet", Name: "符文护符",
+		Inputs: []RecipeInput{{ItemID: "ancient_rune", Quantity: 1}, {ItemID: "shiny_gem", Quantity: 2}, {ItemID: "mystic_relic", Quantity: 1}},
+		Coins:  40, Duration: 45, BaseChance: 55, MinLevel: 2,
+		Output: Item{ID: "rune_amulet", Name: "符文护符", Type: ItemTypeEquipment, Rarity: "rare", Value: 220, Quantity: 1, Attack: 4, Defense: 4},
+	},
+	{
+		ID: "rune_sword", Name: "符文剑",
+		Inputs: []RecipeInput{{ItemID: "ancient_rune", Quantity: 2}, {ItemID: "rare_ore", Quantity: 2}},
+		Coins:  50, Duration: 60, BaseChance: 50, MinLevel: 3,
+		Output: Item{ID: "rune_sword", Name: "符文剑", Type: ItemTypeEquipment, Rarity: "rare", Value: 400, Quantity: 1, Attack: 12},
+	},
+	{
+		ID: "crystal_armor", Name: "水晶护甲",
+		Inputs: []RecipeInput{{ItemID: "mystic_crystal", Quantity: 2}, {ItemID: "rare_ore", Quantity: 1}, {ItemID: "ancient_scroll", Quantity: 1}},
+		Coins:  60, Duration: 60, BaseChance: 45, MinLevel: 3,
+		Output: Item{ID: "crystal_armor", Name: "水晶护甲", Type: ItemTypeEquipment, Rarity: "rare", Value: 450, Quantity: 1, Defense: 10},
+	},
+}
+
+// FindRecipe 按ID或名称查找配方
+func FindRecipe(idOrName string) (Recipe, bool) {
+	for _, recipe := range Recipes {
+		if recipe.ID == idOrName || recipe.Name == idOrName {
+			return recipe, true
+		}
+	}
+	return Recipe{}, false
+}
+
+// SuccessChance 宠物在指定等级的成功率，每高出最低等级一级+5%，最高95%
+func (r Recipe) SuccessChance(level int) int {
+	chance := r.BaseChance + (level-r.MinLevel)*5
+	if chance > 95 {
+		chance = 95
+	}
+	return chance
+}
+
+// MissingInputs 宠物还缺少的材料（材料ID -> 缺少的数量）
+func (r Recipe) MissingInputs(p *Pet) map[string]int {
+	missing := make(map[string]int)
+	for _, input := range r.Inputs {
+		have := 0
+		if item := p.FindItem(input.ItemID); item != nil {
+			have = item.Quantity
+		}
+		if have < input.Quantity {
+			missing[input.ItemID] = input.Quantity - have
+		}
+	}
+	return missing
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// RuleCondition 规则中的一个比较条件，如 "health < 40%" 或 "event battle"
+type RuleCondition struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	// Value 数值条件的比较值，Percent 为真时表示相对上限的百分比
+	Value   int  `json:"value,omitempty"`
+	Percent bool `json:"percent,omitempty"`
+	// Text 文本条件（状态、地点、事件类型）的比较值
+	Text string `json:"text,omitempty"`
+}
+
+// AutomationRule 主人为宠物编写的自动化规则：所有条件成立时执行指令
+type AutomationRule struct {
+	ID         string                 `json:"id"`
+	Source     string                 `json:"source"`
+	Conditions []RuleCondition        `json:"conditions"`
+	Command    string                 `json:"command"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	// Priority 越大越先评估，每次只触发一条规则
+	Priority int `json:"priority"`
+	// Cooldown 触发后多少秒内不再触发
+	Cooldown    int        `json:"cooldown"`
+	Enabled     bool       `json:"enabled"`
+	FireCount   int        `json:"fire_count"`
+	LastFiredAt *time.Time `json:"last_fired_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Ready 规则是否启用且不在冷却中
+func (r AutomationRule) Ready(now time.Time) bool {
+	if !r.Enabled {
+		return false
+	}
+	return r.LastFiredAt == nil || now.Sub(*r.LastFiredAt) >= time.Duration(r.Cooldown)*time.Second
+}
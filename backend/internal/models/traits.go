@@ -0,0 +1,165 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Trait 性格的一个维度
+type Trait string
+
+const (
+	TraitCourage     Trait = "courage"
+	TraitGreed       Trait = "greed"
+	TraitSociability Trait = "sociability"
+	TraitCaution     Trait = "caution"
+	TraitCuriosity   Trait = "curiosity"
+)
+
+const (
+	// PersonalityShiftMargin 另一项特质超过当前主导特质这么多时，性格标签才会改变，避免来回跳动
+	PersonalityShiftMargin = 0.05
+	// dominantTraitValue 按性格标签生成特质时主导特质的取值，其余特质为 1 - dominantTraitValue
+	dominantTraitValue = 0.7
+)
+
+// traitOrder 各特质与性格标签的对应关系，取值相同时靠前的优先
+var traitOrder = []struct {
+	trait       Trait
+	personality PetPersonality
+}{
+	{TraitCourage, PersonalityBrave},
+	{TraitGreed, PersonalityGreedy},
+	{TraitSociability, PersonalityFriendly},
+	{TraitCaution, PersonalityCautious},
+	{TraitCuriosity, PersonalityCurious},
+}
+
+// PersonalityTraits 多维性格，每项取值 0-1，会随经历缓慢变化
+type PersonalityTraits struct {
+	Courage     float64 `json:"courage"`
+	Greed       float64 `json:"greed"`
+	Sociability float64 `json:"sociability"`
+	Caution     float64 `json:"caution"`
+	Curiosity   float64 `json:"curiosity"`
+}
+
+// RandomTraits 随机生成的性格，各项在 0.2-0.7 之间
+func RandomTraits() PersonalityTraits {
+	value := func() float64 { return math.Round((0.2+rand.Float64()*0.5)*100) / 100 }
+	return PersonalityTraits{
+		Courage:     value(),
+		Greed:       value(),
+		Sociability: value(),
+		Caution:     value(),
+		Curiosity:   value(),
+	}
+}
+
+// TraitsFor 以某个性格标签为主导的特质，用于主人指定性格以及没有特质数据的旧宠物
+func TraitsFor(personality PetPersonality) PersonalityTraits {
+	traits := PersonalityTraits{}
+	for _, entry := range traitOrder {
+		value := 1 - dominantTraitValue
+		if entry.personality == personality {
+			value = dominantTraitValue
+		}
+		*traits.field(entry.trait) = value
+	}
+	return traits
+}
+
+// IsPersonality 是否为已知的性格标签
+func IsPersonality(personality PetPersonality) bool {
+	for _, entry := range traitOrder {
+		if entry.personality == personality {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *PersonalityTraits) field(trait Trait) *float64 {
+	switch trait {
+	case TraitCourage:
+		return &t.Courage
+	case TraitGreed:
+		return &t.Greed
+	case TraitSociability:
+		return &t.Sociability
+	case TraitCaution:
+		return &t.Caution
+	case TraitCuriosity:
+		return &t.Curiosity
+	}
+	return nil
+}
+
+// Value 某项特质的取值
+func (t PersonalityTraits) Value(trait Trait) float64 {
+	if field := t.field(trait); field != nil {
+		return *field
+	}
+	return 0
+}
+
+// Validate 检查各项特质是否在 0-1 之间
+func (t PersonalityTraits) Validate() error {
+	for _, entry := range traitOrder {
+		if value := t.Value(entry.trait); value < 0 || value > 1 {
+			return fmt.Errorf("%s 应在 0-1 之间: %g", entry.trait, value)
+		}
+	}
+	return nil
+}
+
+// Weigh 按各特质的权重求和并取整，用于把性格折算为行为优先级
+func (t PersonalityTraits) Weigh(weights map[Trait]int) int {
+	sum := 0.0
+	for trait, weight := range weights {
+		sum += t.Value(trait) * float64(weight)
+	}
+	return int(math.Round(sum))
+}
+
+// Drift 让某项特质变化 amount。勇气与谨慎此消彼长，一方增加时另一方减少一半
+func (t *PersonalityTraits) Drift(trait Trait, amount float64) {
+	field := t.field(trait)
+	if field == nil {
+		return
+	}
+	*field = clampTrait(*field + amount)
+
+	var opposite Trait
+	switch trait {
+	case TraitCourage:
+		opposite = TraitCaution
+	case TraitCaution:
+		opposite = TraitCourage
+	default:
+		return
+	}
+	other := t.field(opposite)
+	*other = clampTrait(*other - amount/2)
+}
+
+func clampTrait(value float64) float64 {
+	return math.Round(math.Max(0, math.Min(1, value))*1000) / 1000
+}
+
+// Dominant 主导特质对应的性格标签。current 为当前标签，只有其他特质明显更突出时才会改变
+func (t PersonalityTraits) Dominant(current PetPersonality) PetPersonality {
+	best, bestValue := PersonalityBrave, -1.0
+	for _, entry := range traitOrder {
+		if value := t.Value(entry.trait); value > bestValue {
+			best, bestValue = entry.personality, value
+		}
+	}
+	for _, entry := range traitOrder {
+		if entry.personality == current && t.Value(entry.trait)+PersonalityShiftMargin > bestValue {
+			return current
+		}
+	}
+	return best
+}
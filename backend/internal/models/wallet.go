@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+const walletAccountPrefix = "wallet:"
+
+// WalletAccount 返回主人钱包的金币账户
+func WalletAccount(owner string) LedgerAccount {
+	return LedgerAccount(walletAccountPrefix + owner)
+}
+
+const (
+	ReasonWalletDeposit  LedgerReason = "wallet_deposit"  // 宠物 -> 钱包
+	ReasonWalletWithdraw LedgerReason = "wallet_withdraw" // 钱包 -> 宠物
+	ReasonTransfer       LedgerReason = "transfer"        // 钱包 -> 钱包
+	ReasonTransferFee    LedgerReason = "transfer_fee"    // 转账手续费
+)
+
+// Wallet 主人钱包，与宠物金币相互独立
+type Wallet struct {
+	Owner     string    `json:"owner"`
+	Balance   int       `json:"balance"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Transfer 主人之间的转账记录
+type Transfer struct {
+	ID        string    `json:"id"` // 由客户端提供，用于幂等
+	FromOwner string    `json:"from_owner"`
+	ToOwner   string    `json:"to_owner"`
+	Amount    int       `json:"amount"`
+	Fee       int       `json:"fee"`
+	Memo      string    `json:"memo,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// ResourceProfile 地点资源池的容量与再生速度
+type ResourceProfile struct {
+	MaxOre        int `json:"max_ore"`
+	MaxTreasure   int `json:"max_treasure"`
+	OreRegen      int `json:"ore_regen"`      // 每分钟再生的矿量
+	TreasureRegen int `json:"treasure_regen"` // 每小时再生的宝藏数
+}
+
+// LocationProfiles 各地点的资源设定
+var LocationProfiles = map[string]ResourceProfile{
+	"起始村庄":   {MaxOre: 200, MaxTreasure: 1, OreRegen: 4, TreasureRegen: 1},
+	"北方森林":   {MaxOre: 600, MaxTreasure: 3, OreRegen: 10, TreasureRegen: 1},
+	"东部山脉":   {MaxOre: 1200, MaxTreasure: 4, OreRegen: 15, TreasureRegen: 1},
+	"南方沼泽":   {MaxOre: 500, MaxTreasure: 5, OreRegen: 8, TreasureRegen: 2},
+	"西部草原":   {MaxOre: 400, MaxTreasure: 2, OreRegen: 12, TreasureRegen: 1},
+	"神秘洞穴":   {MaxOre: 900, MaxTreasure: 6, OreRegen: 10, TreasureRegen: 2},
+	"古老废墟":   {MaxOre: 500, MaxTreasure: 8, OreRegen: 6, TreasureRegen: 2},
+	"水晶矿洞":   {MaxOre: 2000, MaxTreasure: 5, OreRegen: 20, TreasureRegen: 1},
+	"魔法森林":   {MaxOre: 700, MaxTreasure: 5, OreRegen: 10, TreasureRegen: 2},
+	"暗影峡谷":   {MaxOre: 1000, MaxTreasure: 6, OreRegen: 12, TreasureRegen: 1},
+	"天空之城遗址": {MaxOre: 800, MaxTreasure: 10, OreRegen: 8, TreasureRegen: 3},
+}
+
+// LocationResources 地点当前的资源存量，被该地所有宠物共同消耗
+type LocationResources struct {
+	Location string `json:"location"`
+	Ore      int    `json:"ore"`
+	Treasure int    `json:"treasure"`
+	// 上一次再生结算的时间，停机期间的再生在启动后补上
+	OreRegenAt      time.Time `json:"ore_regen_at"`
+	TreasureRegenAt time.Time `json:"treasure_regen_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Richness 矿脉充盈度 0-1
+func (r *LocationResources) Richness() float64 {
+	profile, exists := LocationProfiles[r.Location]
+	if !exists || profile.MaxOre == 0 {
+		return 0
+	}
+	return float64(r.Ore) / float64(profile.MaxOre)
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"time"
+)
+
+// WorldEventStatus 世界事件状态
+type WorldEventStatus string
+
+const (
+	WorldEventScheduled WorldEventStatus = "scheduled"
+	WorldEventActive    WorldEventStatus = "active"
+	WorldEventEnded     WorldEventStatus = "ended"
+	WorldEventCancelled WorldEventStatus = "cancelled"
+)
+
+// WorldEventModifiers 世界事件对随机事件和AI的影响，零值表示不影响
+type WorldEventModifiers struct {
+	ExpMultiplier  float64   `json:"exp_multiplier,omitempty"`  // 战斗经验倍率
+	CoinMultiplier float64   `json:"coin_multiplier,omitempty"` // 战斗、发现和奖励金币倍率
+	RareFindBonus  int       `json:"rare_find_bonus,omitempty"` // 稀有发现概率加成（百分点）
+	ExploreBonus   int       `json:"explore_bonus,omitempty"`   // AI 探索优先级加成，地点事件还会吸引宠物前往
+	Monsters       []Monster `json:"monsters,omitempty"`        // 事件期间出没的限定怪物
+	BossTemplate   string    `json:"boss_template,omitempty"`   // 事件开始时召唤的世界首领
+}
+
+// Combine 叠加两组修正：倍率相乘，加成相加，限定怪物合并
+func (m WorldEventModifiers) Combine(other WorldEventModifiers) WorldEventModifiers {
+	combined := WorldEventModifiers{
+		ExpMultiplier:  m.ExpRate() * other.ExpRate(),
+		CoinMultiplier: m.CoinRate() * other.CoinRate(),
+		RareFindBonus:  m.RareFindBonus + other.RareFindBonus,
+		ExploreBonus:   m.ExploreBonus + other.ExploreBonus,
+		BossTemplate:   m.BossTemplate,
+	}
+	combined.Monsters = append(append([]Monster{}, m.Monsters...), other.Monsters...)
+	if combined.BossTemplate == "" {
+		combined.BossTemplate = other.BossTemplate
+	}
+	return combined
+}
+
+// ExpRate 经验倍率，未设置时为1
+func (m WorldEventModifiers) ExpRate() float64 {
+	if m.ExpMultiplier <= 0 {
+		return 1
+	}
+	return m.ExpMultiplier
+}
+
+// CoinRate 金币倍率，未设置时为1
+func (m WorldEventModifiers) CoinRate() float64 {
+	if m.CoinMultiplier <= 0 {
+		return 1
+	}
+	return m.CoinMultiplier
+}
+
+// WorldEvent 一场限时的世界事件，Location 为空时全服生效
+type WorldEvent struct {
+	ID          string              `json:"id"`
+	CalendarID  string              `json:"calendar_id,omitempty"` // 由日历生成时对应的日历条目
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Location    string              `json:"location,omitempty"`
+	Modifiers   WorldEventModifiers `json:"modifiers"`
+	Status      WorldEventStatus    `json:"status"`
+	StartAt     time.Time           `json:"start_at"`
+	EndAt       time.Time           `json:"end_at"`
+	CreatedBy   string              `json:"created_by"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// AppliesTo 事件是否作用于该地点
+func (e *WorldEvent) AppliesTo(location string) bool {
+	return e.Location == "" || e.Location == location
+}
+
+// CalendarEntry 周期性的世界事件定义。Month 为0时每周 Weekday 的 Hour 点开始，
+// 否则每年 Month 月 Day 日的 Hour 点开始
+type CalendarEntry struct {
+	ID          string
+	Name        string
+	Description string
+	Location    string
+	Modifiers   WorldEventModifiers
+	Weekday     time.Weekday
+	Month       time.Month
+	Day         int
+	Hour        int
+	Duration    time.Duration
+}
+
+// Occurrences 返回与 [from, to) 有交集的各次开始时间
+func (c CalendarEntry) Occurrences(from, to time.Time) []time.Time {
+	var starts []time.Time
+	// 从 from 之前一个周期开始找，覆盖已开始但尚未结束的一次
+	day := time.Date(from.Year(), from.Month(), from.Day(), c.Hour, 0, 0, 0, from.Location())
+	day = day.Add(-c.Duration).AddDate(0, 0, -1)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if c.Month == 0 {
+			if day.Weekday() != c.Weekday {
+				continue
+			}
+		} else if day.Month() != c.Month || day.Day() != c.Day {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), c.Hour, 0, 0, 0, day.Location())
+		if start.Add(c.Duration).After(from) && start.Before(to) {
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+// WorldCalendar 世界事件日历
+var WorldCalendar = []CalendarEntry{
+	{
+		ID:          "double_exp_weekend",
+		Name:        "双倍经验周末",
+		Description: "周末期间战斗获得的经验翻倍",
+		Modifiers:   WorldEventModifiers{ExpMultiplier: 2},
+		Weekday:     time.Saturday,
+		Duration:    48 * time.Hour,
+	},
+	{
+		ID:          "cave_treasure_festival",
+		Name:        "神秘洞穴寻宝节",
+		Description: "神秘洞穴的宝藏大量涌现，金币收益提升，更容易找到稀有宝物",
+		Location:    "神秘洞穴",
+		Modifiers:   WorldEventModifiers{CoinMultiplier: 1.5, RareFindBonus: 10, ExploreBonus: 15},
+		Weekday:     time.Wednesday,
+		Hour:        20,
+		Duration:    3 * time.Hour,
+	},
+	{
+		ID:          "halloween",
+		Name:        "万圣夜",
+		Description: "南瓜怪和幽灵在各地游荡",
+		Modifiers: WorldEventModifiers{Monsters: []Monster{
+			{Name: "南瓜怪", Health: 45, Attack: 13, Defense: 4, ExpReward: 30, CoinReward: 20},
+			{Name: "游荡幽灵", Health: 35, Attack: 16, Defense: 2, ExpReward: 35, CoinReward: 25},
+			{Name: "吸血蝙蝠群", Health: 30, Attack: 11, Defense: 3, ExpReward: 25, CoinReward: 15},
+		}},
+		Month:    time.October,
+		Day:      29,
+		Duration: 72 * time.Hour,
+	},
+	{
+		ID:          "winter_festival",
+		Name:        "冬日庆典",
+		Description: "雪怪出没，庆典期间金币收益提升",
+		Modifiers: WorldEventModifiers{CoinMultiplier: 1.2, Monsters: []Monster{
+			{Name: "雪怪", Health: 60, Attack: 15, Defense: 6, ExpReward: 40, CoinReward: 25},
+			{Name: "冰霜精灵", Health: 35, Attack: 14, Defense: 3, ExpReward: 30, CoinReward: 20},
+		}},
+		Month:    time.December,
+		Day:      24,
+		Duration: 72 * time.Hour,
+	},
+	{
+		ID:          "new_year",
+		Name:        "新年讨伐",
+		Description: "暗影巨龙在新年降临，击败它迎接好运",
+		Modifiers:   WorldEventModifiers{ExpMultiplier: 1.5, BossTemplate: "shadow_dragon"},
+		Month:       time.January,
+		Day:         1,
+		Duration:    24 * time.Hour,
+	},
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxQueueLength 每只宠物的行动计划最多排入的步数
+const maxQueueLength = 10
+
+const NotificationActionQueue = "action_queue"
+
+var (
+	// ErrQueueFull 行动计划已满
+	ErrQueueFull = errors.New("行动计划已满")
+	// ErrQueueStepNotFound 计划中没有这一步
+	ErrQueueStepNotFound = errors.New("计划中没有这一步")
+	// ErrQueueStepRunning 正在执行的一步只能通过 cancel 或 recall 中止
+	ErrQueueStepRunning = errors.New("这一步正在执行，请使用 cancel 或 recall 中止")
+)
+
+// queueableCommands 可以排入计划的指令，值为计划文本里参数对应的字段
+var queueableCommands = map[string]string{
+	"explore":   "location",
+	"rest":      "duration",
+	"feed":      "amount",
+	"socialize": "",
+	"craft":     "recipe",
+}
+
+// ActionQueueUpdate 行动计划变化时推送的内容
+type ActionQueueUpdate struct {
+	PetID string                `json:"pet_id"`
+	Queue []models.QueuedAction `json:"queue"`
+}
+
+// newQueuedAction 校验并创建一步计划
+func newQueuedAction(command string, params map[string]interface{}) (models.QueuedAction, error) {
+	if _, ok := queueableCommands[command]; !ok {
+		return models.QueuedAction{}, fmt.Errorf("指令 %s 不能排入计划", command)
+	}
+	if location, ok := params["location"].(string); ok && !isExploreLocation(location) {
+		return models.QueuedAction{}, fmt.Errorf("未知的地点: %s", location)
+	}
+	if recipe, ok := params["recipe"].(string); ok {
+		if _, exists := models.FindRecipe(recipe); !exists {
+			return models.QueuedAction{}, fmt.Errorf("unknown recipe: %s", recipe)
+		}
+	}
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+
+	return models.QueuedAction{
+		ID:         uuid.New().String(),
+		Command:    command,
+		Params:     params,
+		EnqueuedAt: time.Now(),
+	}, nil
+}
+
+// ParseActionPlan 解析形如 "explore 北方森林 → rest → feed 30 → socialize" 的计划文本
+func ParseActionPlan(plan string) ([]models.QueuedAction, error) {
+	plan = strings.ReplaceAll(plan, "->", "→")
+
+	steps := make([]models.QueuedAction, 0)
+	for _, part := range strings.Split(plan, "→") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+
+		command := fields[0]
+		key, ok := queueableCommands[command]
+		if !ok {
+			return nil, fmt.Errorf("指令 %s 不能排入计划", command)
+		}
+
+		params := make(map[string]interface{})
+		if arg := strings.Join(fields[1:], " "); arg != "" {
+			if key == "" {
+				return nil, fmt.Errorf("指令 %s 不需要参数", command)
+			}
+			// 数值参数与 JSON 请求保持一致，使用 float64
+			if key == "duration" || key == "amount" {
+				n, err := strconv.Atoi(arg)
+				if err != nil || n <= 0 {
+					return nil, fmt.Errorf("%s 的参数应为正整数: %s", command, arg)
+				}
+				params[key] = float64(n)
+			} else {
+				params[key] = arg
+			}
+		}
+
+		step, err := newQueuedAction(command, params)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("计划为空")
+	}
+	return steps, nil
+}
+
+// queueChanged 保存行动计划并推送给在线客户端，调用方需持有锁
+func (ps *PetService) queueChanged(pet *models.Pet) {
+	ps.savePetToDatabase(pet)
+	ps.notify(Notification{
+		Type:  NotificationActionQueue,
+		PetID: pet.ID,
+		Owner: pet.Owner,
+		Data:  ActionQueueUpdate{PetID: pet.ID, Queue: append([]models.QueuedAction{}, pet.ActionQueue...)},
+	})
+}
+
+// advanceQueue 宠物空闲时推进行动计划：移除已结束的一步并开始下一步，调用方需持有锁
+func (ps *PetService) advanceQueue(pet *models.Pet) {
+	changed := false
+	for len(pet.ActionQueue) > 0 && pet.IsAlive() && pet.Status == models.StatusIdle {
+		step := &pet.ActionQueue[0]
+		if step.Running() {
+			// 宠物已回到空闲，说明这一步已经结束
+			pet.ActionQueue = pet.ActionQueue[1:]
+			changed = true
+			continue
+		}
+
+		now := time.Now()
+		step.StartedAt = &now
+		changed = true
+		if _, err := ps.dispatchCommand(pet, step.Command, step.Params); err != nil {
+			ps.addEvent(models.Event{
+				ID:        uuid.New().String(),
+				PetID:     pet.ID,
+				PetName:   pet.Name,
+				Type:      models.EventReward,
+				Message:   fmt.Sprintf("[%s] 计划中的 %s 无法执行，已跳过：%v", pet.Name, step.Command, err),
+				Timestamp: now,
+			})
+			pet.ActionQueue = pet.ActionQueue[1:]
+		}
+	}
+
+	if changed {
+		ps.queueChanged(pet)
+	}
+}
+
+// clearQueue 清空行动计划，keepRunning 时保留正在执行的一步，调用方需持有锁
+func (ps *PetService) clearQueue(pet *models.Pet, keepRunning bool) int {
+	kept := pet.ActionQueue[:0:0]
+	if keepRunning && len(pet.ActionQueue) > 0 && pet.ActionQueue[0].Running() {
+		kept = append(kept, pet.ActionQueue[0])
+	}
+	removed := len(pet.ActionQueue) - len(kept)
+	if removed > 0 {
+		pet.ActionQueue = kept
+		ps.queueChanged(pet)
+	}
+	return removed
+}
+
+// enqueueActions 把若干步追加到计划末尾，宠物空闲时立即开始，调用方需持有锁
+func (ps *PetService) enqueueActions(pet *models.Pet, steps []models.QueuedAction) error {
+	if len(pet.ActionQueue)+len(steps) > maxQueueLength {
+		return fmt.Errorf("%w（最多%d步，当前%d步）", ErrQueueFull, maxQueueLength, len(pet.ActionQueue))
+	}
+
+	pet.ActionQueue = append(pet.ActionQueue, steps...)
+	ps.queueChanged(pet)
+	ps.advanceQueue(pet)
+	return nil
+}
+
+// reorderQueue 按给定顺序重排尚未开始的步骤，order 必须恰好包含所有未开始的步骤，调用方需持有锁
+func (ps *PetService) reorderQueue(pet *models.Pet, order []string) error {
+	start := 0
+	if len(pet.ActionQueue) > 0 && pet.ActionQueue[0].Running() {
+		start = 1
+	}
+	pending := make(map[string]models.QueuedAction)
+	for _, step := range pet.ActionQueue[start:] {
+		pending[step.ID] = step
+	}
+	if len(order) != len(pending) {
+		return fmt.Errorf("新顺序应包含全部%d个未开始的步骤", len(pending))
+	}
+
+	reordered := append(make([]models.QueuedAction, 0, len(pet.ActionQueue)), pet.ActionQueue[:start]...)
+	for _, id := range order {
+		step, exists := pending[id]
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrQueueStepNotFound, id)
+		}
+		delete(pending, id)
+		reordered = append(reordered, step)
+	}
+
+	pet.ActionQueue = reordered
+	ps.queueChanged(pet)
+	return nil
+}
+
+// removeQueuedAction 从计划中移除一步尚未开始的行动，调用方需持有锁
+func (ps *PetService) removeQueuedAction(pet *models.Pet, stepID string) error {
+	for i, step := range pet.ActionQueue {
+		if step.ID != stepID {
+			continue
+		}
+		if step.Running() {
+			return ErrQueueStepRunning
+		}
+		pet.ActionQueue = append(pet.ActionQueue[:i:i], pet.ActionQueue[i+1:]...)
+		ps.queueChanged(pet)
+		return nil
+	}
+	return ErrQueueStepNotFound
+}
+
+// queueSteps 从指令参数中读取计划：plan 为计划文本，steps 为 [{command, params}] 列表
+func queueSteps(params map[string]interface{}) ([]models.QueuedAction, error) {
+	if plan, ok := params["plan"].(string); ok && plan != "" {
+		return ParseActionPlan(plan)
+	}
+
+	raw, _ := params["steps"].([]interface{})
+	steps := make([]models.QueuedAction, 0, len(raw))
+	for _, item := range raw {
+		entry, _ := item.(map[string]interface{})
+		command, _ := entry["command"].(string)
+		stepParams, _ := entry["params"].(map[string]interface{})
+		step, err := newQueuedAction(command, stepParams)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func (ps *PetService) executeQueueCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	steps, err := queueSteps(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) > 0 {
+		if err := ps.enqueueActions(pet, steps); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]interface{}{
+		"action":  "queue",
+		"added":   len(steps),
+		"queue":   pet.ActionQueue,
+		"message": fmt.Sprintf("%s 的行动计划共 %d 步", pet.Name, len(pet.ActionQueue)),
+	}, nil
+}
+
+func (ps *PetService) executeQueueReorderCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	raw, _ := params["order"].([]interface{})
+	order := make([]string, 0, len(raw))
+	for _, id := range raw {
+		if s, ok := id.(string); ok {
+			order = append(order, s)
+		}
+	}
+	if err := ps.reorderQueue(pet, order); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":  "queue_reorder",
+		"queue":   pet.ActionQueue,
+		"message": fmt.Sprintf("%s 的行动计划已重新排序", pet.Name),
+	}, nil
+}
+
+func (ps *PetService) executeQueueRemoveCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	stepID, _ := params["id"].(string)
+	if err := ps.removeQueuedAction(pet, stepID); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":  "queue_remove",
+		"queue":   pet.ActionQueue,
+		"message": fmt.Sprintf("已从 %s 的行动计划中移除一步", pet.Name),
+	}, nil
+}
+
+func (ps *PetService) executeQueueClearCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	removed := ps.clearQueue(pet, true)
+
+	return map[string]interface{}{
+		"action":  "queue_clear",
+		"removed": removed,
+		"queue":   pet.ActionQueue,
+		"message": fmt.Sprintf("已清空 %s 的行动计划（%d 步）", pet.Name, removed),
+	}, nil
+}
+
+// GetActionQueue 获取宠物的行动计划
+func (ps *PetService) GetActionQueue(petID string) ([]models.QueuedAction, error) {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+	return append([]models.QueuedAction{}, pet.ActionQueue...), nil
+}
+
+// EnqueueActions 追加计划步骤，plan 为计划文本，与 steps 二选一
+func (ps *PetService) EnqueueActions(petID, plan string, steps []models.QueuedAction) ([]models.QueuedAction, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+
+	if plan != "" {
+		parsed, err := ParseActionPlan(plan)
+		if err != nil {
+			return nil, err
+		}
+		steps = parsed
+	} else {
+		for i, step := range steps {
+			validated, err := newQueuedAction(step.Command, step.Params)
+			if err != nil {
+				return nil, err
+			}
+			steps[i] = validated
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("计划为空")
+	}
+
+	if err := ps.enqueueActions(pet, steps); err != nil {
+		return nil, err
+	}
+	return append([]models.QueuedAction{}, pet.ActionQueue...), nil
+}
+
+// ReorderActionQueue 重排尚未开始的计划步骤
+func (ps *PetService) ReorderActionQueue(petID string, order []string) ([]models.QueuedAction, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+	if err := ps.reorderQueue(pet, order); err != nil {
+		return nil, err
+	}
+	return append([]models.QueuedAction{}, pet.ActionQueue...), nil
+}
+
+// RemoveQueuedAction 移除一步尚未开始的计划
+func (ps *PetService) RemoveQueuedAction(petID, stepID string) ([]models.QueuedAction, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+	if err := ps.removeQueuedAction(pet, stepID); err != nil {
+		return nil, err
+	}
+	return append([]models.QueuedAction{}, pet.ActionQueue...), nil
+}
+
+// ClearActionQueue 清空尚未开始的计划步骤，正在执行的一步不受影响
+func (ps *PetService) ClearActionQueue(petID string) ([]models.QueuedAction, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+	ps.clearQueue(pet, true)
+	return append([]models.QueuedAction{}, pet.ActionQueue...), nil
+}
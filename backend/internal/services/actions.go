@@ -19,12 +19,19 @@ func (ps *PetService) executeAction(pet *models.Pet, action Action) {
 		ps.executeSocializeAction(pet, action)
 	case ActionEat:
 		ps.executeEatAction(pet, action)
+	case ActionBank:
+		ps.executeBankAction(pet, action)
+	case ActionCraft:
+		ps.executeCraftAction(pet, action)
 	case ActionIdle:
 	}
 }
 
 func (ps *PetService) executeExploreAction(pet *models.Pet, action Action) {
-	pet.Status = models.StatusExploring
+	if location, ok := action.Params["location"].(string); ok && location != "" {
+		pet.Location = location
+		ps.stateManager.UpdateLocation(pet.ID, location)
+	}
 	
 	event := models.Event{
 		ID:        uuid.New().String(),
@@ -37,19 +44,33 @@ func (ps *PetService) executeExploreAction(pet *models.Pet, action Action) {
 	}
 	ps.addEvent(event)
 
-	go func() {
-		time.Sleep(time.Duration(action.Duration) * time.Second)
-		ps.mutex.Lock()
-		if pet.Status == models.StatusExploring {
-			ps.processExploreResult(pet)
+	// 记下出发时的地点，探索结果记在这里，而不是完成时宠物所在的地方
+	params := map[string]interface{}{"location": pet.Location}
+	for key, value := range action.Params {
+		if key != "location" {
+			params[key] = value
 		}
-		ps.mutex.Unlock()
-	}()
+	}
+	ps.beginAction(pet, ActionExplore, models.StatusExploring, action.Reason, params, action.Duration)
+}
+
+func (ps *PetService) completeExploreAction(pet *models.Pet, action *models.PetAction) {
+	ps.processExploreResult(pet)
+}
+
+// cancelExploreAction 探索过半才会带回部分收获
+func (ps *PetService) cancelExploreAction(pet *models.Pet, action *models.PetAction, progress float64) string {
+	if progress < 0.5 {
+		return "一无所获"
+	}
+	coins := int(progress * float64(5+rand.Intn(20)))
+	exp := int(progress * 10)
+	ps.creditCoins(pet, coins, models.AccountMint, models.ReasonDiscovery, "探索中途返回")
+	pet.GainExperience(exp)
+	return fmt.Sprintf("带回了%d金币，经验+%d", coins, exp)
 }
 
 func (ps *PetService) executeRestAction(pet *models.Pet, action Action) {
-	pet.Status = models.StatusResting
-	
 	event := models.Event{
 		ID:        uuid.New().String(),
 		PetID:     pet.ID,
@@ -60,46 +81,44 @@ func (ps *PetService) executeRestAction(pet *models.Pet, action Action) {
 	}
 	ps.addEvent(event)
 
-	go func() {
-		time.Sleep(time.Duration(action.Duration) * time.Second)
-		ps.mutex.Lock()
-		if pet.Status == models.StatusResting {
-			restoreAmount := 20 + rand.Intn(20)
-			pet.RestoreEnergy(restoreAmount)
-			pet.Heal(10)
-			pet.Status = models.StatusIdle
-			
-			ps.addEvent(models.Event{
-				ID:        uuid.New().String(),
-				PetID:     pet.ID,
-				PetName:   pet.Name,
-				Type:      models.EventReward,
-				Message:   fmt.Sprintf("[%s] 休息完毕，恢复了%d点体力", pet.Name, restoreAmount),
-				Timestamp: time.Now(),
-			})
-			
-			ps.savePetToDatabase(pet)
-		}
-		ps.mutex.Unlock()
-	}()
+	ps.beginAction(pet, ActionRest, models.StatusResting, action.Reason, action.Params, action.Duration)
 }
 
-func (ps *PetService) executeSocializeAction(pet *models.Pet, action Action) {
-	pet.Status = models.StatusSocializing
+func (ps *PetService) completeRestAction(pet *models.Pet, action *models.PetAction) {
+	restoreAmount := 20 + rand.Intn(20)
+	pet.RestoreEnergy(restoreAmount)
+	pet.Heal(10)
+	pet.Status = models.StatusIdle
 	
-	var socialPartner *models.Pet
-	for _, otherPet := range ps.pets {
-		if otherPet.ID != pet.ID && otherPet.IsAlive() {
-			socialPartner = otherPet
-			break
-		}
-	}
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventReward,
+		Message:   ps.narrate(pet, Narrative{Kind: NarrateRestDone, Energy: restoreAmount}),
+		Timestamp: time.Now(),
+	})
+}
+
+func (ps *PetService) cancelRestAction(pet *models.Pet, action *models.PetAction, progress float64) string {
+	restoreAmount := int(progress * float64(20+rand.Intn(20)))
+	healAmount := int(progress * 10)
+	pet.RestoreEnergy(restoreAmount)
+	pet.Heal(healAmount)
+	return fmt.Sprintf("恢复了%d点体力、%d点生命", restoreAmount, healAmount)
+}
+
+func (ps *PetService) executeSocializeAction(pet *models.Pet, action Action) {
+	socialPartner := ps.choosePartner(pet)
 	
 	var message string
+	params := action.Params
 	if socialPartner != nil {
-		message = fmt.Sprintf("[%s] 与 %s 愉快地交流", pet.Name, socialPartner.Name)
+		message = ps.narrate(pet, Narrative{Kind: NarrateChat, Friend: socialPartner.Name})
 		pet.AddFriend(socialPartner.Owner)
 		socialPartner.AddFriend(pet.Owner)
+		// 记下社交对象，结束时双方都会留下回忆
+		params = map[string]interface{}{"partner": socialPartner.ID}
 	} else {
 		message = fmt.Sprintf("[%s] %s", pet.Name, action.Reason)
 	}
@@ -114,111 +133,229 @@ func (ps *PetService) executeSocializeAction(pet *models.Pet, action Action) {
 	}
 	ps.addEvent(event)
 
-	go func() {
-		time.Sleep(time.Duration(action.Duration) * time.Second)
-		ps.mutex.Lock()
-		if pet.Status == models.StatusSocializing {
-			socialGain := 15 + rand.Intn(20)
-			pet.IncreaseSocial(socialGain)
-			pet.Status = models.StatusIdle
-			
-			ps.addEvent(models.Event{
-				ID:        uuid.New().String(),
-				PetID:     pet.ID,
-				PetName:   pet.Name,
-				Type:      models.EventSocial,
-				Message:   fmt.Sprintf("[%s] 社交结束，心情变好了", pet.Name),
-				Timestamp: time.Now(),
-			})
-			
-			ps.savePetToDatabase(pet)
-		}
-		ps.mutex.Unlock()
-	}()
+	ps.beginAction(pet, ActionSocialize, models.StatusSocializing, action.Reason, params, action.Duration)
+}
+
+func (ps *PetService) completeSocializeAction(pet *models.Pet, action *models.PetAction) {
+	socialGain := 15 + rand.Intn(20)
+	_, lonely := feltEmotion(pet, models.EmotionLoneliness)
+	pet.IncreaseSocial(socialGain)
+	pet.Soothe(models.EmotionLoneliness, 0.8, time.Now())
+	pet.Status = models.StatusIdle
+	
+	partnerID, _ := action.Params["partner"].(string)
+	if partner, exists := ps.pets[partnerID]; exists {
+		ps.remember(pet, models.Memory{
+			Kind:        models.MemoryMeeting,
+			Summary:     fmt.Sprintf("和 %s 度过了愉快的时光", partner.Name),
+			Location:    pet.Location,
+			PartnerID:   partner.ID,
+			PartnerName: partner.Name,
+			Valence:     20 + socialGain,
+			Importance:  25,
+		})
+		ps.remember(partner, models.Memory{
+			Kind:        models.MemoryMeeting,
+			Summary:     fmt.Sprintf("%s 来找自己玩", pet.Name),
+			Location:    partner.Location,
+			PartnerID:   pet.ID,
+			PartnerName: pet.Name,
+			Valence:     30,
+			Importance:  20,
+		})
+		pet.Feel(models.EmotionJoy, 0.3, fmt.Sprintf("和 %s 玩得很开心", partner.Name), time.Now())
+		partner.Feel(models.EmotionJoy, 0.2, fmt.Sprintf("%s 来找自己玩", pet.Name), time.Now())
+		partner.Soothe(models.EmotionLoneliness, 0.5, time.Now())
+		ps.savePetToDatabase(partner)
+	}
+	ps.driftTrait(pet, models.TraitSociability, traitDrift)
+	
+	kind := NarrateSocialDone
+	if lonely {
+		kind = NarrateLonelinessEased
+	}
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventSocial,
+		Message:   ps.narrate(pet, Narrative{Kind: kind}),
+		Timestamp: time.Now(),
+	})
+}
+
+func (ps *PetService) cancelSocializeAction(pet *models.Pet, action *models.PetAction, progress float64) string {
+	socialGain := int(progress * float64(15+rand.Intn(20)))
+	pet.IncreaseSocial(socialGain)
+	return fmt.Sprintf("社交度+%d", socialGain)
 }
 
 func (ps *PetService) executeEatAction(pet *models.Pet, action Action) {
-	if pet.Coins < 10 {
-		pet.Status = "寻找食物"
-		
+	// 背包里有能填饱肚子的消耗品时直接吃掉
+	if food := pet.FindConsumable("hunger"); food != nil {
+		item, _ := pet.UseItem(food.ID)
+		ps.addEvent(models.Event{
+			ID:        uuid.New().String(),
+			PetID:     pet.ID,
+			PetName:   pet.Name,
+			Type:      models.EventReward,
+			Message:   ps.narrate(pet, Narrative{Kind: NarrateEatItem, Item: item.Name, Hunger: item.Effect.Hunger}),
+			Timestamp: time.Now(),
+		})
+		ps.savePetToDatabase(pet)
+		return
+	}
+	
+	// 没钱或超出主人设定的花费上限时去找免费的食物
+	if pet.Coins < 10 || !pet.Policy.AllowsSpending(10) {
 		event := models.Event{
 			ID:        uuid.New().String(),
 			PetID:     pet.ID,
 			PetName:   pet.Name,
 			Type:      models.EventReward,
-			Message:   fmt.Sprintf("[%s] 寻找免费的食物...", pet.Name),
+			Message:   ps.narrate(pet, Narrative{Kind: NarrateForage}),
 			Timestamp: time.Now(),
 		}
 		ps.addEvent(event)
 		
-		go func() {
-			time.Sleep(time.Duration(action.Duration) * time.Second)
-			ps.mutex.Lock()
-			if pet.Status == "寻找食物" {
-				feedAmount := 10 + rand.Intn(15)
-				pet.Feed(feedAmount)
-				pet.Status = models.StatusIdle
-				
-				foundEvent := models.Event{
-					ID:        uuid.New().String(),
-					PetID:     pet.ID,
-					PetName:   pet.Name,
-					Type:      models.EventReward,
-					Message:   fmt.Sprintf("[%s] 找到了一些免费食物，饱食度+%d", pet.Name, feedAmount),
-					Timestamp: time.Now(),
-				}
-				ps.addEvent(foundEvent)
-				
-				ps.savePetToDatabase(pet)
-			}
-			ps.mutex.Unlock()
-		}()
+		params := map[string]interface{}{"op": "forage"}
+		ps.beginAction(pet, ActionEat, models.StatusForaging, action.Reason, params, action.Duration)
 	} else {
 		cost := 10 + rand.Intn(10)
-		if pet.Coins >= cost {
-			pet.Status = "进食中"
-			pet.Coins -= cost
+		if pet.Coins >= cost && pet.Policy.AllowsSpending(cost) {
+			ps.debitCoins(pet, cost, models.AccountShop, models.ReasonFood, "购买食物")
 			
 			event := models.Event{
 				ID:        uuid.New().String(),
 				PetID:     pet.ID,
 				PetName:   pet.Name,
 				Type:      models.EventReward,
-				Message:   fmt.Sprintf("[%s] 购买食物中...", pet.Name),
+				Message:   ps.narrate(pet, Narrative{Kind: NarrateBuyFood}),
 				Timestamp: time.Now(),
 			}
 			ps.addEvent(event)
 			
-			go func() {
-				time.Sleep(time.Duration(action.Duration) * time.Second)
-				ps.mutex.Lock()
-				if pet.Status == "进食中" {
-					feedAmount := 25 + rand.Intn(20)
-					pet.Feed(feedAmount)
-					pet.Status = models.StatusIdle
-					
-					finishEvent := models.Event{
-						ID:        uuid.New().String(),
-						PetID:     pet.ID,
-						PetName:   pet.Name,
-						Type:      models.EventReward,
-						Message:   fmt.Sprintf("[%s] 花费%d金币买了美味的食物，饱食度+%d", pet.Name, cost, feedAmount),
-						Timestamp: time.Now(),
-						Data:      models.EventData{Coins: -cost},
-					}
-					ps.addEvent(finishEvent)
-					
-					ps.savePetToDatabase(pet)
-				}
-				ps.mutex.Unlock()
-			}()
+			params := map[string]interface{}{"op": "buy", "cost": cost}
+			ps.beginAction(pet, ActionEat, models.StatusEating, action.Reason, params, action.Duration)
 		}
 	}
 }
 
+func (ps *PetService) completeEatAction(pet *models.Pet, action *models.PetAction) {
+	pet.Status = models.StatusIdle
+
+	if op, _ := action.Params["op"].(string); op == "forage" {
+		feedAmount := 10 + rand.Intn(15)
+		pet.Feed(feedAmount)
+		
+		ps.addEvent(models.Event{
+			ID:        uuid.New().String(),
+			PetID:     pet.ID,
+			PetName:   pet.Name,
+			Type:      models.EventReward,
+			Message:   ps.narrate(pet, Narrative{Kind: NarrateForaged, Hunger: feedAmount}),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	cost := paramInt(action.Params, "cost")
+	feedAmount := 25 + rand.Intn(20)
+	pet.Feed(feedAmount)
+	
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventReward,
+		Message:   ps.narrate(pet, Narrative{Kind: NarrateMeal, Coins: cost, Hunger: feedAmount}),
+		Timestamp: time.Now(),
+		Data:      models.EventData{Coins: -cost},
+	})
+}
+
+// cancelEatAction 只吃到一部分，已买的食物不退钱
+func (ps *PetService) cancelEatAction(pet *models.Pet, action *models.PetAction, progress float64) string {
+	if op, _ := action.Params["op"].(string); op == "forage" {
+		feedAmount := int(progress * float64(10+rand.Intn(15)))
+		pet.Feed(feedAmount)
+		return fmt.Sprintf("饱食度+%d", feedAmount)
+	}
+
+	feedAmount := int(progress * float64(25+rand.Intn(20)))
+	pet.Feed(feedAmount)
+	return fmt.Sprintf("饱食度+%d，剩下的食物浪费了", feedAmount)
+}
+
+func (ps *PetService) executeBankAction(pet *models.Pet, action Action) {
+	pet.Location = bankLocation
+	
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventTransfer,
+		Message:   fmt.Sprintf("[%s] %s，前往%s银行", pet.Name, action.Reason, bankLocation),
+		Timestamp: time.Now(),
+		Data:      models.EventData{Location: bankLocation},
+	})
+
+	ps.beginAction(pet, ActionBank, models.StatusBanking, action.Reason, action.Params, action.Duration)
+}
+
+func (ps *PetService) completeBankAction(pet *models.Pet, action *models.PetAction) {
+	pet.Status = models.StatusIdle
+	
+	op, _ := action.Params["op"].(string)
+	amount := commandAmount(action.Params)
+	if _, exists := action.Params["keep"]; exists {
+		amount = pet.Coins - paramInt(action.Params, "keep")
+	}
+	item, _ := action.Params["item"].(string)
+	
+	var err error
+	switch op {
+	case "deposit":
+		if amount <= 0 {
+			err = fmt.Errorf("手头已经没有多余的金币可存")
+		} else {
+			_, err = ps.bankDeposit(pet, amount)
+		}
+	case "withdraw":
+		_, err = ps.bankWithdraw(pet, amount)
+	case "repay":
+		_, _, err = ps.repayLoan(pet, amount, models.ReasonLoanRepayment)
+	case "borrow":
+		if _, err = ps.borrow(pet, amount); err == nil && item != "" {
+			_, err = ps.buyGear(pet, item)
+		}
+	case "buy":
+		_, err = ps.buyGear(pet, item)
+	}
+	
+	if err != nil {
+		ps.addEvent(models.Event{
+			ID:        uuid.New().String(),
+			PetID:     pet.ID,
+			PetName:   pet.Name,
+			Type:      models.EventTransfer,
+			Message:   fmt.Sprintf("[%s] 在银行没能办成业务：%v", pet.Name, err),
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+func (ps *PetService) cancelBankAction(pet *models.Pet, action *models.PetAction, progress float64) string {
+	return "银行业务没有办理"
+}
+
 func (ps *PetService) processExploreResult(pet *models.Pet) {
+	levelBefore := pet.Level
 	event := ps.generateRandomEvent(pet)
 	ps.addEvent(event)
+	ps.rememberExplore(pet, event, levelBefore)
+	ps.driftFromExplore(pet, event)
+	ps.feelExplore(pet, event, levelBefore)
 	pet.Status = models.StatusIdle
 	pet.LastActivity = time.Now()
 	
@@ -12,11 +12,20 @@ func (ps *PetService) runGlobalAI() {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ps.quit:
+			return
+		case <-ticker.C:
+		}
 		ps.mutex.Lock()
 		for _, pet := range ps.pets {
 			if pet.IsAlive() {
 				ps.updatePetAttributes(pet)
+				ps.checkEmergencyRecall(pet)
+				// 模拟已推进到此刻，随宠物下一次保存一起落库。重启时从已落库的时刻补算，
+				// 保存之后的衰减没有落库，补算它们不会重复
+				pet.SimulatedAt = time.Now()
 			}
 		}
 		ps.mutex.Unlock()
@@ -31,9 +40,14 @@ func (ps *PetService) startPetAI(pet *models.Pet) {
 	ticker := time.NewTicker(15 * time.Second)
 	ps.activePets[pet.ID] = ticker
 
-	go func() {
+	ps.spawn(func() {
 		defer ticker.Stop()
-		for range ticker.C {
+		for {
+			select {
+			case <-ps.quit:
+				return
+			case <-ticker.C:
+			}
 			ps.mutex.Lock()
 			currentPet, exists := ps.pets[pet.ID]
 			if !exists || !currentPet.IsAlive() {
@@ -47,11 +61,77 @@ func (ps *PetService) startPetAI(pet *models.Pet) {
 				continue
 			}
 
+			if ps.checkEmergencyRecall(currentPet) {
+				ps.mutex.Unlock()
+				continue
+			}
+
+			// 探索等行动进行中时等它完成再做决策，紧急召回仍然照常检查
+			if ps.currentAction(currentPet, time.Now()) != nil {
+				ps.mutex.Unlock()
+				continue
+			}
+
+			// 主人的自动化规则先于计划和 AI 决策，触发后本轮不再做别的事
+			if ps.applyRules(currentPet) {
+				ps.mutex.Unlock()
+				continue
+			}
+
+			// 主人安排了计划时 AI 不做决策
+			if len(currentPet.ActionQueue) > 0 {
+				ps.advanceQueue(currentPet)
+				ps.mutex.Unlock()
+				continue
+			}
+
+			// 有目标时按计划一步步行动
+			if ps.pursueGoal(currentPet) {
+				ps.mutex.Unlock()
+				continue
+			}
+
 			action := ps.aiEngine.DecideNextAction(currentPet)
 			ps.executeAction(currentPet, action)
 			ps.mutex.Unlock()
 		}
-	}()
+	})
+}
+
+// checkEmergencyRecall 探索途中伤势过重或精疲力竭时自动逃回起始村庄，返回是否触发
+func (ps *PetService) checkEmergencyRecall(pet *models.Pet) bool {
+	if pet.Status != models.StatusExploring || !pet.IsAlive() {
+		return false
+	}
+	// 逃跑的生命线由主人设定的冒险倾向决定，默认为25%
+	wounded := pet.Health*100 < pet.MaxHealth*pet.Policy.FleeHealthPercent()
+	if !wounded && pet.Energy > 5 {
+		return false
+	}
+
+	cause := "伤势过重"
+	if !wounded {
+		cause = "精疲力竭"
+	}
+	// 逃跑是最差的结果，宠物会记住这个地方
+	location := pet.Location
+	if _, _, err := ps.interruptAction(pet, cause+"，紧急逃回"+bankLocation, true); err != nil {
+		return false
+	}
+	learn(pet, ActionExplore, location, fleeReward, time.Now())
+	ps.remember(pet, models.Memory{
+		Kind:       models.MemoryFled,
+		Summary:    fmt.Sprintf("在%s%s，狼狈地逃了回来", location, cause),
+		Location:   location,
+		Valence:    -80,
+		Importance: 85,
+	})
+	pet.Feel(models.EmotionFear, 0.9, fmt.Sprintf("在%s%s", location, cause), time.Now())
+	if wounded {
+		ps.driftTrait(pet, models.TraitCaution, nearDeathDrift)
+	}
+	ps.savePetToDatabase(pet)
+	return true
 }
 
 func (ps *PetService) updatePetAttributes(pet *models.Pet) {
@@ -59,6 +139,8 @@ func (ps *PetService) updatePetAttributes(pet *models.Pet) {
 		energyLoss := 2
 		if pet.Status == models.StatusExploring || pet.Status == models.StatusFighting {
 			energyLoss = 5
+			// 夜里和恶劣天气在野外行动更累
+			energyLoss += conditionsAt(pet.Location, time.Now()).EnergyDrain()
 		}
 		pet.ConsumeEnergy(energyLoss)
 	}
@@ -74,6 +156,7 @@ func (ps *PetService) updatePetAttributes(pet *models.Pet) {
 	if pet.Social > 0 && pet.Status != models.StatusSocializing {
 		pet.DecreaseSocial(1)
 	}
+	feelIsolation(pet)
 
 	if pet.Hunger < 20 && pet.Health > 0 {
 		pet.TakeDamage(5)
@@ -82,7 +165,7 @@ func (ps *PetService) updatePetAttributes(pet *models.Pet) {
 			PetID:     pet.ID,
 			PetName:   pet.Name,
 			Type:      models.EventReward,
-			Message:   fmt.Sprintf("[%s] 因为饥饿失去了5点生命值", pet.Name),
+			Message:   ps.narrate(pet, Narrative{Kind: NarrateStarving, Damage: 5}),
 			Timestamp: time.Now(),
 			Data:      models.EventData{Damage: 5},
 		})
@@ -120,15 +203,19 @@ func (ps *PetService) StartExploration(petID string) error {
 	}
 	ps.addEvent(event)
 	
-	go func() {
-		time.Sleep(2 * time.Second)
+	ps.spawn(func() {
+		select {
+		case <-ps.quit:
+			return
+		case <-time.After(2 * time.Second):
+		}
 		ps.mutex.Lock()
 		if currentPet, exists := ps.pets[pet.ID]; exists && currentPet.Status == models.StatusExploring {
 			action := ps.aiEngine.DecideNextAction(currentPet)
 			ps.executeAction(currentPet, action)
 		}
 		ps.mutex.Unlock()
-	}()
+	})
 	
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"miningpet/internal/models"
+)
+
+// BTNode 行为树节点，Tick 返回选中的行为和是否成功
+type BTNode interface {
+	Tick(ctx *DecisionContext) (Action, bool)
+}
+
+// BTSelector 依次执行子节点，返回第一个成功的结果
+type BTSelector []BTNode
+
+func (s BTSelector) Tick(ctx *DecisionContext) (Action, bool) {
+	for _, child := range s {
+		if action, ok := child.Tick(ctx); ok {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// BTCondition 条件满足时才执行子节点
+type BTCondition struct {
+	Check func(pet *models.Pet) bool
+	Child BTNode
+}
+
+func (c BTCondition) Tick(ctx *DecisionContext) (Action, bool) {
+	if !c.Check(ctx.Pet) {
+		return Action{}, false
+	}
+	return c.Child.Tick(ctx)
+}
+
+// BTDo 叶子节点：执行指定类型的候选行为，没有该候选时失败
+type BTDo ActionType
+
+func (d BTDo) Tick(ctx *DecisionContext) (Action, bool) {
+	return ctx.candidate(ActionType(d))
+}
+
+// BTWeighted 叶子节点：在所有候选中加权随机选择，总是成功
+type BTWeighted struct{}
+
+func (BTWeighted) Tick(ctx *DecisionContext) (Action, bool) {
+	return weightedChoice(ctx.Candidates, ctx.Rand), true
+}
+
+// BTUrgent 叶子节点：只有候选行为的优先级达到阈值时才执行
+type BTUrgent struct {
+	Type        ActionType
+	MinPriority int
+}
+
+func (u BTUrgent) Tick(ctx *DecisionContext) (Action, bool) {
+	action, ok := ctx.candidate(u.Type)
+	if !ok || action.Priority < u.MinPriority {
+		return Action{}, false
+	}
+	return action, true
+}
+
+// BTPreferred 叶子节点：偏好因素合计达到阈值的候选中选偏好最强的，
+// 让主人策略、经验和情绪等足够强烈的倾向越过固定顺序
+type BTPreferred struct {
+	MinPreference int
+}
+
+func (p BTPreferred) Tick(ctx *DecisionContext) (Action, bool) {
+	var best Action
+	bestPreference := p.MinPreference - 1
+	for _, action := range ctx.Candidates {
+		if value := preference(action); value > bestPreference {
+			best, bestPreference = action, value
+		}
+	}
+	return best, bestPreference >= p.MinPreference
+}
+
+// BehaviorTreeStrategy 行为树：按固定的优先顺序检查需求，先满足最紧迫的需求
+type BehaviorTreeStrategy struct {
+	StrategyName string
+	Root         BTNode
+}
+
+// NewBehaviorTreeStrategy 默认的行为树：饥饿 > 伤病疲劳 > 强烈的偏好 > 急迫的银行业务 > 孤独 > 制作 > 探索
+func NewBehaviorTreeStrategy() *BehaviorTreeStrategy {
+	return &BehaviorTreeStrategy{
+		StrategyName: "behavior_tree",
+		Root: BTSelector{
+			BTCondition{Check: func(p *models.Pet) bool { return p.Hunger < 30 }, Child: BTDo(ActionEat)},
+			BTCondition{Check: func(p *models.Pet) bool { return p.Health*5 < p.MaxHealth*2 || p.Energy*100 < p.MaxEnergy*p.Policy.RestThreshold }, Child: BTDo(ActionRest)},
+			BTPreferred{MinPreference: 40},
+			BTUrgent{Type: ActionBank, MinPriority: 50},
+			BTCondition{Check: func(p *models.Pet) bool { return p.Social < 30 }, Child: BTDo(ActionSocialize)},
+			BTDo(ActionCraft),
+			BTCondition{Check: func(p *models.Pet) bool { return p.Energy*2 >= p.MaxEnergy && p.Hunger >= 40 }, Child: BTDo(ActionExplore)},
+			BTWeighted{},
+		},
+	}
+}
+
+func (s *BehaviorTreeStrategy) Name() string { return s.StrategyName }
+
+func (s *BehaviorTreeStrategy) Description() string {
+	return "按行为树的固定顺序满足最紧迫的需求"
+}
+
+func (s *BehaviorTreeStrategy) Decide(ctx *DecisionContext) Action {
+	if action, ok := s.Root.Tick(ctx); ok {
+		return action
+	}
+	return weightedChoice(ctx.Candidates, ctx.Rand)
+}
@@ -18,6 +18,8 @@ const (
 	ActionFight     ActionType = "fight"
 	ActionEat       ActionType = "eat"
 	ActionIdle      ActionType = "idle"
+	ActionBank      ActionType = "bank"
+	ActionCraft     ActionType = "craft"
 )
 
 // Action 表示宠物的一个行为
@@ -26,21 +28,41 @@ type Action struct {
 	Priority int        `json:"priority"`
 	Reason   string     `json:"reason"`
 	Duration int        `json:"duration"` // 秒
+	// Params 行为参数，如银行业务的操作和金额
+	Params map[string]interface{} `json:"params,omitempty"`
+	// Factors 优先级的构成，用于解释 AI 的决策
+	Factors []PriorityFactor `json:"factors,omitempty"`
 }
 
 // AIEngine AI决策引擎
 type AIEngine struct {
 	rand *rand.Rand
+	// bank 用于查询宠物的存款和贷款，与宠物数据共用 PetService 的主锁
+	bank *BankService
+	// world 与 population 用于挑选探索地点
+	world      *WorldService
+	population func(location string) int
+	// boss 用于把宠物引向世界首领所在地点
+	boss *BossService
+	// events 进行中的世界事件会影响探索意愿
+	events *WorldEventService
+	// decisions 每只宠物最近的决策记录
+	decisions map[string][]DecisionRecord
+	// narrator 叙述行为理由，评估候选行为很频繁，只使用本地模板
+	narrator *TemplateNarrator
 }
 
 // NewAIEngine 创建新的AI引擎
 func NewAIEngine() *AIEngine {
-	return &AIEngine{
-		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	ai := &AIEngine{
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		decisions: make(map[string][]DecisionRecord),
 	}
+	ai.narrator = &TemplateNarrator{intn: ai.rand.Intn}
+	return ai
 }
 
-// DecideNextAction 基于宠物当前状态决定下一个行为
+// DecideNextAction 基于宠物当前状态，由宠物选择的策略决定下一个行为
 func (ai *AIEngine) DecideNextAction(pet *models.Pet) Action {
 	actions := ai.evaluateAllActions(pet)
 	
@@ -48,8 +70,10 @@ func (ai *AIEngine) DecideNextAction(pet *models.Pet) Action {
 		return Action{Type: ActionIdle, Priority: 1, Reason: "无可用行为", Duration: 30}
 	}
 	
-	// 根据优先级和随机性选择行为
-	return ai.selectAction(actions, pet)
+	strategy := strategyFor(pet)
+	action := strategy.Decide(&DecisionContext{Pet: pet, Candidates: actions, Rand: ai.rand})
+	ai.recordDecision(pet, strategy.Name(), actions, action)
+	return action
 }
 
 // evaluateAllActions 评估所有可能的行为
@@ -58,158 +82,227 @@ func (ai *AIEngine) evaluateAllActions(pet *models.Pet) []Action {
 	
 	// 评估探索行为
 	if pet.CanExplore() {
-		priority := ai.calculateExplorePriority(pet)
+		var factors priorityFactors
+		priority := ai.calculateExplorePriority(pet, &factors)
+		reason := ai.getExploreReason(pet)
+		if ai.events != nil {
+			priority += factors.add(factorWorldEvent, ai.events.globalModifiers().ExploreBonus)
+		}
+		var params map[string]interface{}
+		if ai.world != nil {
+			location, score := ai.chooseLocation(pet)
+			if location != "" {
+				// 资源丰富且不拥挤的地方更有吸引力
+				priority += factors.add(factorLocation, int((score-0.5)*30))
+				params = map[string]interface{}{"location": location}
+				if location != pet.Location {
+					reason = fmt.Sprintf("%s，前往%s", reason, location)
+				}
+			}
+		}
 		if priority > 0 {
 			actions = append(actions, Action{
 				Type:     ActionExplore,
 				Priority: priority,
-				Reason:   ai.getExploreReason(pet),
+				Reason:   reason,
 				Duration: ai.rand.Intn(60) + 30, // 30-90秒
+				Params:   params,
+				Factors:  factors,
 			})
 		}
 	}
 	
 	// 评估休息行为
 	if pet.CanRest() {
-		priority := ai.calculateRestPriority(pet)
+		var factors priorityFactors
+		priority := ai.calculateRestPriority(pet, &factors)
 		if priority > 0 {
 			actions = append(actions, Action{
 				Type:     ActionRest,
 				Priority: priority,
 				Reason:   ai.getRestReason(pet),
 				Duration: ai.rand.Intn(30) + 20, // 20-50秒
+				Factors:  factors,
 			})
 		}
 	}
 	
 	// 评估社交行为
 	if pet.CanSocialize() {
-		priority := ai.calculateSocializePriority(pet)
+		var factors priorityFactors
+		priority := ai.calculateSocializePriority(pet, &factors)
 		if priority > 0 {
 			actions = append(actions, Action{
 				Type:     ActionSocialize,
 				Priority: priority,
 				Reason:   ai.getSocializeReason(pet),
 				Duration: ai.rand.Intn(40) + 25, // 25-65秒
+				Factors:  factors,
 			})
 		}
 	}
 	
+	// 评估银行业务
+	if pet.CanRest() && ai.bank != nil {
+		if action, ok := ai.evaluateBankAction(pet); ok {
+			actions = append(actions, action)
+		}
+	}
+	
+	// 评估制作行为
+	if pet.CanRest() {
+		if action, ok := ai.evaluateCraftAction(pet); ok {
+			actions = append(actions, action)
+		}
+	}
+	
 	// 评估进食行为
-	priority := ai.calculateEatPriority(pet)
+	var eatFactors priorityFactors
+	priority := ai.calculateEatPriority(pet, &eatFactors)
 	if priority > 0 {
 		actions = append(actions, Action{
 			Type:     ActionEat,
 			Priority: priority,
 			Reason:   ai.getEatReason(pet),
 			Duration: ai.rand.Intn(20) + 10, // 10-30秒
+			Factors:  eatFactors,
 		})
 	}
 	
-	return actions
+	// 宠物从自身经历中学到的偏好
+	return applyLearnedWeights(pet, actions, time.Now())
 }
 
 // 计算探索行为优先级
-func (ai *AIEngine) calculateExplorePriority(pet *models.Pet) int {
-	priority := 50 // 基础优先级
+func (ai *AIEngine) calculateExplorePriority(pet *models.Pet, factors *priorityFactors) int {
+	priority := factors.add(factorBase, 50) // 基础优先级
 	
-	// 基于性格调整
-	switch pet.Personality {
-	case models.PersonalityCurious:
-		priority += 30
-	case models.PersonalityBrave:
-		priority += 20
-	case models.PersonalityGreedy:
-		priority += 15
-	case models.PersonalityCautious:
-		priority -= 10
-	}
+	// 基于性格调整：好奇、勇敢和贪婪让宠物想出门，谨慎让宠物想留下
+	priority += factors.add(factorPersonality, pet.Traits.Weigh(map[models.Trait]int{
+		models.TraitCuriosity: 40,
+		models.TraitCourage:   30,
+		models.TraitGreed:     20,
+		models.TraitCaution:   -45,
+	}))
 	
 	// 基于体力调整
 	energyPercent := float64(pet.Energy) / float64(pet.MaxEnergy)
 	if energyPercent < 0.3 {
-		priority -= 40
+		priority += factors.add(factorEnergy, -40)
 	} else if energyPercent > 0.8 {
-		priority += 20
+		priority += factors.add(factorEnergy, 20)
 	}
 	
 	// 基于心情调整
-	priority += int(pet.GetMoodInfluence() * 10)
+	priority += factors.add(factorMood, int(pet.GetMoodInfluence()*10))
+	
+	// 基于情绪调整
+	priority += factors.add(factorEmotion, emotionAdjust(pet, ActionExplore, time.Now()))
 	
 	// 基于饱食度调整
 	if pet.Hunger < 40 {
-		priority -= 30
+		priority += factors.add(factorHunger, -30)
 	}
 	
+	// 基于主人设定的冒险倾向调整，受伤时保守的宠物更不愿出门
+	policyAdjust := (pet.Policy.RiskTolerance - 50) / 2
+	if pet.Health*2 < pet.MaxHealth {
+		policyAdjust -= (100 - pet.Policy.RiskTolerance) / 4
+	}
+	priority += factors.add(factorPolicy, policyAdjust)
+	
+	// 基于昼夜和天气调整
+	priority += factors.add(factorConditions, conditionsAt(pet.Location, time.Now()).ExploreModifier(pet.Traits))
+	
 	if priority < 0 {
-		priority = 0
+		priority += factors.add(factorFloor, -priority)
 	}
 	
 	return priority
 }
 
 // 计算休息行为优先级
-func (ai *AIEngine) calculateRestPriority(pet *models.Pet) int {
-	priority := 20 // 基础优先级
+func (ai *AIEngine) calculateRestPriority(pet *models.Pet, factors *priorityFactors) int {
+	priority := factors.add(factorBase, 20) // 基础优先级
 	
 	// 基于体力调整
 	energyPercent := float64(pet.Energy) / float64(pet.MaxEnergy)
 	if energyPercent < 0.3 {
-		priority += 80
+		priority += factors.add(factorEnergy, 80)
 	} else if energyPercent < 0.5 {
-		priority += 40
+		priority += factors.add(factorEnergy, 40)
 	}
 	
 	// 基于健康状况调整
 	healthPercent := float64(pet.Health) / float64(pet.MaxHealth)
 	if healthPercent < 0.5 {
-		priority += 60
+		priority += factors.add(factorHealth, 60)
 	}
 	
 	// 基于心情调整
 	if pet.Mood == models.MoodTired {
-		priority += 50
+		priority += factors.add(factorMood, 50)
 	}
 	
+	// 基于情绪调整
+	priority += factors.add(factorEmotion, emotionAdjust(pet, ActionRest, time.Now()))
+	
 	// 基于性格调整
-	if pet.Personality == models.PersonalityCautious {
-		priority += 20
+	priority += factors.add(factorPersonality, pet.Traits.Weigh(map[models.Trait]int{models.TraitCaution: 30}))
+	
+	// 基于主人设定的休息阈值调整，默认阈值与上面的体力判断一致
+	energy := int(energyPercent * 100)
+	threshold := pet.Policy.RestThreshold
+	if threshold > defaultRestThreshold && energy >= defaultRestThreshold && energy < threshold {
+		priority += factors.add(factorPolicy, 40)
+	} else if threshold < defaultRestThreshold && energy >= threshold && energy < defaultRestThreshold {
+		priority += factors.add(factorPolicy, -40)
 	}
 	
 	return priority
 }
 
 // 计算社交行为优先级
-func (ai *AIEngine) calculateSocializePriority(pet *models.Pet) int {
-	priority := 30 // 基础优先级
+func (ai *AIEngine) calculateSocializePriority(pet *models.Pet, factors *priorityFactors) int {
+	priority := factors.add(factorBase, 30) // 基础优先级
 	
 	// 基于社交度调整
 	if pet.Social < 30 {
-		priority += 60
+		priority += factors.add(factorSocial, 60)
 	} else if pet.Social < 50 {
-		priority += 30
+		priority += factors.add(factorSocial, 30)
 	}
 	
 	// 基于性格调整
-	switch pet.Personality {
-	case models.PersonalityFriendly:
-		priority += 40
-	case models.PersonalityCurious:
-		priority += 20
-	case models.PersonalityCautious:
-		priority -= 10
-	}
+	priority += factors.add(factorPersonality, pet.Traits.Weigh(map[models.Trait]int{
+		models.TraitSociability: 55,
+		models.TraitCuriosity:   20,
+		models.TraitCaution:     -15,
+	}))
 	
 	// 基于心情调整
 	if pet.Mood == models.MoodSad {
-		priority += 30
+		priority += factors.add(factorMood, 30)
+	}
+	
+	// 基于情绪调整
+	priority += factors.add(factorEmotion, emotionAdjust(pet, ActionSocialize, time.Now()))
+	
+	// 基于主人设定的社交意愿调整
+	priority += factors.add(factorPolicy, (pet.Policy.SocialAppetite-50)*4/5)
+	
+	// 想念相处愉快的伙伴
+	priority += factors.add(factorMemory, int(fondestFeeling(pet, time.Now())*memorySocialInfluence))
+	
+	if priority < 0 {
+		priority += factors.add(factorFloor, -priority)
 	}
 	
 	return priority
 }
 
 // 计算进食行为优先级
-func (ai *AIEngine) calculateEatPriority(pet *models.Pet) int {
+func (ai *AIEngine) calculateEatPriority(pet *models.Pet, factors *priorityFactors) int {
 	// 如果饱食度已达到90以上，不需要进食
 	if pet.Hunger >= 90 {
 		return 0
@@ -219,73 +312,38 @@ func (ai *AIEngine) calculateEatPriority(pet *models.Pet) int {
 	
 	// 基于饱食度调整
 	if pet.Hunger < 20 {
-		priority = 100 // 极高优先级
+		priority = factors.add(factorHunger, 100) // 极高优先级
 	} else if pet.Hunger < 40 {
-		priority = 70
+		priority = factors.add(factorHunger, 70)
 	} else if pet.Hunger < 60 {
-		priority = 30
+		priority = factors.add(factorHunger, 30)
 	} else if pet.Hunger < 80 {
-		priority = 10 // 轻微饥饿
+		priority = factors.add(factorHunger, 10) // 轻微饥饿
 	}
 	
 	// 基于性格调整（但不能导致已饱食时还要进食）
-	if pet.Personality == models.PersonalityGreedy && pet.Hunger < 85 {
-		priority += 15
+	if pet.Hunger < 85 {
+		priority += factors.add(factorPersonality, pet.Traits.Weigh(map[models.Trait]int{models.TraitGreed: 20}))
 	}
 	
 	return priority
 }
 
-// selectAction 基于优先级和随机性选择行为
-func (ai *AIEngine) selectAction(actions []Action, pet *models.Pet) Action {
-	if len(actions) == 1 {
-		return actions[0]
-	}
-	
-	// 计算加权随机选择
-	totalWeight := 0
-	for _, action := range actions {
-		totalWeight += action.Priority
-	}
-	
-	if totalWeight == 0 {
-		return actions[ai.rand.Intn(len(actions))]
-	}
-	
-	randomValue := ai.rand.Intn(totalWeight)
-	currentWeight := 0
-	
-	for _, action := range actions {
-		currentWeight += action.Priority
-		if randomValue < currentWeight {
-			return action
-		}
-	}
-	
-	return actions[len(actions)-1]
-}
-
 // 获取各种行为的原因描述
 func (ai *AIEngine) getExploreReason(pet *models.Pet) string {
-	reasons := []string{
-		fmt.Sprintf("%s 想要寻找新的冒险", pet.Name),
-		fmt.Sprintf("%s 对未知的地方充满好奇", pet.Name),
-		fmt.Sprintf("%s 希望找到一些宝藏", pet.Name),
-	}
-	
-	switch pet.Personality {
-	case models.PersonalityCurious:
-		reasons = append(reasons, fmt.Sprintf("%s 的好奇心驱使着探索", pet.Name))
-	case models.PersonalityBrave:
-		reasons = append(reasons, fmt.Sprintf("%s 勇敢地踏上冒险之路", pet.Name))
-	case models.PersonalityGreedy:
-		reasons = append(reasons, fmt.Sprintf("%s 想要寻找更多财富", pet.Name))
+	// 心情大好时迫不及待想出门
+	if joy, ok := feltEmotion(pet, models.EmotionJoy); ok {
+		return fmt.Sprintf("%s 因为%s兴高采烈，迫不及待想再去冒险", pet.Name, joy.Cause)
 	}
 	
-	return reasons[ai.rand.Intn(len(reasons))]
+	reason := ai.narrator.Narrate(Narrative{Kind: NarrateExploreIntent, PetName: pet.Name, Personality: pet.Personality, Mood: pet.Mood})
+	return fmt.Sprintf("%s %s", pet.Name, reason)
 }
 
 func (ai *AIEngine) getRestReason(pet *models.Pet) string {
+	if fear, ok := feltEmotion(pet, models.EmotionFear); ok {
+		return fmt.Sprintf("%s %s，还心有余悸，想先躲起来歇一歇", pet.Name, fear.Cause)
+	}
 	if pet.Energy < 30 {
 		return fmt.Sprintf("%s 感到很疲惫，需要休息", pet.Name)
 	}
@@ -296,6 +354,12 @@ func (ai *AIEngine) getRestReason(pet *models.Pet) string {
 }
 
 func (ai *AIEngine) getSocializeReason(pet *models.Pet) string {
+	if _, ok := feltEmotion(pet, models.EmotionLoneliness); ok {
+		return fmt.Sprintf("%s 孤单了太久，想找伙伴说说话", pet.Name)
+	}
+	if _, ok := feltEmotion(pet, models.EmotionSadness); ok {
+		return fmt.Sprintf("%s 心里难过，想找朋友倾诉", pet.Name)
+	}
 	if pet.Social < 30 {
 		return fmt.Sprintf("%s 感到孤独，想要交朋友", pet.Name)
 	}
@@ -307,4 +371,143 @@ func (ai *AIEngine) getEatReason(pet *models.Pet) string {
 		return fmt.Sprintf("%s 感到很饿，急需进食", pet.Name)
 	}
 	return fmt.Sprintf("%s 想要补充体力", pet.Name)
-}
\ No newline at end of file
+}
+
+const (
+	// foodReserve 处理银行业务时为买食物保留的金币
+	foodReserve = 20
+	// savingsThreshold 谨慎的宠物手头金币超过该值时会去存钱
+	savingsThreshold = 100
+	// minBorrowRisk 冒险倾向低于该值的宠物不会借钱
+	minBorrowRisk = 30
+	// defaultRestThreshold 默认休息阈值，与 models.DefaultPetPolicy 一致
+	defaultRestThreshold = 30
+	// financeTraitThreshold 谨慎或贪婪达到该值时宠物才会主动理财
+	financeTraitThreshold = 0.5
+)
+
+// personalityFinance 决定理财方式的特质：谨慎占上风时存钱，贪婪占上风时买装备，两者都不突出时不主动理财
+func personalityFinance(traits models.PersonalityTraits) models.Trait {
+	switch {
+	case traits.Caution >= financeTraitThreshold && traits.Caution >= traits.Greed:
+		return models.TraitCaution
+	case traits.Greed >= financeTraitThreshold && traits.Greed > traits.Caution:
+		return models.TraitGreed
+	}
+	return ""
+}
+
+// evaluateBankAction 根据性格和财务状况评估银行业务：谨慎的宠物存钱，贪婪的宠物借钱买装备
+func (ai *AIEngine) evaluateBankAction(pet *models.Pet) (Action, bool) {
+	bankAction := func(op string, amount, priority int, reason string) (Action, bool) {
+		return Action{
+			Type:     ActionBank,
+			Priority: priority,
+			Reason:   reason,
+			Duration: ai.rand.Intn(15) + 15, // 15-30秒
+			Params:   map[string]interface{}{"op": op, "amount": amount},
+			Factors:  []PriorityFactor{{Name: factorFinance, Value: priority}},
+		}, true
+	}
+
+	// 有欠款时优先还款，逾期更急迫
+	if loan := ai.bank.openLoan(pet.ID); loan != nil {
+		amount := pet.Coins - foodReserve
+		if amount > loan.Outstanding {
+			amount = loan.Outstanding
+		}
+		if amount > 0 {
+			priority := 20
+			if loan.Status == models.LoanOverdue {
+				priority = 60
+			}
+			priority += pet.Traits.Weigh(map[models.Trait]int{models.TraitCaution: 30})
+			return bankAction("repay", amount, priority, fmt.Sprintf("%s 想尽快还清银行的贷款", pet.Name))
+		}
+		return Action{}, false
+	}
+
+	switch personalityFinance(pet.Traits) {
+	case models.TraitCaution:
+		deposit := ai.bank.balance(pet.ID)
+		if pet.Coins < 15 && deposit > 0 && pet.Hunger < 50 {
+			amount := 30
+			if amount > deposit {
+				amount = deposit
+			}
+			return bankAction("withdraw", amount, 40, fmt.Sprintf("%s 取些积蓄买食物", pet.Name))
+		}
+		if pet.Coins > savingsThreshold {
+			priority := 25 + (pet.Coins-savingsThreshold)/10
+			if priority > 60 {
+				priority = 60
+			}
+			// 存款金额到银行时再按手头金币计算，路上花掉的金币不会让存款失败
+			action, _ := bankAction("deposit", 0, priority, fmt.Sprintf("%s 想把金币存进银行吃利息", pet.Name))
+			action.Params["keep"] = foodReserve * 2
+			return action, true
+		}
+
+	case models.TraitGreed:
+		gear, ok := pet.NextGear()
+		if !ok {
+			return Action{}, false
+		}
+		// 超出主人设定的花费上限时不买
+		if !pet.Policy.AllowsSpending(gear.Value) {
+			return Action{}, false
+		}
+		var action Action
+		if pet.Coins >= gear.Value {
+			action, _ = bankAction("buy", 0, 35, fmt.Sprintf("%s 看中了商店里的%s", pet.Name, gear.Name))
+		} else if need := gear.Value - pet.Coins; pet.Level >= 2 && need <= loanLimit(pet) && pet.Policy.RiskTolerance >= minBorrowRisk {
+			// 借到钱后直接去商店买装备
+			action, _ = bankAction("borrow", need, 25, fmt.Sprintf("%s 想借钱买%s", pet.Name, gear.Name))
+		} else {
+			return Action{}, false
+		}
+		action.Params["item"] = gear.ID
+		return action, true
+	}
+
+	return Action{}, false
+}
+
+// evaluateCraftAction 在材料齐全的配方中挑选成品价值最高的一个
+func (ai *AIEngine) evaluateCraftAction(pet *models.Pet) (Action, bool) {
+	var best *models.Recipe
+	for i := range models.Recipes {
+		recipe := &models.Recipes[i]
+		if pet.Coins < recipe.Coins+foodReserve || !pet.Policy.AllowsSpending(recipe.Coins) || canCraft(pet, *recipe) != nil {
+			continue
+		}
+		// 装备只做一件
+		if recipe.Output.Type == models.ItemTypeEquipment && pet.FindItem(recipe.Output.ID) != nil {
+			continue
+		}
+		if best == nil || recipe.Output.Value > best.Output.Value {
+			best = recipe
+		}
+	}
+	if best == nil {
+		return Action{}, false
+	}
+
+	var factors priorityFactors
+	priority := factors.add(factorBase, 20) + factors.add(factorRecipe, best.SuccessChance(pet.Level)/5)
+	weights := map[models.Trait]int{models.TraitCuriosity: 20, models.TraitGreed: 15}
+	// 谨慎的宠物不愿冒险制作成功率低的配方
+	if best.SuccessChance(pet.Level) < 60 {
+		weights[models.TraitCaution] = -20
+	}
+	priority += factors.add(factorPersonality, pet.Traits.Weigh(weights))
+
+	return Action{
+		Type:     ActionCraft,
+		Priority: priority,
+		Reason:   fmt.Sprintf("%s 收集齐了材料，开始制作%s", pet.Name, best.Name),
+		Duration: best.Duration,
+		Params:   map[string]interface{}{"recipe": best.ID},
+		Factors:  factors,
+	}, true
+}
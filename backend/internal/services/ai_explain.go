@@ -0,0 +1,216 @@
+package services
+
+import (
+	"math/rand"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// decisionLogSize 每只宠物保留的最近决策条数
+const decisionLogSize = 20
+
+// explainSamples 策略没有给出概率时，用于估算选择概率的模拟次数
+const explainSamples = 500
+
+// 优先级因素名称
+const (
+	factorBase        = "base"
+	factorPersonality = "personality"
+	factorEnergy      = "energy"
+	factorHealth      = "health"
+	factorMood        = "mood"
+	factorHunger      = "hunger"
+	factorSocial      = "social"
+	factorConditions  = "conditions"
+	factorWorldEvent  = "world_event"
+	factorLocation    = "location"
+	factorFinance     = "finance"
+	factorRecipe      = "recipe"
+	factorPolicy      = "policy"
+	factorLearned     = "learned"
+	factorMemory      = "memory"
+	factorGoal        = "goal"
+	factorEmotion     = "emotion"
+	factorFloor       = "floor"
+)
+
+// PriorityFactor 构成行为优先级的一项因素
+type PriorityFactor struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// priorityFactors 记录优先级的构成
+type priorityFactors []PriorityFactor
+
+// add 记录一项非零的因素并原样返回其数值
+func (f *priorityFactors) add(name string, value int) int {
+	if value != 0 {
+		*f = append(*f, PriorityFactor{Name: name, Value: value})
+	}
+	return value
+}
+
+// ProbabilisticStrategy 能直接给出各候选行为被选中概率的策略
+type ProbabilisticStrategy interface {
+	Strategy
+	Probabilities(ctx *DecisionContext) []float64
+}
+
+// Probabilities 加权随机选择的概率即优先级占比
+func (WeightedStrategy) Probabilities(ctx *DecisionContext) []float64 {
+	total := 0
+	for _, action := range ctx.Candidates {
+		total += action.Priority
+	}
+
+	probabilities := make([]float64, len(ctx.Candidates))
+	for i, action := range ctx.Candidates {
+		if total == 0 {
+			probabilities[i] = 1 / float64(len(ctx.Candidates))
+		} else {
+			probabilities[i] = float64(action.Priority) / float64(total)
+		}
+	}
+	return probabilities
+}
+
+// selectionProbabilities 各候选行为被选中的概率，策略不能直接给出时用固定种子模拟估算
+func selectionProbabilities(strategy Strategy, ctx *DecisionContext) []float64 {
+	if probabilistic, ok := strategy.(ProbabilisticStrategy); ok {
+		return probabilistic.Probabilities(ctx)
+	}
+
+	sample := &DecisionContext{Pet: ctx.Pet, Candidates: ctx.Candidates, Rand: rand.New(rand.NewSource(1))}
+	counts := make(map[ActionType]int)
+	for i := 0; i < explainSamples; i++ {
+		counts[strategy.Decide(sample).Type]++
+	}
+
+	probabilities := make([]float64, len(ctx.Candidates))
+	for i, action := range ctx.Candidates {
+		probabilities[i] = float64(counts[action.Type]) / explainSamples
+	}
+	return probabilities
+}
+
+// DecisionInputs 做决策时宠物的状态
+type DecisionInputs struct {
+	Personality models.PetPersonality    `json:"personality"`
+	Traits      models.PersonalityTraits `json:"traits"`
+	Mood        models.PetMood           `json:"mood"`
+	Emotions    models.Emotions          `json:"emotions"`
+	Status      models.PetStatus         `json:"status"`
+	Location    string                   `json:"location"`
+	Health      int                      `json:"health"`
+	MaxHealth   int                      `json:"max_health"`
+	Energy      int                      `json:"energy"`
+	MaxEnergy   int                      `json:"max_energy"`
+	Hunger      int                      `json:"hunger"`
+	Social      int                      `json:"social"`
+	Coins       int                      `json:"coins"`
+}
+
+func decisionInputs(pet *models.Pet) DecisionInputs {
+	return DecisionInputs{
+		Personality: pet.Personality,
+		Traits:      pet.Traits,
+		Mood:        pet.Mood,
+		Emotions:    pet.Emotions,
+		Status:      pet.Status,
+		Location:    pet.Location,
+		Health:      pet.Health,
+		MaxHealth:   pet.MaxHealth,
+		Energy:      pet.Energy,
+		MaxEnergy:   pet.MaxEnergy,
+		Hunger:      pet.Hunger,
+		Social:      pet.Social,
+		Coins:       pet.Coins,
+	}
+}
+
+// DecisionRecord 一次真实决策的记录
+type DecisionRecord struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	Strategy   string         `json:"strategy"`
+	Inputs     DecisionInputs `json:"inputs"`
+	Candidates []Action       `json:"candidates"`
+	Chosen     ActionType     `json:"chosen"`
+	Reason     string         `json:"reason"`
+}
+
+// ExplainedAction 候选行为及其被选中的概率
+type ExplainedAction struct {
+	Action
+	Probability float64 `json:"probability"`
+}
+
+// DecisionExplanation 宠物此刻会如何决策，以及最近的决策记录
+type DecisionExplanation struct {
+	PetID      string            `json:"pet_id"`
+	PetName    string            `json:"pet_name"`
+	Strategy   string            `json:"strategy"`
+	Inputs     DecisionInputs    `json:"inputs"`
+	Candidates []ExplainedAction `json:"candidates"`
+	// Learned 宠物从自身经历中学到的偏好，键为行为或"行为@地点"
+	Learned   map[string]float64 `json:"learned"`
+	Decisions []DecisionRecord   `json:"decisions"`
+}
+
+// recordDecision 把一次决策写入宠物的决策记录，只保留最近的若干条
+func (ai *AIEngine) recordDecision(pet *models.Pet, strategy string, candidates []Action, chosen Action) {
+	log := append(ai.decisions[pet.ID], DecisionRecord{
+		Timestamp:  time.Now(),
+		Strategy:   strategy,
+		Inputs:     decisionInputs(pet),
+		Candidates: candidates,
+		Chosen:     chosen.Type,
+		Reason:     chosen.Reason,
+	})
+	if len(log) > decisionLogSize {
+		log = log[len(log)-decisionLogSize:]
+	}
+	ai.decisions[pet.ID] = log
+}
+
+// Explain 评估宠物此刻的候选行为和选择概率，不执行也不记录决策
+func (ai *AIEngine) Explain(pet *models.Pet, limit int) *DecisionExplanation {
+	strategy := strategyFor(pet)
+	explanation := &DecisionExplanation{
+		PetID:      pet.ID,
+		PetName:    pet.Name,
+		Strategy:   strategy.Name(),
+		Inputs:     decisionInputs(pet),
+		Candidates: make([]ExplainedAction, 0),
+		Learned:    pet.Learned.Snapshot(time.Now()),
+		Decisions:  make([]DecisionRecord, 0),
+	}
+
+	if candidates := ai.evaluateAllActions(pet); len(candidates) > 0 {
+		ctx := &DecisionContext{Pet: pet, Candidates: candidates, Rand: ai.rand}
+		for i, probability := range selectionProbabilities(strategy, ctx) {
+			explanation.Candidates = append(explanation.Candidates, ExplainedAction{Action: candidates[i], Probability: probability})
+		}
+	}
+
+	// 最近的决策在前
+	decisions := ai.decisions[pet.ID]
+	for i := len(decisions) - 1; i >= 0 && (limit <= 0 || len(explanation.Decisions) < limit); i-- {
+		explanation.Decisions = append(explanation.Decisions, decisions[i])
+	}
+	return explanation
+}
+
+// ExplainDecision 解释宠物的 AI 决策，limit 为返回的最近决策条数
+func (ps *PetService) ExplainDecision(petID string, limit int) (*DecisionExplanation, error) {
+	// 评估会用到 AI 引擎的随机数生成器，需要独占锁
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+	return ps.aiEngine.Explain(pet, limit), nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"miningpet/internal/models"
+)
+
+// TestExplainDecision 解释给出每个候选行为的优先级构成和选择概率，不会记录决策；决策记录最近的在前且有上限
+func TestExplainDecision(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "thinker", models.PersonalityCurious)
+
+	explanation, err := ps.ExplainDecision(pet.ID, 0)
+	if err != nil {
+		t.Fatalf("Failed to explain decision: %v", err)
+	}
+	if explanation.Strategy != DefaultStrategy || len(explanation.Candidates) == 0 || len(explanation.Decisions) != 0 {
+		t.Fatalf("Expected weighted candidates and no decisions, got %+v", explanation)
+	}
+
+	total, sum := 0, 0.0
+	for _, candidate := range explanation.Candidates {
+		total += candidate.Priority
+	}
+	for _, candidate := range explanation.Candidates {
+		factors := 0
+		for _, factor := range candidate.Factors {
+			factors += factor.Value
+		}
+		if factors != candidate.Priority {
+			t.Errorf("Expected %s factors %+v to add up to priority %d", candidate.Type, candidate.Factors, candidate.Priority)
+		}
+		if expected := float64(candidate.Priority) / float64(total); math.Abs(candidate.Probability-expected) > 1e-9 {
+			t.Errorf("Expected %s probability %.3f, got %.3f", candidate.Type, expected, candidate.Probability)
+		}
+		sum += candidate.Probability
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("Expected probabilities to add up to 1, got %.3f", sum)
+	}
+
+	ps.mutex.Lock()
+	for i := 0; i < decisionLogSize+5; i++ {
+		ps.aiEngine.DecideNextAction(pet)
+	}
+	ps.mutex.Unlock()
+
+	if explanation, _ = ps.ExplainDecision(pet.ID, 0); len(explanation.Decisions) != decisionLogSize {
+		t.Errorf("Expected %d decisions kept, got %d", decisionLogSize, len(explanation.Decisions))
+	}
+	explanation, _ = ps.ExplainDecision(pet.ID, 3)
+	if len(explanation.Decisions) != 3 {
+		t.Fatalf("Expected 3 decisions, got %d", len(explanation.Decisions))
+	}
+	for i := 1; i < len(explanation.Decisions); i++ {
+		if explanation.Decisions[i].Timestamp.After(explanation.Decisions[i-1].Timestamp) {
+			t.Error("Expected the most recent decision first")
+		}
+	}
+	if _, err := ps.ExplainDecision("missing", 0); err != ErrPetNotFound {
+		t.Errorf("Expected pet not found, got %v", err)
+	}
+}
+
+// TestSelectionProbabilitiesEstimated 策略不能直接给出概率时用固定种子模拟估算，结果可重复
+func TestSelectionProbabilitiesEstimated(t *testing.T) {
+	pet := models.NewPet("estimator")
+	ctx := &DecisionContext{Pet: pet, Candidates: testCandidates(), Rand: rand.New(rand.NewSource(1))}
+
+	if probabilities := selectionProbabilities(restStrategy{}, ctx); !reflect.DeepEqual(probabilities, []float64{0, 1, 0}) {
+		t.Errorf("Expected a deterministic strategy to always rest, got %v", probabilities)
+	}
+
+	utility := NewUtilityStrategy()
+	utility.Noise = 0.5
+	first, second := selectionProbabilities(utility, ctx), selectionProbabilities(utility, ctx)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected repeatable estimates, got %v and %v", first, second)
+	}
+	sum := 0.0
+	for _, probability := range first {
+		sum += probability
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("Expected estimates to add up to 1, got %v", first)
+	}
+}
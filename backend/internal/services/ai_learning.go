@@ -0,0 +1,110 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+const (
+	// learningInfluence 学到的偏好最多让行为优先级上下浮动的比例
+	learningInfluence = 0.5
+	// learningLocationInfluence 学到的地点偏好对地点评分的影响
+	learningLocationInfluence = 0.4
+	// fleeReward 探索途中紧急逃回视为最差的结果
+	fleeReward = -1
+)
+
+// learnableActions 结果会被宠物记住的行为，银行业务的收支是主人的安排，不参与学习
+var learnableActions = map[ActionType]bool{
+	ActionExplore:   true,
+	ActionRest:      true,
+	ActionSocialize: true,
+	ActionEat:       true,
+	ActionCraft:     true,
+}
+
+// outcomeSnapshot 行动结算前的状态，用于计算结果的好坏
+type outcomeSnapshot struct {
+	coins      int
+	health     int
+	experience int
+	level      int
+}
+
+func snapshotOutcome(pet *models.Pet) outcomeSnapshot {
+	return outcomeSnapshot{coins: pet.Coins, health: pet.Health, experience: pet.Experience, level: pet.Level}
+}
+
+// outcomeReward 把一次行动的结果折算为 [-1, 1] 的奖励：赚到金币、获得经验和升级是好事，受伤是坏事。
+// 花掉的金币不算损失，那是进食和制作本身的代价
+func outcomeReward(pet *models.Pet, before outcomeSnapshot) float64 {
+	reward := 0.0
+	if gained := pet.Coins - before.coins; gained > 0 {
+		reward += float64(gained) / 50
+	}
+	if levels := pet.Level - before.level; levels > 0 {
+		// 升级会回满生命、清空经验，不再比较这两项
+		reward += 0.5 * float64(levels)
+	} else {
+		if exp := pet.Experience - before.experience; exp > 0 {
+			reward += float64(exp) / 40
+		}
+		if damage := before.health - pet.Health; damage > 0 && pet.MaxHealth > 0 {
+			reward -= 2 * float64(damage) / float64(pet.MaxHealth)
+		}
+	}
+	return math.Max(-1, math.Min(1, reward))
+}
+
+// learn 把一次结果记入宠物对该行为及地点的偏好，调用方需持有锁
+func learn(pet *models.Pet, actionType ActionType, location string, reward float64, now time.Time) {
+	if !learnableActions[actionType] {
+		return
+	}
+	if pet.Learned == nil {
+		pet.Learned = make(models.LearnedWeights)
+	}
+	pet.Learned.Learn(models.LearnedKey(string(actionType), ""), reward, now)
+	if location != "" {
+		pet.Learned.Learn(models.LearnedKey(string(actionType), location), reward, now)
+	}
+}
+
+// learnFromOutcome 行动完成后根据结果更新偏好，探索会同时记住出发时的地点，调用方需持有锁
+func learnFromOutcome(pet *models.Pet, action *models.PetAction, before outcomeSnapshot) {
+	location := ""
+	if ActionType(action.Type) == ActionExplore {
+		location, _ = action.Params["location"].(string)
+		if location == "" {
+			location = pet.Location
+		}
+	}
+	learn(pet, ActionType(action.Type), location, outcomeReward(pet, before), time.Now())
+}
+
+// learnedLocationBonus 学到的地点偏好对地点评分的加成
+func learnedLocationBonus(pet *models.Pet, location string, now time.Time) float64 {
+	return pet.Learned.Value(models.LearnedKey(string(ActionExplore), location), now) * learningLocationInfluence
+}
+
+// applyLearnedWeights 按学到的偏好调整候选行为的优先级，记为 learned 因素。
+// 偏好只改变倾向，不会让候选行为消失
+func applyLearnedWeights(pet *models.Pet, actions []Action, now time.Time) []Action {
+	for i := range actions {
+		action := &actions[i]
+		value := pet.Learned.Value(models.LearnedKey(string(action.Type), ""), now)
+		if value == 0 {
+			continue
+		}
+		factors := priorityFactors(action.Factors)
+		adjust := int(float64(action.Priority) * value * learningInfluence)
+		if action.Priority+adjust < 1 {
+			adjust = 1 - action.Priority
+		}
+		action.Priority += factors.add(factorLearned, adjust)
+		action.Factors = factors
+	}
+	return actions
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// TestLearnedWeightDecay 学到的偏好每72小时向中性衰减一半，新结果从衰减后的值开始学习
+func TestLearnedWeightDecay(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	weights := make(models.LearnedWeights)
+	weights.Learn("explore", 1, start)
+
+	for _, c := range []struct {
+		elapsed  time.Duration
+		expected float64
+	}{
+		{0, 1},
+		{-time.Hour, 1},
+		{models.LearningHalfLife, 0.5},
+		{2 * models.LearningHalfLife, 0.25},
+		{models.LearningHalfLife / 2, math.Sqrt(0.5)},
+	} {
+		if value := weights.Value("explore", start.Add(c.elapsed)); math.Abs(value-c.expected) > 1e-9 {
+			t.Errorf("Expected %.4f after %v, got %.4f", c.expected, c.elapsed, value)
+		}
+	}
+	if value := weights.Value("rest", start); value != 0 {
+		t.Errorf("Expected unknown preference to be neutral, got %f", value)
+	}
+
+	// 第二次经验的学习率为 1/2，从衰减到 0.5 的值向 1 靠拢
+	later := start.Add(models.LearningHalfLife)
+	weights.Learn("explore", 1, later)
+	if value := weights.Value("explore", later); math.Abs(value-0.75) > 1e-9 {
+		t.Errorf("Expected 0.75 after relearning, got %.4f", value)
+	}
+
+	// 经验很多之后仍以最小学习率跟上变化，奖励被限制在 [-1, 1]
+	for i := 0; i < 50; i++ {
+		weights.Learn("rest", 1, start)
+	}
+	weights.Learn("rest", -5, start)
+	if value := weights.Value("rest", start); math.Abs(value-0.8) > 1e-9 {
+		t.Errorf("Expected a single bad outcome to move a settled preference by 0.2, got %.4f", value)
+	}
+}
+
+// TestApplyLearnedWeights 偏好按比例调整优先级并记为 learned 因素，不会让候选行为消失；银行业务不参与学习
+func TestApplyLearnedWeights(t *testing.T) {
+	now := time.Now()
+	pet := models.NewPet("learner")
+	learn(pet, ActionExplore, "北方森林", 1, now)
+	learn(pet, ActionRest, "", -1, now)
+	learn(pet, ActionBank, "", 1, now)
+
+	if _, exists := pet.Learned[models.LearnedKey(string(ActionBank), "")]; exists {
+		t.Error("Expected bank outcomes not to be learned")
+	}
+	if bonus := learnedLocationBonus(pet, "北方森林", now); math.Abs(bonus-learningLocationInfluence) > 1e-9 {
+		t.Errorf("Expected location bonus %.2f, got %.2f", learningLocationInfluence, bonus)
+	}
+
+	actions := applyLearnedWeights(pet, []Action{
+		{Type: ActionExplore, Priority: 60},
+		{Type: ActionRest, Priority: 1},
+		{Type: ActionEat, Priority: 40},
+	}, now)
+	if actions[0].Priority != 90 || factorValue(actions[0].Factors, factorLearned) != 30 {
+		t.Errorf("Expected explore raised by half to 90, got %+v", actions[0])
+	}
+	if actions[1].Priority != 1 {
+		t.Errorf("Expected rest to stay a candidate, got priority %d", actions[1].Priority)
+	}
+	if actions[2].Priority != 40 || len(actions[2].Factors) != 0 {
+		t.Errorf("Expected eat untouched, got %+v", actions[2])
+	}
+}
+
+// TestLearnFromExploreOrigin 探索结果记在出发时的地点，而不是完成时宠物所在的地方
+func TestLearnFromExploreOrigin(t *testing.T) {
+	pet := models.NewPet("wanderer")
+	before := snapshotOutcome(pet)
+	pet.Coins += 50
+	pet.Location = "南方沙漠"
+
+	learnFromOutcome(pet, &models.PetAction{Type: string(ActionExplore), Params: map[string]interface{}{"location": "北方森林"}}, before)
+	now := time.Now()
+	if value := pet.Learned.Value(models.LearnedKey(string(ActionExplore), "北方森林"), now); value <= 0.99 {
+		t.Errorf("Expected a rewarding trip learned for the origin, got %.3f", value)
+	}
+	if _, exists := pet.Learned[models.LearnedKey(string(ActionExplore), "南方沙漠")]; exists {
+		t.Error("Expected nothing learned about where the pet ended up")
+	}
+}
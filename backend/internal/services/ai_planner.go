@@ -0,0 +1,431 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// plannerName 决策记录中按目标计划行动时的策略名
+	plannerName = "planner"
+	// goalAdoptChance 没有目标的宠物每次空闲决策时自己立下目标的概率（百分比）
+	goalAdoptChance = 5
+	// maxPlanSteps 一次规划的最多步数，走完后按当时的情况继续规划
+	maxPlanSteps = 4
+	// maxLevelGoal 主人最多要求宠物一次升几级
+	maxLevelGoal = 10
+	// depletedYield 地点产出低于该值时不再为攒钱去那里
+	depletedYield = 0.1
+)
+
+// ErrNoGoal 宠物当前没有目标
+var ErrNoGoal = errors.New("宠物当前没有目标")
+
+// newGoal 校验并创建目标，save_coins 指定 item 时目标金币为装备价格
+func newGoal(pet *models.Pet, kind models.GoalKind, target int, item, location string) (*models.PetGoal, error) {
+	goal := &models.PetGoal{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		Target:    target,
+		Plan:      make([]models.PlanStep, 0),
+		AdoptedAt: time.Now(),
+	}
+
+	switch kind {
+	case models.GoalSaveCoins:
+		if item != "" {
+			gear, exists := models.FindGear(item)
+			if !exists {
+				return nil, fmt.Errorf("商店没有这件装备: %s", item)
+			}
+			if pet.FindItem(gear.ID) != nil {
+				return nil, fmt.Errorf("%s 已经拥有%s", pet.Name, gear.Name)
+			}
+			goal.Item, goal.Target = gear.ID, gear.Value
+			goal.Description = fmt.Sprintf("攒够%d金币买%s", gear.Value, gear.Name)
+		} else {
+			if target <= pet.Coins || target > models.MaxSpendingLimit {
+				return nil, fmt.Errorf("目标金币应在 %d-%d 之间: %d", pet.Coins+1, models.MaxSpendingLimit, target)
+			}
+			goal.Description = fmt.Sprintf("攒够%d金币", target)
+		}
+	case models.GoalReachLevel:
+		if target <= pet.Level || target > pet.Level+maxLevelGoal {
+			return nil, fmt.Errorf("目标等级应在 %d-%d 之间: %d", pet.Level+1, pet.Level+maxLevelGoal, target)
+		}
+		goal.Description = fmt.Sprintf("升到%d级", target)
+	case models.GoalMakeFriend:
+		if !isExploreLocation(location) {
+			return nil, fmt.Errorf("未知的地点: %s", location)
+		}
+		goal.Target, goal.Location = 0, location
+		goal.Description = fmt.Sprintf("在%s结识新朋友", location)
+	default:
+		return nil, fmt.Errorf("未知的目标: %s", kind)
+	}
+	return goal, nil
+}
+
+// proposeGoal 宠物按性格给自己立下的目标
+func (ai *AIEngine) proposeGoal(pet *models.Pet) *models.PetGoal {
+	var goal *models.PetGoal
+	switch pet.Personality {
+	case models.PersonalityGreedy, models.PersonalityCautious:
+		if gear, ok := pet.NextGear(); ok && gear.Value > pet.Coins && pet.Policy.AllowsSpending(gear.Value) {
+			goal, _ = newGoal(pet, models.GoalSaveCoins, 0, gear.ID, "")
+		} else {
+			goal, _ = newGoal(pet, models.GoalSaveCoins, (pet.Coins+200)/50*50, "", "")
+		}
+	case models.PersonalityBrave:
+		goal, _ = newGoal(pet, models.GoalReachLevel, pet.Level+1, "", "")
+	default:
+		location := models.Locations[ai.rand.Intn(len(models.Locations))]
+		if len(pet.Policy.PreferredLocations) > 0 {
+			location = pet.Policy.PreferredLocations[ai.rand.Intn(len(pet.Policy.PreferredLocations))]
+		}
+		goal, _ = newGoal(pet, models.GoalMakeFriend, 0, "", location)
+	}
+	return goal
+}
+
+// goalAchieved 目标是否已经达成
+func goalAchieved(pet *models.Pet, goal *models.PetGoal) bool {
+	switch goal.Kind {
+	case models.GoalSaveCoins:
+		if goal.Item != "" {
+			return pet.FindItem(goal.Item) != nil
+		}
+		return pet.Coins >= goal.Target
+	case models.GoalReachLevel:
+		return pet.Level >= goal.Target
+	case models.GoalMakeFriend:
+		for _, memory := range pet.Memories {
+			if memory.Kind == models.MemoryMeeting && memory.Location == goal.Location && memory.CreatedAt.After(goal.AdoptedAt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// needsStep 追求目标之前要先照顾好自己，返回要做的事和原因
+func needsStep(pet *models.Pet) (models.PlanStep, string, bool) {
+	if pet.Hunger < 40 {
+		return models.PlanStep{Action: string(ActionEat)}, "肚子饿了", true
+	}
+	if pet.Health*2 < pet.MaxHealth {
+		return models.PlanStep{Action: string(ActionRest)}, "受了伤", true
+	}
+	if pet.Energy*10 < pet.MaxEnergy*3 {
+		return models.PlanStep{Action: string(ActionRest)}, "累了", true
+	}
+	return models.PlanStep{}, "", false
+}
+
+func exploreStep(location string) models.PlanStep {
+	return models.PlanStep{Action: string(ActionExplore), Params: map[string]interface{}{"location": location}}
+}
+
+// planGoal 按宠物此刻的状况为目标规划接下来的几步
+func (ai *AIEngine) planGoal(pet *models.Pet, goal *models.PetGoal) []models.PlanStep {
+	steps := make([]models.PlanStep, 0, maxPlanSteps)
+	if step, _, ok := needsStep(pet); ok {
+		steps = append(steps, step)
+	}
+
+	location := pet.Location
+	if ai.world != nil {
+		if best, _ := ai.chooseLocation(pet); best != "" {
+			location = best
+		}
+	}
+
+	switch goal.Kind {
+	case models.GoalSaveCoins:
+		if goal.Item != "" && pet.Coins >= goal.Target {
+			steps = append(steps, models.PlanStep{Action: string(ActionBank), Params: map[string]interface{}{"op": "buy", "item": goal.Item}})
+			break
+		}
+		steps = append(steps, exploreStep(location), exploreStep(location))
+	case models.GoalReachLevel:
+		steps = append(steps, exploreStep(location), exploreStep(location), models.PlanStep{Action: string(ActionRest)})
+	case models.GoalMakeFriend:
+		steps = append(steps, exploreStep(goal.Location), models.PlanStep{Action: string(ActionSocialize)}, exploreStep(goal.Location))
+	}
+
+	if len(steps) > maxPlanSteps {
+		steps = steps[:maxPlanSteps]
+	}
+	return steps
+}
+
+// stepBlocked 计划的下一步是否因情况变化而行不通，返回原因，行得通时为空
+func (ai *AIEngine) stepBlocked(pet *models.Pet, goal *models.PetGoal, step models.PlanStep) string {
+	// 饿了、累了或受伤时先照顾好自己
+	if need, cause, ok := needsStep(pet); ok && need.Action != step.Action && ActionType(step.Action) != ActionRest {
+		return cause
+	}
+
+	switch ActionType(step.Action) {
+	case ActionExplore:
+		if !pet.CanExplore() {
+			return "没有力气探索了"
+		}
+		location, _ := step.Params["location"].(string)
+		if goal.Kind == models.GoalSaveCoins && ai.world != nil && ai.population != nil {
+			if resource, exists := ai.world.resources[location]; exists && yieldFactor(resource, ai.population(location)) < depletedYield {
+				return fmt.Sprintf("%s的矿脉快被挖空了", location)
+			}
+		}
+	case ActionRest:
+		if !pet.CanRest() {
+			return "现在没法休息"
+		}
+	case ActionSocialize:
+		if !pet.CanSocialize() {
+			return "现在没有心思社交"
+		}
+	case ActionEat:
+		if pet.Hunger >= 80 {
+			return "已经不饿了"
+		}
+	case ActionBank:
+		if gear, exists := models.FindGear(goal.Item); exists && pet.Coins < gear.Value {
+			return "金币又不够了"
+		}
+	}
+	return ""
+}
+
+// stepLabel 计划步骤在事件消息中的说法
+func stepLabel(step models.PlanStep) string {
+	switch ActionType(step.Action) {
+	case ActionExplore:
+		location, _ := step.Params["location"].(string)
+		return "探索" + location
+	case ActionRest:
+		return "休息"
+	case ActionSocialize:
+		return "社交"
+	case ActionEat:
+		return "进食"
+	case ActionBank:
+		item, _ := step.Params["item"].(string)
+		if gear, exists := models.FindGear(item); exists {
+			return "购买" + gear.Name
+		}
+		return "去银行"
+	}
+	return step.Action
+}
+
+func planText(plan []models.PlanStep) string {
+	labels := make([]string, 0, len(plan))
+	for _, step := range plan {
+		labels = append(labels, stepLabel(step))
+	}
+	return strings.Join(labels, " → ")
+}
+
+// plannedAction 把计划步骤转换为可执行的行为，时长与 AI 评估的同类行为一致
+func (ai *AIEngine) plannedAction(pet *models.Pet, goal *models.PetGoal, step models.PlanStep) Action {
+	action := Action{
+		Type:     ActionType(step.Action),
+		Priority: 100,
+		Reason:   fmt.Sprintf("%s 为了%s，接下来%s", pet.Name, goal.Description, stepLabel(step)),
+		Params:   step.Params,
+		Factors:  []PriorityFactor{{Name: factorGoal, Value: 100}},
+	}
+	switch action.Type {
+	case ActionExplore:
+		action.Duration = ai.rand.Intn(60) + 30
+	case ActionRest:
+		action.Duration = ai.rand.Intn(30) + 20
+	case ActionSocialize:
+		action.Duration = ai.rand.Intn(40) + 25
+	case ActionEat:
+		action.Duration = ai.rand.Intn(20) + 10
+	case ActionBank:
+		action.Duration = ai.rand.Intn(15) + 15
+	}
+	return action
+}
+
+// adoptGoal 宠物开始追求目标并规划第一段行动，调用方需持有锁
+func (ps *PetService) adoptGoal(pet *models.Pet, goal *models.PetGoal) {
+	goal.Plan = ps.aiEngine.planGoal(pet, goal)
+	pet.Goal = goal
+
+	message := fmt.Sprintf("[%s] 立下目标：%s。计划：%s", pet.Name, goal.Description, planText(goal.Plan))
+	if goal.AssignedBy != "" {
+		message = fmt.Sprintf("[%s] 接受了主人的目标：%s。计划：%s", pet.Name, goal.Description, planText(goal.Plan))
+	}
+	ps.addGoalEvent(pet, message)
+	ps.savePetToDatabase(pet)
+}
+
+// finishGoal 目标达成或放弃，都会留下回忆，调用方需持有锁
+func (ps *PetService) finishGoal(pet *models.Pet, achieved bool, cause string) {
+	goal := pet.Goal
+	pet.Goal = nil
+
+	if achieved {
+		ps.addGoalEvent(pet, fmt.Sprintf("[%s] 🎯 达成了目标：%s！", pet.Name, goal.Description))
+		ps.remember(pet, models.Memory{
+			Kind:       models.MemoryGoalAchieved,
+			Summary:    fmt.Sprintf("达成了目标：%s", goal.Description),
+			Location:   pet.Location,
+			Valence:    70,
+			Importance: 75,
+		})
+		pet.Feel(models.EmotionJoy, 0.7, "达成了目标", time.Now())
+	} else {
+		ps.addGoalEvent(pet, fmt.Sprintf("[%s] %s，放弃了目标：%s", pet.Name, cause, goal.Description))
+		ps.remember(pet, models.Memory{
+			Kind:       models.MemoryGoalAbandoned,
+			Summary:    fmt.Sprintf("%s，放弃了目标：%s", cause, goal.Description),
+			Location:   pet.Location,
+			Valence:    -30,
+			Importance: 30,
+		})
+		pet.Feel(models.EmotionSadness, 0.4, "放弃了目标", time.Now())
+	}
+	ps.savePetToDatabase(pet)
+}
+
+func (ps *PetService) addGoalEvent(pet *models.Pet, message string) {
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventGoal,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data:      models.EventData{Location: pet.Location},
+	})
+}
+
+// pursueGoal 空闲的宠物按目标计划行动：检查目标是否达成，情况变化时重新规划，然后执行下一步。
+// 返回是否已按计划行动，没有目标或计划走不通时交给 AI 决策。调用方需持有锁
+func (ps *PetService) pursueGoal(pet *models.Pet) bool {
+	if pet.Status != models.StatusIdle {
+		return false
+	}
+
+	if pet.Goal == nil {
+		if ps.aiEngine.rand.Intn(100) >= goalAdoptChance {
+			return false
+		}
+		goal := ps.aiEngine.proposeGoal(pet)
+		if goal == nil {
+			return false
+		}
+		ps.adoptGoal(pet, goal)
+	}
+
+	goal := pet.Goal
+	if goalAchieved(pet, goal) {
+		ps.finishGoal(pet, true, "")
+		return false
+	}
+	if goal.Expired(time.Now()) {
+		ps.finishGoal(pet, false, "迟迟没有进展")
+		return false
+	}
+
+	if len(goal.Plan) == 0 {
+		// 一段计划走完了，按现在的情况继续规划
+		goal.Plan = ps.aiEngine.planGoal(pet, goal)
+	} else if cause := ps.aiEngine.stepBlocked(pet, goal, goal.Plan[0]); cause != "" {
+		goal.Plan = ps.aiEngine.planGoal(pet, goal)
+		goal.Replans++
+		ps.addGoalEvent(pet, fmt.Sprintf("[%s] %s，为了%s重新规划：%s", pet.Name, cause, goal.Description, planText(goal.Plan)))
+	}
+	if len(goal.Plan) == 0 || ps.aiEngine.stepBlocked(pet, goal, goal.Plan[0]) != "" {
+		return false
+	}
+
+	action := ps.aiEngine.plannedAction(pet, goal, goal.Plan[0])
+	goal.Plan = goal.Plan[1:]
+	ps.aiEngine.recordDecision(pet, plannerName, []Action{action}, action)
+	ps.executeAction(pet, action)
+	ps.savePetToDatabase(pet)
+	return true
+}
+
+// SetPetGoal 主人为宠物指定目标，替换宠物原有的目标
+func (ps *PetService) SetPetGoal(petID string, kind models.GoalKind, target int, item, location string) (*models.PetGoal, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+	return ps.assignGoal(pet, kind, target, item, location)
+}
+
+// assignGoal 调用方需持有锁
+func (ps *PetService) assignGoal(pet *models.Pet, kind models.GoalKind, target int, item, location string) (*models.PetGoal, error) {
+	goal, err := newGoal(pet, kind, target, item, location)
+	if err != nil {
+		return nil, err
+	}
+	goal.AssignedBy = "owner"
+	ps.adoptGoal(pet, goal)
+	return goal, nil
+}
+
+// ClearPetGoal 让宠物放弃当前目标
+func (ps *PetService) ClearPetGoal(petID string) error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return ErrPetNotFound
+	}
+	if pet.Goal == nil {
+		return ErrNoGoal
+	}
+	ps.finishGoal(pet, false, "主人改变了主意")
+	return nil
+}
+
+func (ps *PetService) executeGoalCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	if kind, ok := params["kind"].(string); ok && kind != "" {
+		item, _ := params["item"].(string)
+		location, _ := params["location"].(string)
+		if _, err := ps.assignGoal(pet, models.GoalKind(kind), paramInt(params, "target"), item, location); err != nil {
+			return nil, err
+		}
+	}
+
+	message := fmt.Sprintf("%s 目前没有目标", pet.Name)
+	if pet.Goal != nil {
+		message = fmt.Sprintf("%s 的目标：%s。计划：%s", pet.Name, pet.Goal.Description, planText(pet.Goal.Plan))
+	}
+	return map[string]interface{}{
+		"action":  "goal",
+		"goal":    pet.Goal,
+		"message": message,
+	}, nil
+}
+
+func (ps *PetService) executeGoalClearCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	if pet.Goal == nil {
+		return nil, ErrNoGoal
+	}
+	description := pet.Goal.Description
+	ps.finishGoal(pet, false, "主人改变了主意")
+
+	return map[string]interface{}{
+		"action":  "goal_clear",
+		"message": fmt.Sprintf("%s 放弃了目标：%s", pet.Name, description),
+	}, nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"miningpet/internal/models"
+)
+
+// DefaultStrategy 未指定策略的宠物使用的决策策略
+const DefaultStrategy = "weighted"
+
+// ErrUnknownStrategy 没有注册该名称的决策策略
+var ErrUnknownStrategy = errors.New("unknown AI strategy")
+
+// DecisionContext 一次决策的输入：宠物和引擎评估出的候选行为
+type DecisionContext struct {
+	Pet *models.Pet
+	// Candidates 当前可执行的行为，已带好优先级、时长和参数，至少有一个
+	Candidates []Action
+	Rand       *rand.Rand
+}
+
+// candidate 取出指定类型的候选行为
+func (ctx *DecisionContext) candidate(actionType ActionType) (Action, bool) {
+	for _, action := range ctx.Candidates {
+		if action.Type == actionType {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// preferenceFactors 宠物和主人的偏好因素：不反映当下需求，而是性格、主人策略、经验、记忆、目标和情绪带来的倾向
+var preferenceFactors = map[string]bool{
+	factorPersonality: true,
+	factorPolicy:      true,
+	factorLearned:     true,
+	factorMemory:      true,
+	factorGoal:        true,
+	factorEmotion:     true,
+}
+
+// preference 候选行为优先级中偏好因素的合计，不按需求打分的策略用它体现偏好
+func preference(action Action) int {
+	total := 0
+	for _, factor := range action.Factors {
+		if preferenceFactors[factor.Name] {
+			total += factor.Value
+		}
+	}
+	return total
+}
+
+// Strategy AI 决策策略，从候选行为中选出宠物下一步要做的事。
+// 决策在 PetService 的主锁内进行，实现不能阻塞
+type Strategy interface {
+	Name() string
+	Description() string
+	Decide(ctx *DecisionContext) Action
+}
+
+// StrategyInfo 策略列表中的一项
+type StrategyInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]Strategy{
+		DefaultStrategy: WeightedStrategy{},
+		"utility":       NewUtilityStrategy(),
+		"behavior_tree": NewBehaviorTreeStrategy(),
+	}
+)
+
+// RegisterStrategy 注册决策策略，同名策略会被替换。宠物通过名称选择策略
+func RegisterStrategy(strategy Strategy) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[strategy.Name()] = strategy
+}
+
+// LookupStrategy 按名称查找已注册的策略
+func LookupStrategy(name string) (Strategy, bool) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	strategy, exists := strategies[name]
+	return strategy, exists
+}
+
+// ListStrategies 所有已注册的策略，按名称排序
+func ListStrategies() []StrategyInfo {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	infos := make([]StrategyInfo, 0, len(strategies))
+	for _, strategy := range strategies {
+		infos = append(infos, StrategyInfo{Name: strategy.Name(), Description: strategy.Description()})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// strategyFor 宠物使用的策略，未设置或已不存在时退回默认策略
+func strategyFor(pet *models.Pet) Strategy {
+	if strategy, exists := LookupStrategy(pet.Strategy); exists {
+		return strategy
+	}
+	strategy, _ := LookupStrategy(DefaultStrategy)
+	return strategy
+}
+
+// WeightedStrategy 按优先级加权随机选择，优先级越高越可能被选中
+type WeightedStrategy struct{}
+
+func (WeightedStrategy) Name() string { return DefaultStrategy }
+
+func (WeightedStrategy) Description() string { return "按优先级加权随机选择行为" }
+
+func (WeightedStrategy) Decide(ctx *DecisionContext) Action {
+	return weightedChoice(ctx.Candidates, ctx.Rand)
+}
+
+// weightedChoice 基于优先级和随机性选择行为
+func weightedChoice(actions []Action, rnd *rand.Rand) Action {
+	if len(actions) == 1 {
+		return actions[0]
+	}
+
+	// 计算加权随机选择
+	totalWeight := 0
+	for _, action := range actions {
+		totalWeight += action.Priority
+	}
+
+	if totalWeight == 0 {
+		return actions[rnd.Intn(len(actions))]
+	}
+
+	randomValue := rnd.Intn(totalWeight)
+	currentWeight := 0
+
+	for _, action := range actions {
+		currentWeight += action.Priority
+		if randomValue < currentWeight {
+			return action
+		}
+	}
+
+	return actions[len(actions)-1]
+}
+
+// SetPetStrategy 为宠物选择决策策略，下一次决策起生效
+func (ps *PetService) SetPetStrategy(petID, name string) (*models.Pet, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+	if err := ps.setStrategy(pet, name); err != nil {
+		return nil, err
+	}
+	return pet, nil
+}
+
+// setStrategy 调用方需持有锁
+func (ps *PetService) setStrategy(pet *models.Pet, name string) error {
+	if _, exists := LookupStrategy(name); !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownStrategy, name)
+	}
+	pet.Strategy = name
+	ps.savePetToDatabase(pet)
+	return nil
+}
+
+func (ps *PetService) executeStrategyCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	if name, ok := params["name"].(string); ok && name != "" {
+		if err := ps.setStrategy(pet, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]interface{}{
+		"action":     "strategy",
+		"strategy":   strategyFor(pet).Name(),
+		"strategies": ListStrategies(),
+		"message":    fmt.Sprintf("%s 使用 %s 策略做决定", pet.Name, strategyFor(pet).Name()),
+	}, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"miningpet/internal/models"
+)
+
+// restStrategy 总是选择休息的测试策略
+type restStrategy struct{}
+
+func (restStrategy) Name() string        { return "test_rest" }
+func (restStrategy) Description() string { return "总是休息" }
+func (restStrategy) Decide(ctx *DecisionContext) Action {
+	action, _ := ctx.candidate(ActionRest)
+	return action
+}
+
+// testCandidates 吃、休息、探索三个候选行为
+func testCandidates() []Action {
+	return []Action{
+		{Type: ActionEat, Priority: 10},
+		{Type: ActionRest, Priority: 30},
+		{Type: ActionExplore, Priority: 60},
+	}
+}
+
+// TestStrategyRegistry 内置策略按名称排序列出，注册的策略可以被宠物选用，未知策略被拒绝并退回默认策略
+func TestStrategyRegistry(t *testing.T) {
+	var names []string
+	for _, info := range ListStrategies() {
+		names = append(names, info.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"behavior_tree", "utility", DefaultStrategy}) {
+		t.Errorf("Expected built-in strategies sorted by name, got %v", names)
+	}
+
+	RegisterStrategy(restStrategy{})
+	t.Cleanup(func() {
+		strategiesMu.Lock()
+		delete(strategies, restStrategy{}.Name())
+		strategiesMu.Unlock()
+	})
+	if _, exists := LookupStrategy("test_rest"); !exists {
+		t.Fatal("Expected registered strategy to be found")
+	}
+
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "strategist", models.PersonalityCurious)
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if err := ps.setStrategy(pet, "test_rest"); err != nil {
+		t.Fatalf("Failed to set strategy: %v", err)
+	}
+	ctx := &DecisionContext{Pet: pet, Candidates: testCandidates(), Rand: rand.New(rand.NewSource(1))}
+	if action := strategyFor(pet).Decide(ctx); action.Type != ActionRest {
+		t.Errorf("Expected the registered strategy to decide, got %s", action.Type)
+	}
+
+	if err := ps.setStrategy(pet, "missing"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("Expected unknown strategy error, got %v", err)
+	}
+	if pet.Strategy != "test_rest" {
+		t.Errorf("Expected strategy unchanged after a rejected name, got %s", pet.Strategy)
+	}
+	// 策略被移除后退回默认策略
+	pet.Strategy = "removed"
+	if name := strategyFor(pet).Name(); name != DefaultStrategy {
+		t.Errorf("Expected fallback to %s, got %s", DefaultStrategy, name)
+	}
+}
+
+// TestWeightedChoiceDeterministic 同样的随机种子得到同样的选择，零优先级的行为不会被选中
+func TestWeightedChoiceDeterministic(t *testing.T) {
+	candidates := testCandidates()
+	candidates[0].Priority = 0
+
+	first, second := rand.New(rand.NewSource(42)), rand.New(rand.NewSource(42))
+	counts := make(map[ActionType]int)
+	for i := 0; i < 1000; i++ {
+		a, b := weightedChoice(candidates, first), weightedChoice(candidates, second)
+		if a.Type != b.Type {
+			t.Fatalf("Expected identical choices with the same seed, got %s and %s", a.Type, b.Type)
+		}
+		counts[a.Type]++
+	}
+	if counts[ActionEat] != 0 {
+		t.Errorf("Expected zero-priority action never chosen, got %d", counts[ActionEat])
+	}
+	// 探索的优先级是休息的两倍
+	if counts[ActionExplore] <= counts[ActionRest] {
+		t.Errorf("Expected explore chosen more often than rest, got %v", counts)
+	}
+
+	if action := weightedChoice(candidates[:1], first); action.Type != ActionEat {
+		t.Errorf("Expected the only candidate, got %s", action.Type)
+	}
+}
+
+// TestBuiltinStrategiesFollowNeeds 效用 AI 和行为树在饥饿时都选择进食，相同种子的效用决策可重复
+func TestBuiltinStrategiesFollowNeeds(t *testing.T) {
+	pet := models.NewPet("hungry")
+	pet.Hunger = 5
+
+	for _, name := range []string{"utility", "behavior_tree"} {
+		strategy, _ := LookupStrategy(name)
+		ctx := &DecisionContext{Pet: pet, Candidates: testCandidates(), Rand: rand.New(rand.NewSource(7))}
+		if action := strategy.Decide(ctx); action.Type != ActionEat {
+			t.Errorf("Expected %s to feed a starving pet, got %s", name, action.Type)
+		}
+	}
+
+	pet.Hunger, pet.Energy = 70, 70
+	utility := NewUtilityStrategy()
+	a := utility.Decide(&DecisionContext{Pet: pet, Candidates: testCandidates(), Rand: rand.New(rand.NewSource(3))})
+	b := utility.Decide(&DecisionContext{Pet: pet, Candidates: testCandidates(), Rand: rand.New(rand.NewSource(3))})
+	if a.Type != b.Type {
+		t.Errorf("Expected identical utility decisions with the same seed, got %s and %s", a.Type, b.Type)
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"math"
+
+	"miningpet/internal/models"
+)
+
+// CurveKind 效用曲线的形状
+type CurveKind string
+
+const (
+	CurveLinear    CurveKind = "linear"
+	CurveQuadratic CurveKind = "quadratic"
+	CurveLogistic  CurveKind = "logistic"
+)
+
+// UtilityCurve 把 [0, 1] 的输入映射为 [0, 1] 的效用
+type UtilityCurve struct {
+	Kind CurveKind `json:"kind"`
+	// Slope 线性曲线的斜率，二次曲线的系数
+	Slope float64 `json:"slope"`
+	// Offset 线性和二次曲线的截距
+	Offset float64 `json:"offset"`
+	// Midpoint 与 Steepness 决定逻辑斯谛曲线的中点和陡峭程度
+	Midpoint  float64 `json:"midpoint"`
+	Steepness float64 `json:"steepness"`
+}
+
+// Eval 计算 x 处的效用，结果限制在 [0, 1]
+func (c UtilityCurve) Eval(x float64) float64 {
+	var y float64
+	switch c.Kind {
+	case CurveQuadratic:
+		y = c.Slope*x*x + c.Offset
+	case CurveLogistic:
+		y = 1 / (1 + math.Exp(-c.Steepness*(x-c.Midpoint)))
+	default:
+		y = c.Slope*x + c.Offset
+	}
+	return math.Max(0, math.Min(1, y))
+}
+
+// DefaultUtilityCurves 各类行为的默认效用曲线，输入为对应需求的迫切程度
+func DefaultUtilityCurves() map[ActionType]UtilityCurve {
+	return map[ActionType]UtilityCurve{
+		// 饥饿：越饿越急，接近空腹时迅速升高
+		ActionEat: {Kind: CurveQuadratic, Slope: 1.1},
+		// 疲劳或受伤：过半后急剧上升
+		ActionRest: {Kind: CurveLogistic, Midpoint: 0.55, Steepness: 10},
+		// 孤独：线性增长
+		ActionSocialize: {Kind: CurveLinear, Slope: 0.8, Offset: 0.05},
+		// 探索：精力越充沛越想出门
+		ActionExplore: {Kind: CurveLogistic, Midpoint: 0.45, Steepness: 8},
+		// 银行和制作：沿用引擎给出的优先级
+		ActionBank:  {Kind: CurveLinear, Slope: 1},
+		ActionCraft: {Kind: CurveLinear, Slope: 1},
+	}
+}
+
+// UtilityStrategy 效用 AI：按需求计算每个候选行为的效用，选择效用最高的行为。
+// 曲线可以调整，调整后的实例用新名称注册即可并行实验
+type UtilityStrategy struct {
+	StrategyName string
+	Curves       map[ActionType]UtilityCurve
+	// Noise 给效用加上的随机扰动幅度，避免宠物的行为完全可预测
+	Noise float64
+	// PreferenceWeight 偏好因素对效用的影响，每 100 点偏好加减的效用
+	PreferenceWeight float64
+}
+
+// NewUtilityStrategy 使用默认曲线的效用 AI
+func NewUtilityStrategy() *UtilityStrategy {
+	return &UtilityStrategy{
+		StrategyName:     "utility",
+		Curves:           DefaultUtilityCurves(),
+		Noise:            0.05,
+		PreferenceWeight: 0.3,
+	}
+}
+
+func (s *UtilityStrategy) Name() string { return s.StrategyName }
+
+func (s *UtilityStrategy) Description() string {
+	return "按效用曲线为每个行为打分，选择最迫切的需求"
+}
+
+// urgency 行为对应需求的迫切程度，范围 [0, 1]
+func urgency(pet *models.Pet, action Action) float64 {
+	ratio := func(value, max int) float64 {
+		if max <= 0 {
+			return 0
+		}
+		return math.Max(0, math.Min(1, float64(value)/float64(max)))
+	}
+
+	switch action.Type {
+	case ActionEat:
+		return 1 - ratio(pet.Hunger, 100)
+	case ActionRest:
+		return math.Max(1-ratio(pet.Energy, pet.MaxEnergy), 1-ratio(pet.Health, pet.MaxHealth))
+	case ActionSocialize:
+		return 1 - ratio(pet.Social, 100)
+	case ActionExplore:
+		// 精力和饱食度都充足时才想出门，心情好时更积极
+		drive := math.Min(ratio(pet.Energy, pet.MaxEnergy), ratio(pet.Hunger, 100))
+		return math.Max(0, math.Min(1, drive+pet.GetMoodInfluence()*0.1))
+	default:
+		return ratio(action.Priority, 100)
+	}
+}
+
+// Score 候选行为的效用：需求的迫切程度加上偏好因素的影响。
+// 银行和制作的迫切程度本身就是优先级，已经包含偏好，不再重复计入
+func (s *UtilityStrategy) Score(pet *models.Pet, action Action) float64 {
+	curve, exists := s.Curves[action.Type]
+	if !exists {
+		curve = UtilityCurve{Kind: CurveLinear, Slope: 1}
+	}
+	score := curve.Eval(urgency(pet, action))
+	switch action.Type {
+	case ActionEat, ActionRest, ActionSocialize, ActionExplore:
+		score += float64(preference(action)) / 100 * s.PreferenceWeight
+	}
+	return score
+}
+
+func (s *UtilityStrategy) Decide(ctx *DecisionContext) Action {
+	best := ctx.Candidates[0]
+	bestScore := math.Inf(-1)
+	for _, action := range ctx.Candidates {
+		score := s.Score(ctx.Pet, action)
+		if s.Noise > 0 {
+			score += (ctx.Rand.Float64()*2 - 1) * s.Noise
+		}
+		if score > bestScore {
+			best, bestScore = action, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,449 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// auctionCommissionRate 拍卖行从成交价中抽取的佣金（百分比），最少1金币
+	auctionCommissionRate = 5
+	// auctionMinDuration / auctionMaxDuration 拍卖时长范围
+	auctionMinDuration = time.Minute
+	auctionMaxDuration = 24 * time.Hour
+	// auctionDefaultDuration 未指定时长时的默认值
+	auctionDefaultDuration = time.Hour
+	// maxOpenAuctionsPerPet 每只宠物同时进行中的拍卖数量上限
+	maxOpenAuctionsPerPet = 5
+)
+
+var (
+	// ErrAuctionNotFound 拍卖不存在
+	ErrAuctionNotFound = errors.New("auction not found")
+	// ErrAuctionClosed 拍卖已结束
+	ErrAuctionClosed = errors.New("auction is closed")
+)
+
+// AuctionService 拍卖行，进行中的拍卖由 PetService 的主锁保护
+type AuctionService struct {
+	repo *database.AuctionRepository
+	open map[string]*models.Auction
+}
+
+// CreateAuctionRequest 上架请求
+type CreateAuctionRequest struct {
+	PetID       string
+	ItemID      string
+	Quantity    int
+	StartingBid int
+	BuyoutPrice int
+	Duration    time.Duration
+}
+
+// NewAuctionService 创建拍卖行并加载进行中的拍卖
+func NewAuctionService() *AuctionService {
+	as := &AuctionService{
+		repo: database.NewAuctionRepository(),
+		open: make(map[string]*models.Auction),
+	}
+
+	auctions, err := as.repo.GetOpenAuctions()
+	if err != nil {
+		log.Printf("Warning: failed to load auctions from database: %v", err)
+		return as
+	}
+	for _, auction := range auctions {
+		as.open[auction.ID] = auction
+	}
+
+	log.Printf("Loaded %d open auctions from database", len(auctions))
+	return as
+}
+
+// totalEscrow 托管中的金币总额
+func (as *AuctionService) totalEscrow() int64 {
+	var total int64
+	for _, auction := range as.open {
+		if auction.HighestBidderID != "" {
+			total += int64(auction.CurrentBid)
+		}
+	}
+	return total
+}
+
+func (as *AuctionService) openCountBySeller(petID string) int {
+	count := 0
+	for _, auction := range as.open {
+		if auction.SellerID == petID {
+			count++
+		}
+	}
+	return count
+}
+
+// auctionCommission 计算佣金
+func auctionCommission(price int) int {
+	commission := price * auctionCommissionRate / 100
+	if commission < 1 {
+		commission = 1
+	}
+	if commission > price {
+		commission = price
+	}
+	return commission
+}
+
+// saveAuction 登记拍卖快照，随指定宠物一起落库
+func (ps *PetService) saveAuction(petID string, auction *models.Auction) {
+	dbAuction, err := database.ConvertToDBAuction(auction)
+	if err != nil {
+		log.Printf("Failed to convert auction %s: %v", auction.ID, err)
+		return
+	}
+	ps.ledger.addRecord(petID, dbAuction)
+}
+
+// CreateAuction 宠物把背包中的物品交给拍卖行上架
+func (ps *PetService) CreateAuction(req CreateAuctionRequest) (*models.Auction, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[req.PetID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+
+	if req.Quantity <= 0 {
+		req.Quantity = 1
+	}
+	if req.Duration == 0 {
+		req.Duration = auctionDefaultDuration
+	}
+	if req.Duration < auctionMinDuration || req.Duration > auctionMaxDuration {
+		return nil, fmt.Errorf("duration must be between %v and %v", auctionMinDuration, auctionMaxDuration)
+	}
+	if req.StartingBid <= 0 {
+		return nil, fmt.Errorf("starting_bid must be positive")
+	}
+	if req.BuyoutPrice != 0 && req.BuyoutPrice < req.StartingBid {
+		return nil, fmt.Errorf("buyout_price must not be lower than starting_bid")
+	}
+	if ps.auctions.openCountBySeller(pet.ID) >= maxOpenAuctionsPerPet {
+		return nil, fmt.Errorf("同时最多上架%d件拍卖品", maxOpenAuctionsPerPet)
+	}
+
+	item, ok := pet.RemoveItem(req.ItemID, req.Quantity)
+	if !ok {
+		return nil, fmt.Errorf("%s 的背包里没有足够的物品: %s", pet.Name, req.ItemID)
+	}
+
+	now := time.Now()
+	auction := &models.Auction{
+		ID:          uuid.New().String(),
+		SellerID:    pet.ID,
+		SellerName:  pet.Name,
+		Item:        item,
+		StartingBid: req.StartingBid,
+		BuyoutPrice: req.BuyoutPrice,
+		Status:      models.AuctionOpen,
+		CreatedAt:   now,
+		EndsAt:      now.Add(req.Duration),
+	}
+	ps.auctions.open[auction.ID] = auction
+	ps.saveAuction(pet.ID, auction)
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventTransfer,
+		Message:   ps.narrate(pet, Narrative{Kind: NarrateAuctionListed, Item: item.Name, Quantity: item.Quantity, Coins: auction.StartingBid}),
+		Timestamp: now,
+		Data:      models.EventData{Items: []models.Item{item}},
+	})
+	ps.savePetToDatabase(pet)
+
+	result := *auction
+	return &result, nil
+}
+
+// PlaceBid 宠物对拍卖出价，出价金额会托管到拍卖结束；达到一口价时立即成交
+func (ps *PetService) PlaceBid(auctionID, petID string, amount int) (*models.Auction, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	bidder, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+
+	auction, exists := ps.auctions.open[auctionID]
+	if !exists {
+		stored, err := ps.auctions.repo.GetAuction(auctionID)
+		if err != nil {
+			return nil, err
+		}
+		if stored == nil {
+			return nil, ErrAuctionNotFound
+		}
+		return nil, ErrAuctionClosed
+	}
+
+	now := time.Now()
+	if !now.Before(auction.EndsAt) {
+		return nil, ErrAuctionClosed
+	}
+	if auction.SellerID == bidder.ID {
+		return nil, fmt.Errorf("不能对自己的拍卖出价")
+	}
+
+	buyout := auction.BuyoutPrice > 0 && amount >= auction.BuyoutPrice
+	if buyout {
+		amount = auction.BuyoutPrice
+	} else if minBid := auction.MinNextBid(); amount < minBid {
+		return nil, fmt.Errorf("出价过低（最低: %d）", minBid)
+	}
+
+	// 自己加价时先退回之前托管的金额
+	available := bidder.Coins
+	if auction.HighestBidderID == bidder.ID {
+		available += auction.CurrentBid
+	}
+	if available < amount {
+		return nil, fmt.Errorf("宠物金币不足（当前: %d，需要: %d）", available, amount)
+	}
+
+	escrow := models.EscrowAccount(auction.ID)
+	previousID, previousBid := auction.HighestBidderID, auction.CurrentBid
+	if previousID != "" {
+		if previous, exists := ps.pets[previousID]; exists {
+			ps.creditCoins(previous, previousBid, escrow, models.ReasonAuctionRefund, auction.ID)
+			if previous.ID != bidder.ID {
+				ps.savePetToDatabase(previous)
+				ps.notify(Notification{
+					Type:    NotificationAuctionOutbid,
+					PetID:   previous.ID,
+					Owner:   previous.Owner,
+					Message: fmt.Sprintf("%s 对%s的出价已被 %s 超过（%d金币）", previous.Name, auction.Item.Name, bidder.Name, amount),
+					Data: map[string]interface{}{
+						"auction_id":   auction.ID,
+						"item":         auction.Item,
+						"your_bid":     previousBid,
+						"current_bid":  amount,
+						"min_next_bid": auction.MinNextBid(),
+						"ends_at":      auction.EndsAt,
+					},
+				})
+			}
+		} else {
+			log.Printf("Warning: previous bidder %s of auction %s not found, refund skipped", previousID, auction.ID)
+		}
+	}
+
+	ps.debitCoins(bidder, amount, escrow, models.ReasonAuctionBid, auction.ID)
+	auction.CurrentBid = amount
+	auction.HighestBidderID = bidder.ID
+	auction.HighestBidderName = bidder.Name
+	auction.BidCount++
+
+	bid := &models.Bid{
+		ID:        uuid.New().String(),
+		AuctionID: auction.ID,
+		PetID:     bidder.ID,
+		PetName:   bidder.Name,
+		Amount:    amount,
+		Buyout:    buyout,
+		CreatedAt: now,
+	}
+	ps.ledger.addRecord(bidder.ID, database.ConvertToDBAuctionBid(bid))
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     bidder.ID,
+		PetName:   bidder.Name,
+		Type:      models.EventTransfer,
+		Message:   ps.narrate(bidder, Narrative{Kind: NarrateAuctionBid, Coins: amount, Item: auction.Item.Name}),
+		Timestamp: now,
+		Data:      models.EventData{Coins: -amount},
+	})
+
+	if buyout {
+		ps.settleAuction(auction, now)
+	} else {
+		ps.saveAuction(bidder.ID, auction)
+		ps.savePetToDatabase(bidder)
+	}
+
+	result := *auction
+	return &result, nil
+}
+
+// settleAuction 结算拍卖：成交则把托管金额扣除佣金后交给卖家、物品交给买家，流拍则退回物品，调用方需持有锁
+func (ps *PetService) settleAuction(auction *models.Auction, now time.Time) {
+	seller, exists := ps.pets[auction.SellerID]
+	if !exists {
+		log.Printf("Warning: seller %s of auction %s not found, settlement postponed", auction.SellerID, auction.ID)
+		return
+	}
+
+	var winner *models.Pet
+	if auction.HighestBidderID != "" {
+		winner, exists = ps.pets[auction.HighestBidderID]
+		if !exists {
+			log.Printf("Warning: winner %s of auction %s not found, settlement postponed", auction.HighestBidderID, auction.ID)
+			return
+		}
+	}
+
+	auction.SettledAt = &now
+	delete(ps.auctions.open, auction.ID)
+
+	if winner == nil {
+		auction.Status = models.AuctionExpired
+		seller.AddItem(auction.Item)
+		ps.saveAuction(seller.ID, auction)
+
+		message := ps.narrate(seller, Narrative{Kind: NarrateAuctionUnsold, Item: auction.Item.Name})
+		ps.addEvent(models.Event{
+			ID:        uuid.New().String(),
+			PetID:     seller.ID,
+			PetName:   seller.Name,
+			Type:      models.EventTransfer,
+			Message:   message,
+			Timestamp: now,
+			Data:      models.EventData{Items: []models.Item{auction.Item}},
+		})
+		ps.notify(Notification{Type: NotificationAuctionSettled, PetID: seller.ID, Owner: seller.Owner, Message: message, Data: auction})
+		ps.savePetToDatabase(seller)
+		return
+	}
+
+	escrow := models.EscrowAccount(auction.ID)
+	commission := auctionCommission(auction.CurrentBid)
+	auction.Status = models.AuctionSold
+	auction.Commission = commission
+
+	ps.creditCoins(seller, auction.CurrentBid-commission, escrow, models.ReasonAuctionSale, auction.ID)
+	ps.recordLedgerEntry(models.NewLedgerEntry(seller.ID, models.AccountTreasury, escrow, commission, models.ReasonAuctionCommission, auction.ID))
+	winner.AddItem(auction.Item)
+	ps.saveAuction(seller.ID, auction)
+
+	message := ps.narrate(seller, Narrative{Kind: NarrateAuctionSold, Item: auction.Item.Name, Quantity: auction.Item.Quantity,
+		Counterpart: winner.Name, Coins: auction.CurrentBid, Fee: commission})
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     seller.ID,
+		PetName:   seller.Name,
+		Type:      models.EventTransfer,
+		Message:   message,
+		Timestamp: now,
+		Data:      models.EventData{Coins: auction.CurrentBid - commission, FriendName: winner.Name, Items: []models.Item{auction.Item}},
+	})
+	ps.notify(Notification{Type: NotificationAuctionSettled, PetID: seller.ID, Owner: seller.Owner, Message: message, Data: auction})
+	ps.notify(Notification{Type: NotificationAuctionSettled, PetID: winner.ID, Owner: winner.Owner, Message: message, Data: auction})
+
+	ps.savePetToDatabase(seller)
+	ps.savePetToDatabase(winner)
+}
+
+// settleExpiredAuctions 结算所有已到期的拍卖，调用方需持有锁
+func (ps *PetService) settleExpiredAuctions(now time.Time) {
+	for _, auction := range ps.auctions.open {
+		if !now.Before(auction.EndsAt) {
+			ps.settleAuction(auction, now)
+		}
+	}
+}
+
+// runAuctionSettlement 定期结算到期拍卖，启动时先结算停机期间到期的拍卖
+func (ps *PetService) runAuctionSettlement() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		ps.mutex.Lock()
+		ps.settleExpiredAuctions(time.Now())
+		ps.mutex.Unlock()
+
+		select {
+		case <-ps.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetAuctions 分页获取拍卖列表，status 为空时返回全部
+func (ps *PetService) GetAuctions(status string, limit, offset int) (map[string]interface{}, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	auctions, total, err := ps.auctions.repo.GetAuctions(status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// 进行中的拍卖以内存为准，数据库中可能尚未写入最新出价
+	ps.mutex.RLock()
+	for i, auction := range auctions {
+		if current, exists := ps.auctions.open[auction.ID]; exists {
+			latest := *current
+			auctions[i] = &latest
+		}
+	}
+	ps.mutex.RUnlock()
+
+	return map[string]interface{}{
+		"auctions": auctions,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	}, nil
+}
+
+// GetAuction 获取拍卖详情及出价记录
+func (ps *PetService) GetAuction(auctionID string) (map[string]interface{}, error) {
+	ps.mutex.RLock()
+	current, isOpen := ps.auctions.open[auctionID]
+	var auction *models.Auction
+	if isOpen {
+		latest := *current
+		auction = &latest
+	}
+	ps.mutex.RUnlock()
+
+	if auction == nil {
+		stored, err := ps.auctions.repo.GetAuction(auctionID)
+		if err != nil {
+			return nil, err
+		}
+		if stored == nil {
+			return nil, ErrAuctionNotFound
+		}
+		auction = stored
+	}
+
+	bids, err := ps.auctions.repo.GetBids(auctionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"auction": auction,
+		"bids":    bids,
+	}
+	if auction.Status == models.AuctionOpen {
+		result["min_next_bid"] = auction.MinNextBid()
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+)
+
+// listTestItem 给卖家一件物品并上架拍卖
+func listTestItem(t *testing.T, ps *PetService, seller *models.Pet, startingBid, buyout int) (*models.Auction, models.Item) {
+	item := models.RareFinds[0]
+	ps.mutex.Lock()
+	seller.AddItem(item)
+	ps.mutex.Unlock()
+
+	auction, err := ps.CreateAuction(CreateAuctionRequest{
+		PetID:       seller.ID,
+		ItemID:      item.ID,
+		StartingBid: startingBid,
+		BuyoutPrice: buyout,
+		Duration:    time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create auction: %v", err)
+	}
+	if seller.FindItem(item.ID) != nil {
+		t.Fatalf("Expected listed item to leave the seller's inventory")
+	}
+	return auction, item
+}
+
+// TestAuctionEscrow 被超过的出价全额退回，一口价立即成交，成交价扣除佣金后归卖家，托管账户清零
+func TestAuctionEscrow(t *testing.T) {
+	ps := newTestPetService(t)
+	seller := newTestPet(t, ps, "seller", models.PersonalityCurious)
+	alice := newTestPet(t, ps, "alice", models.PersonalityGreedy)
+	bob := newTestPet(t, ps, "bob", models.PersonalityGreedy)
+	sellerCoins, aliceCoins, bobCoins := seller.Coins, alice.Coins, bob.Coins
+
+	auction, item := listTestItem(t, ps, seller, 20, 45)
+
+	if _, err := ps.PlaceBid(auction.ID, alice.ID, 20); err != nil {
+		t.Fatalf("Failed to bid: %v", err)
+	}
+	if _, err := ps.PlaceBid(auction.ID, bob.ID, 20); err == nil {
+		t.Error("Expected bid below the minimum increment to fail")
+	}
+	if _, err := ps.PlaceBid(auction.ID, bob.ID, 30); err != nil {
+		t.Fatalf("Failed to outbid: %v", err)
+	}
+	if alice.Coins != aliceCoins || bob.Coins != bobCoins-30 {
+		t.Errorf("Expected outbid bidder refunded, got alice %d bob %d", alice.Coins, bob.Coins)
+	}
+
+	// 出价超过一口价时按一口价成交
+	settled, err := ps.PlaceBid(auction.ID, alice.ID, 60)
+	if err != nil {
+		t.Fatalf("Failed to buy out: %v", err)
+	}
+	commission := auctionCommission(45)
+	if settled.Status != models.AuctionSold || settled.CurrentBid != 45 {
+		t.Fatalf("Expected auction sold at buyout price, got %s at %d", settled.Status, settled.CurrentBid)
+	}
+	if alice.Coins != aliceCoins-45 || bob.Coins != bobCoins || seller.Coins != sellerCoins+45-commission {
+		t.Errorf("Unexpected coins after settlement: alice %d bob %d seller %d", alice.Coins, bob.Coins, seller.Coins)
+	}
+	if alice.FindItem(item.ID) == nil {
+		t.Error("Expected buyer to receive the item")
+	}
+
+	assertReconciled(t, ps)
+	balances, err := ps.ledger.repo.GetAccountBalances(string(models.EscrowAccount("")))
+	if err != nil {
+		t.Fatalf("Failed to get escrow balances: %v", err)
+	}
+	if balance := balances[models.EscrowAccount(auction.ID)]; balance != 0 {
+		t.Errorf("Expected empty escrow after settlement, got %d", balance)
+	}
+	if ps.auctions.totalEscrow() != 0 {
+		t.Errorf("Expected no coins held in open auctions, got %d", ps.auctions.totalEscrow())
+	}
+}
+
+// TestAuctionExpiredWithoutBids 无人出价的拍卖到期后流拍，物品退回卖家
+func TestAuctionExpiredWithoutBids(t *testing.T) {
+	ps := newTestPetService(t)
+	seller := newTestPet(t, ps, "seller", models.PersonalityCurious)
+
+	auction, item := listTestItem(t, ps, seller, 20, 0)
+
+	ps.mutex.Lock()
+	ps.settleExpiredAuctions(auction.EndsAt.Add(-time.Second))
+	if _, open := ps.auctions.open[auction.ID]; !open {
+		t.Error("Expected auction to stay open before it ends")
+	}
+	ps.settleExpiredAuctions(auction.EndsAt)
+	ps.mutex.Unlock()
+
+	if _, open := ps.auctions.open[auction.ID]; open {
+		t.Fatal("Expected auction to be settled at its end time")
+	}
+	if seller.FindItem(item.ID) == nil {
+		t.Error("Expected unsold item back in the seller's inventory")
+	}
+
+	database.FlushBatchManagers()
+	stored, err := ps.auctions.repo.GetAuction(auction.ID)
+	if err != nil || stored == nil || stored.Status != models.AuctionExpired {
+		t.Errorf("Expected expired auction to be stored, got %+v (%v)", stored, err)
+	}
+}
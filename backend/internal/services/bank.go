@@ -0,0 +1,531 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// GameDayDuration 一个游戏日对应的现实时长
+	GameDayDuration = time.Hour
+
+	// bankLocation 银行所在地
+	bankLocation = "起始村庄"
+	// depositInterestRate 存款每个游戏日的利率（百分比）
+	depositInterestRate = 1
+	// loanInterestRate 贷款每个游戏日的利率（百分比）
+	loanInterestRate = 5
+	// loanTermDays 贷款期限（游戏日）
+	loanTermDays = 3
+	// loanLimitPerLevel 每一级可借的金币上限
+	loanLimitPerLevel = 100
+	// garnishRate 逾期贷款从每笔奖励中扣除的比例（百分比）
+	garnishRate = 50
+)
+
+// BankService 起始村庄银行，账户与贷款由 PetService 的主锁保护
+type BankService struct {
+	repo     *database.BankRepository
+	accounts map[string]*models.BankAccount
+	// 每只宠物同时最多一笔未还清的贷款
+	loans map[string]*models.Loan
+}
+
+// NewBankService 创建银行服务并加载已有账户和未还清的贷款
+func NewBankService() *BankService {
+	bs := &BankService{
+		repo:     database.NewBankRepository(),
+		accounts: make(map[string]*models.BankAccount),
+		loans:    make(map[string]*models.Loan),
+	}
+
+	accounts, err := bs.repo.GetAllAccounts()
+	if err != nil {
+		log.Printf("Warning: failed to load bank accounts from database: %v", err)
+		return bs
+	}
+	for _, account := range accounts {
+		bs.accounts[account.PetID] = account
+	}
+
+	loans, err := bs.repo.GetOpenLoans()
+	if err != nil {
+		log.Printf("Warning: failed to load loans from database: %v", err)
+		return bs
+	}
+	for _, loan := range loans {
+		bs.loans[loan.PetID] = loan
+	}
+
+	log.Printf("Loaded %d bank accounts and %d open loans from database", len(accounts), len(loans))
+	return bs
+}
+
+// account 获取宠物的银行账户，不存在则开户
+func (bs *BankService) account(petID string) *models.BankAccount {
+	account, exists := bs.accounts[petID]
+	if !exists {
+		now := time.Now()
+		account = &models.BankAccount{PetID: petID, LastInterestAt: now, UpdatedAt: now}
+		bs.accounts[petID] = account
+	}
+	return account
+}
+
+// balance 宠物的存款余额
+func (bs *BankService) balance(petID string) int {
+	if account, exists := bs.accounts[petID]; exists {
+		return account.Balance
+	}
+	return 0
+}
+
+// openLoan 宠物未还清的贷款
+func (bs *BankService) openLoan(petID string) *models.Loan {
+	return bs.loans[petID]
+}
+
+// totalDeposits 所有存款之和
+func (bs *BankService) totalDeposits() int64 {
+	var total int64
+	for _, account := range bs.accounts {
+		total += int64(account.Balance)
+	}
+	return total
+}
+
+// loanLimit 宠物当前可借的金币上限
+func loanLimit(pet *models.Pet) int {
+	return pet.Level * loanLimitPerLevel
+}
+
+// accrue 按经过的完整游戏日复利计算，返回增加的金额和计入的天数
+func accrue(amount, rate int, since, now time.Time) (int, int) {
+	days := int(now.Sub(since) / GameDayDuration)
+	if days <= 0 || amount <= 0 {
+		return 0, days
+	}
+
+	total := amount
+	for i := 0; i < days; i++ {
+		increase := total * rate / 100
+		if increase < 1 {
+			increase = 1
+		}
+		total += increase
+	}
+	return total - amount, days
+}
+
+func (ps *PetService) saveBankAccount(account *models.BankAccount) {
+	ps.ledger.addRecord(account.PetID, database.ConvertToDBBankAccount(account))
+}
+
+func (ps *PetService) saveLoan(loan *models.Loan) {
+	ps.ledger.addRecord(loan.PetID, database.ConvertToDBLoan(loan))
+}
+
+// bankDeposit 宠物把金币存入银行
+func (ps *PetService) bankDeposit(pet *models.Pet, amount int) (*models.BankAccount, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if pet.Coins < amount {
+		return nil, fmt.Errorf("宠物金币不足（当前: %d，需要: %d）", pet.Coins, amount)
+	}
+
+	account := ps.bank.account(pet.ID)
+	if account.Balance == 0 {
+		// 从存入时开始计息
+		account.LastInterestAt = time.Now()
+	}
+	ps.debitCoins(pet, amount, models.BankDepositAccount(pet.ID), models.ReasonBankDeposit, "银行存款")
+	account.Balance += amount
+	account.UpdatedAt = time.Now()
+	ps.saveBankAccount(account)
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventTransfer,
+		Message:   ps.narrate(pet, Narrative{Kind: NarrateBankDeposit, Location: bankLocation, Coins: amount, Balance: account.Balance}),
+		Timestamp: time.Now(),
+		Data:      models.EventData{Coins: -amount, Location: bankLocation},
+	})
+	ps.savePetToDatabase(pet)
+
+	result := *account
+	return &result, nil
+}
+
+// bankWithdraw 宠物从银行取出存款
+func (ps *PetService) bankWithdraw(pet *models.Pet, amount int) (*models.BankAccount, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	account := ps.bank.account(pet.ID)
+	if account.Balance < amount {
+		return nil, fmt.Errorf("存款余额不足（当前: %d，需要: %d）", account.Balance, amount)
+	}
+
+	ps.creditCoins(pet, amount, models.BankDepositAccount(pet.ID), models.ReasonBankWithdraw, "银行取款")
+	account.Balance -= amount
+	account.UpdatedAt = time.Now()
+	ps.saveBankAccount(account)
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventTransfer,
+		Message:   ps.narrate(pet, Narrative{Kind: NarrateBankWithdraw, Location: bankLocation, Coins: amount, Balance: account.Balance}),
+		Timestamp: time.Now(),
+		Data:      models.EventData{Coins: amount, Location: bankLocation},
+	})
+	ps.savePetToDatabase(pet)
+
+	result := *account
+	return &result, nil
+}
+
+// borrow 宠物以等级为额度向银行借款
+func (ps *PetService) borrow(pet *models.Pet, amount int) (*models.Loan, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if loan := ps.bank.openLoan(pet.ID); loan != nil {
+		return nil, fmt.Errorf("还有未还清的贷款（剩余: %d）", loan.Outstanding)
+	}
+	if limit := loanLimit(pet); amount > limit {
+		return nil, fmt.Errorf("超出贷款额度（%d级可借: %d）", pet.Level, limit)
+	}
+
+	now := time.Now()
+	loan := &models.Loan{
+		ID:            uuid.New().String(),
+		PetID:         pet.ID,
+		Principal:     amount,
+		Outstanding:   amount,
+		DailyRate:     loanInterestRate,
+		Status:        models.LoanActive,
+		IssuedAt:      now,
+		DueAt:         now.Add(loanTermDays * GameDayDuration),
+		LastAccruedAt: now,
+	}
+	ps.bank.loans[pet.ID] = loan
+	ps.creditCoins(pet, amount, models.AccountBank, models.ReasonLoan, loan.ID)
+	ps.saveLoan(loan)
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventTransfer,
+		Message:   ps.narrate(pet, Narrative{Kind: NarrateLoan, Location: bankLocation, Coins: amount, Days: loanTermDays}),
+		Timestamp: now,
+		Data:      models.EventData{Coins: amount, Location: bankLocation},
+	})
+	ps.savePetToDatabase(pet)
+
+	result := *loan
+	return &result, nil
+}
+
+// repayLoan 归还贷款，超出欠款的部分不会被扣除，返回实际还款金额
+func (ps *PetService) repayLoan(pet *models.Pet, amount int, reason models.LedgerReason) (*models.Loan, int, error) {
+	loan := ps.bank.openLoan(pet.ID)
+	if loan == nil {
+		return nil, 0, fmt.Errorf("没有需要归还的贷款")
+	}
+	if amount <= 0 {
+		return nil, 0, fmt.Errorf("amount must be positive")
+	}
+	if amount > loan.Outstanding {
+		amount = loan.Outstanding
+	}
+	if pet.Coins < amount {
+		return nil, 0, fmt.Errorf("宠物金币不足（当前: %d，需要: %d）", pet.Coins, amount)
+	}
+
+	ps.debitCoins(pet, amount, models.AccountBank, reason, loan.ID)
+	loan.Outstanding -= amount
+
+	narrative := Narrative{Kind: NarrateLoanRepaid, Coins: amount, Balance: loan.Outstanding}
+	if reason == models.ReasonLoanGarnish {
+		narrative.Kind = NarrateLoanGarnished
+	}
+	if loan.Outstanding == 0 {
+		repaidAt := time.Now()
+		loan.Status = models.LoanRepaid
+		loan.RepaidAt = &repaidAt
+		delete(ps.bank.loans, pet.ID)
+		narrative = Narrative{Kind: NarrateLoanCleared, Location: bankLocation}
+	}
+	ps.saveLoan(loan)
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventTransfer,
+		Message:   ps.narrate(pet, narrative),
+		Timestamp: time.Now(),
+		Data:      models.EventData{Coins: -amount},
+	})
+
+	result := *loan
+	return &result, amount, nil
+}
+
+// isGarnishable 逾期时需要扣款的奖励类型
+func isGarnishable(reason models.LedgerReason) bool {
+	switch reason {
+	case models.ReasonBattle, models.ReasonDiscovery, models.ReasonReward, models.ReasonRareFind, models.ReasonBossReward:
+		return true
+	}
+	return false
+}
+
+// garnishReward 宠物获得奖励后，如有逾期贷款则按比例扣款还贷
+func (ps *PetService) garnishReward(pet *models.Pet, reward int) {
+	loan := ps.bank.openLoan(pet.ID)
+	if loan == nil || loan.Status != models.LoanOverdue {
+		return
+	}
+
+	amount := reward * garnishRate / 100
+	if amount < 1 {
+		amount = 1
+	}
+	if _, _, err := ps.repayLoan(pet, amount, models.ReasonLoanGarnish); err != nil {
+		log.Printf("Failed to garnish reward for pet %s: %v", pet.ID, err)
+	}
+}
+
+// accrueBank 为存款结息、为贷款计息，并把到期未还的贷款标记为逾期，调用方需持有锁
+func (ps *PetService) accrueBank(now time.Time) {
+	touched := make(map[string]bool)
+
+	for petID, account := range ps.bank.accounts {
+		pet, exists := ps.pets[petID]
+		if !exists {
+			continue
+		}
+
+		interest, days := accrue(account.Balance, depositInterestRate, account.LastInterestAt, now)
+		if days <= 0 {
+			continue
+		}
+		account.LastInterestAt = account.LastInterestAt.Add(time.Duration(days) * GameDayDuration)
+		account.UpdatedAt = now
+		if interest > 0 {
+			account.Balance += interest
+			account.InterestEarned += interest
+			ps.recordLedgerEntry(models.NewLedgerEntry(pet.ID, models.BankDepositAccount(pet.ID), models.AccountMint,
+				interest, models.ReasonInterest, fmt.Sprintf("%d个游戏日利息", days)))
+
+			ps.addEvent(models.Event{
+				ID:        uuid.New().String(),
+				PetID:     pet.ID,
+				PetName:   pet.Name,
+				Type:      models.EventReward,
+				Message:   ps.narrate(pet, Narrative{Kind: NarrateInterest, Coins: interest, Balance: account.Balance}),
+				Timestamp: now,
+				Data:      models.EventData{Coins: interest, Location: bankLocation},
+			})
+		}
+		ps.saveBankAccount(account)
+		touched[petID] = true
+	}
+
+	for petID, loan := range ps.bank.loans {
+		pet, exists := ps.pets[petID]
+		if !exists {
+			continue
+		}
+
+		changed := false
+		interest, days := accrue(loan.Outstanding, loan.DailyRate, loan.LastAccruedAt, now)
+		if days > 0 {
+			loan.Outstanding += interest
+			if interest > 0 {
+				ps.recordLedgerEntry(models.NewLedgerEntry(pet.ID, models.LoanReceivableAccount(pet.ID), models.AccountTreasury,
+					interest, models.ReasonLoanInterest, fmt.Sprintf("%d个游戏日贷款利息", days)))
+			}
+			loan.LastAccruedAt = loan.LastAccruedAt.Add(time.Duration(days) * GameDayDuration)
+			changed = true
+		}
+
+		if loan.Status == models.LoanActive && now.After(loan.DueAt) {
+			loan.Status = models.LoanOverdue
+			changed = true
+
+			ps.addEvent(models.Event{
+				ID:        uuid.New().String(),
+				PetID:     pet.ID,
+				PetName:   pet.Name,
+				Type:      models.EventTransfer,
+				Message:   ps.narrate(pet, Narrative{Kind: NarrateLoanOverdue, Balance: loan.Outstanding, Percent: garnishRate}),
+				Timestamp: now,
+			})
+		}
+
+		if changed {
+			ps.saveLoan(loan)
+			touched[petID] = true
+		}
+	}
+
+	for petID := range touched {
+		ps.savePetToDatabase(ps.pets[petID])
+	}
+}
+
+// runBankScheduler 定期结息
+func (ps *PetService) runBankScheduler() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ps.quit:
+			return
+		case <-ticker.C:
+		}
+		ps.mutex.Lock()
+		ps.accrueBank(time.Now())
+		ps.mutex.Unlock()
+	}
+}
+
+// GetBankInfo 获取宠物的银行账户与贷款记录
+func (ps *PetService) GetBankInfo(petID string) (map[string]interface{}, error) {
+	ps.mutex.RLock()
+	pet, exists := ps.pets[petID]
+	if !exists {
+		ps.mutex.RUnlock()
+		return nil, ErrPetNotFound
+	}
+	info := ps.bankSummary(pet)
+	ps.mutex.RUnlock()
+
+	loans, err := ps.bank.repo.GetLoansByPet(petID, 20)
+	if err != nil {
+		return nil, err
+	}
+	info["pet_id"] = petID
+	info["loans"] = loans
+	return info, nil
+}
+
+// bankSummary 宠物的银行概况，调用方需持有锁
+func (ps *PetService) bankSummary(pet *models.Pet) map[string]interface{} {
+	summary := map[string]interface{}{
+		"deposit":               ps.bank.balance(pet.ID),
+		"deposit_interest_rate": depositInterestRate,
+		"loan_limit":            loanLimit(pet),
+		"loan_interest_rate":    loanInterestRate,
+		"game_day":              GameDayDuration.String(),
+	}
+	if account, exists := ps.bank.accounts[pet.ID]; exists {
+		summary["interest_earned"] = account.InterestEarned
+	}
+	if loan := ps.bank.openLoan(pet.ID); loan != nil {
+		current := *loan
+		summary["loan"] = &current
+	}
+	return summary
+}
+
+func commandAmount(params map[string]interface{}) int {
+	return paramInt(params, "amount")
+}
+
+func (ps *PetService) executeBankCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	summary := ps.bankSummary(pet)
+	summary["action"] = "bank"
+	summary["coins"] = pet.Coins
+	summary["message"] = fmt.Sprintf("%s 的存款: %d，可借额度: %d", pet.Name, ps.bank.balance(pet.ID), loanLimit(pet))
+	return summary, nil
+}
+
+func (ps *PetService) executeBankDepositCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	amount := commandAmount(params)
+	account, err := ps.bankDeposit(pet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":  "bank_deposit",
+		"amount":  amount,
+		"coins":   pet.Coins,
+		"deposit": account.Balance,
+		"message": fmt.Sprintf("%s 存入银行 %d 金币，存款余额: %d", pet.Name, amount, account.Balance),
+	}, nil
+}
+
+func (ps *PetService) executeBankWithdrawCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	amount := commandAmount(params)
+	account, err := ps.bankWithdraw(pet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":  "bank_withdraw",
+		"amount":  amount,
+		"coins":   pet.Coins,
+		"deposit": account.Balance,
+		"message": fmt.Sprintf("%s 从银行取出 %d 金币，当前金币: %d", pet.Name, amount, pet.Coins),
+	}, nil
+}
+
+func (ps *PetService) executeBorrowCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	amount := commandAmount(params)
+	loan, err := ps.borrow(pet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":  "borrow",
+		"amount":  amount,
+		"coins":   pet.Coins,
+		"loan":    loan,
+		"message": fmt.Sprintf("%s 借款 %d 金币，到期时间: %s", pet.Name, amount, loan.DueAt.Format("2006-01-02 15:04")),
+	}, nil
+}
+
+func (ps *PetService) executeRepayCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	amount := commandAmount(params)
+	if amount == 0 {
+		// 未指定金额时尽量还清
+		if loan := ps.bank.openLoan(pet.ID); loan != nil {
+			amount = loan.Outstanding
+			if amount > pet.Coins {
+				amount = pet.Coins
+			}
+		}
+	}
+
+	loan, paid, err := ps.repayLoan(pet, amount, models.ReasonLoanRepayment)
+	if err != nil {
+		return nil, err
+	}
+	ps.savePetToDatabase(pet)
+
+	return map[string]interface{}{
+		"action":      "repay",
+		"amount":      paid,
+		"coins":       pet.Coins,
+		"outstanding": loan.Outstanding,
+		"message":     fmt.Sprintf("%s 归还贷款 %d 金币，剩余欠款: %d", pet.Name, paid, loan.Outstanding),
+	}, nil
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+)
+
+// newTestPetService 在临时目录的数据库上创建服务，测试结束时先停止服务再关闭数据库
+func newTestPetService(t *testing.T) *PetService {
+	if err := database.InitializeAt(t.TempDir()); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	ps := NewPetService()
+	t.Cleanup(ps.Stop)
+	return ps
+}
+
+// newTestPet 创建指定性格的宠物
+func newTestPet(t *testing.T, ps *PetService, owner string, personality models.PetPersonality) *models.Pet {
+	pet, err := ps.CreatePetWithTraits(owner, models.TraitsFor(personality))
+	if err != nil {
+		t.Fatalf("Failed to create pet: %v", err)
+	}
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	return ps.pets[pet.ID]
+}
+
+// assertReconciled 刷新批量写入后宠物金币、钱包与账本余额一致
+func assertReconciled(t *testing.T, ps *PetService) {
+	t.Helper()
+	database.FlushBatchManagers()
+	report, err := ps.ReconcileLedger()
+	if err != nil {
+		t.Fatalf("Failed to reconcile ledger: %v", err)
+	}
+	if len(report.Mismatches) != 0 || len(report.WalletMismatches) != 0 {
+		t.Errorf("Expected no ledger mismatches, got %+v %+v", report.Mismatches, report.WalletMismatches)
+	}
+}
+
+// pendingAmount 宠物待落库分录中某种原因的金额合计
+func pendingAmount(ps *PetService, petID string, reason models.LedgerReason) int {
+	ps.ledger.mutex.Lock()
+	defer ps.ledger.mutex.Unlock()
+	total := 0
+	if pending, exists := ps.ledger.pending[petID]; exists {
+		for _, entry := range pending.Entries {
+			if entry.Reason == reason {
+				total += entry.Amount
+			}
+		}
+	}
+	return total
+}
+
+// TestBankLoanInterestAndGarnish 贷款按游戏日复利计息并记账，逾期后从奖励中扣款还贷
+func TestBankLoanInterestAndGarnish(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "borrower", models.PersonalityGreedy)
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if _, err := ps.borrow(pet, 100); err != nil {
+		t.Fatalf("Failed to borrow: %v", err)
+	}
+	loan := ps.bank.loans[pet.ID]
+	now := time.Now()
+	loan.LastAccruedAt = now.Add(-2 * GameDayDuration)
+	loan.DueAt = now.Add(-time.Minute)
+	ps.savePetToDatabase(pet)
+
+	// 100 -> 105 -> 110
+	ps.accrueBank(now)
+	if loan.Outstanding != 110 || loan.Status != models.LoanOverdue {
+		t.Fatalf("Expected overdue loan with 110 outstanding, got %d (%s)", loan.Outstanding, loan.Status)
+	}
+	database.FlushBatchManagers()
+	entries, _, err := ps.ledger.repo.GetEntriesByAccount(models.LoanReceivableAccount(pet.ID), 50, 0)
+	if err != nil {
+		t.Fatalf("Failed to get ledger entries: %v", err)
+	}
+	interest := 0
+	for _, entry := range entries {
+		if entry.Reason == models.ReasonLoanInterest {
+			interest += entry.Amount
+		}
+	}
+	if interest != 10 {
+		t.Errorf("Expected 10 coins of loan interest in the ledger, got %d", interest)
+	}
+
+	// 逾期后奖励的一半用于还款
+	coins := pet.Coins
+	ps.creditCoins(pet, 40, models.AccountMint, models.ReasonReward, "")
+	if loan.Outstanding != 90 || pet.Coins != coins+20 {
+		t.Errorf("Expected 20 coins garnished, got outstanding %d and coins %d -> %d", loan.Outstanding, coins, pet.Coins)
+	}
+	if garnished := pendingAmount(ps, pet.ID, models.ReasonLoanGarnish); garnished != 20 {
+		t.Errorf("Expected 20 coins recorded as garnish, got %d", garnished)
+	}
+	ps.savePetToDatabase(pet)
+
+	assertReconciled(t, ps)
+}
+
+// TestBankDepositInterest 存款按游戏日结息，AI 决定的存款在办理时才按手头金币计算金额
+func TestBankDepositInterest(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "saver", models.PersonalityCautious)
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.creditCoins(pet, 150, models.AccountMint, models.ReasonAddCoins, "")
+	action := &models.PetAction{Params: map[string]interface{}{"op": "deposit", "keep": foodReserve * 2}}
+	keep := pet.Coins - foodReserve*2
+	ps.completeBankAction(pet, action)
+	account := ps.bank.accounts[pet.ID]
+	if account == nil || account.Balance != keep || pet.Coins != foodReserve*2 {
+		t.Fatalf("Expected %d deposited keeping %d coins, got account %+v and %d coins", keep, foodReserve*2, account, pet.Coins)
+	}
+
+	now := time.Now()
+	account.LastInterestAt = now.Add(-3 * GameDayDuration)
+	expected, _ := accrue(keep, depositInterestRate, account.LastInterestAt, now)
+	ps.accrueBank(now)
+	if account.Balance != keep+expected || account.InterestEarned != expected {
+		t.Errorf("Expected %d interest, got balance %d earned %d", expected, account.Balance, account.InterestEarned)
+	}
+
+	assertReconciled(t, ps)
+}
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// bossDuration 首领停留的时长，超时未被击败则离开
+	bossDuration = 30 * time.Minute
+	// bossSpawnInterval 上一场首领战结束后到下一只首领出现的最短间隔，另加随机延迟
+	bossSpawnInterval = 2 * GameDayDuration
+	// bossEncounterChance 首领所在地点的宠物行动时遭遇首领的概率（百分比）
+	bossEncounterChance = 60
+	// bossLeaderboardSize 推送和展示的伤害排行榜人数
+	bossLeaderboardSize = 10
+	// bossFlushInterval 首领战状态的保存间隔
+	bossFlushInterval = 10 * time.Second
+
+	NotificationBossSpawned  = "boss_spawned"
+	NotificationBossUpdate   = "boss_update"
+	NotificationBossDefeated = "boss_defeated"
+	NotificationBossEscaped  = "boss_escaped"
+)
+
+var (
+	// ErrBossActive 已经有进行中的首领战
+	ErrBossActive = errors.New("a world boss is already active")
+	// ErrBossNotFound 首领设定不存在
+	ErrBossNotFound = errors.New("boss template not found")
+)
+
+// BossService 世界首领服务，首领和伤害贡献由 PetService 的主锁保护
+type BossService struct {
+	repo          *database.BossRepository
+	active        *models.WorldBoss
+	contributions map[string]*models.BossContribution
+	nextSpawnAt   time.Time
+	// 自上次保存以来有变化的贡献
+	dirty     map[string]bool
+	bossDirty bool
+}
+
+// BossUpdate 推送给客户端的首领血量与伤害排行
+type BossUpdate struct {
+	Boss        *models.WorldBoss         `json:"boss"`
+	Leaderboard []models.BossContribution `json:"leaderboard"`
+	LastHit     *BossHit                  `json:"last_hit,omitempty"`
+}
+
+// BossHit 一次攻击
+type BossHit struct {
+	PetID   string `json:"pet_id"`
+	PetName string `json:"pet_name"`
+	Damage  int    `json:"damage"`
+}
+
+// NewBossService 创建世界首领服务，恢复重启前进行中的首领战
+func NewBossService() *BossService {
+	bs := &BossService{
+		repo:          database.NewBossRepository(),
+		contributions: make(map[string]*models.BossContribution),
+		dirty:         make(map[string]bool),
+	}
+
+	now := time.Now()
+	bs.nextSpawnAt = now.Add(bossSpawnInterval)
+
+	boss, err := bs.repo.GetActiveBoss()
+	if err != nil {
+		log.Printf("Warning: failed to load world boss from database: %v", err)
+		return bs
+	}
+	if boss == nil {
+		if recent, err := bs.repo.GetRecentBosses(1); err == nil && len(recent) > 0 && recent[0].EndedAt != nil {
+			bs.nextSpawnAt = recent[0].EndedAt.Add(bossSpawnInterval)
+		}
+		return bs
+	}
+
+	contributions, err := bs.repo.GetContributions(boss.ID)
+	if err != nil {
+		log.Printf("Warning: failed to load boss contributions: %v", err)
+	}
+	bs.active = boss
+	for _, contribution := range contributions {
+		bs.contributions[contribution.PetID] = contribution
+	}
+
+	log.Printf("Resumed world boss %s at %s (HP %d/%d, %d contributors)",
+		boss.Name, boss.Location, boss.HP, boss.MaxHP, len(contributions))
+	return bs
+}
+
+// bossAt 返回地点上进行中的首领
+func (bs *BossService) bossAt(location string) *models.WorldBoss {
+	if bs.active != nil && bs.active.Status == models.BossActive && bs.active.Location == location {
+		return bs.active
+	}
+	return nil
+}
+
+// leaderboard 按伤害倒序排列的贡献，limit<=0 表示全部
+func (bs *BossService) leaderboard(limit int) []models.BossContribution {
+	board := make([]models.BossContribution, 0, len(bs.contributions))
+	for _, contribution := range bs.contributions {
+		board = append(board, *contribution)
+	}
+	sort.Slice(board, func(i, j int) bool {
+		if board[i].Damage != board[j].Damage {
+			return board[i].Damage > board[j].Damage
+		}
+		return board[i].PetName < board[j].PetName
+	})
+	if limit > 0 && len(board) > limit {
+		board = board[:limit]
+	}
+	return board
+}
+
+// update 当前首领的快照
+func (bs *BossService) update(hit *BossHit) BossUpdate {
+	boss := *bs.active
+	return BossUpdate{Boss: &boss, Leaderboard: bs.leaderboard(bossLeaderboardSize), LastHit: hit}
+}
+
+// takeDirty 取出需要保存的首领状态，没有变化时返回 nil
+func (bs *BossService) takeDirty() (*models.WorldBoss, []*models.BossContribution) {
+	if bs.active == nil || (!bs.bossDirty && len(bs.dirty) == 0) {
+		return nil, nil
+	}
+
+	boss := *bs.active
+	contributions := make([]*models.BossContribution, 0, len(bs.dirty))
+	for petID := range bs.dirty {
+		contribution := *bs.contributions[petID]
+		contributions = append(contributions, &contribution)
+	}
+	bs.dirty = make(map[string]bool)
+	bs.bossDirty = false
+	return &boss, contributions
+}
+
+// SpawnBoss 在指定地点召唤世界首领，location 为空时随机选择
+func (ps *PetService) SpawnBoss(templateID, location string) (*models.WorldBoss, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	boss, err := ps.spawnBoss(templateID, location, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	snapshot := *boss
+	return &snapshot, nil
+}
+
+// spawnBoss 召唤世界首领，调用方需持有锁
+func (ps *PetService) spawnBoss(templateID, location string, now time.Time) (*models.WorldBoss, error) {
+	if ps.boss.active != nil {
+		return nil, ErrBossActive
+	}
+
+	template := models.BossTemplates[rand.Intn(len(models.BossTemplates))]
+	if templateID != "" {
+		found, exists := models.FindBossTemplate(templateID)
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrBossNotFound, templateID)
+		}
+		template = found
+	}
+
+	if location == "" {
+		location = models.Locations[rand.Intn(len(models.Locations))]
+	} else if !isExploreLocation(location) {
+		return nil, fmt.Errorf("未知地点: %s", location)
+	}
+
+	boss := &models.WorldBoss{
+		ID:         uuid.New().String(),
+		TemplateID: template.ID,
+		Name:       template.Name,
+		Location:   location,
+		MaxHP:      template.MaxHP,
+		HP:         template.MaxHP,
+		Attack:     template.Attack,
+		Defense:    template.Defense,
+		RewardPool: template.RewardPool,
+		ExpPool:    template.ExpPool,
+		Status:     models.BossActive,
+		SpawnedAt:  now,
+		ExpiresAt:  now.Add(bossDuration),
+	}
+	ps.boss.active = boss
+	ps.boss.contributions = make(map[string]*models.BossContribution)
+	ps.boss.dirty = make(map[string]bool)
+	ps.boss.bossDirty = true
+
+	message := fmt.Sprintf("⚔️ 世界首领%s出现在%s！HP %d，%d分钟内击败它可按伤害瓜分%d金币",
+		boss.Name, boss.Location, boss.MaxHP, int(bossDuration/time.Minute), boss.RewardPool)
+	ps.notify(Notification{Type: NotificationBossSpawned, Message: message, Data: ps.boss.update(nil)})
+	log.Printf("World boss %s spawned at %s", boss.Name, boss.Location)
+
+	return boss, nil
+}
+
+func isExploreLocation(location string) bool {
+	for _, candidate := range models.Locations {
+		if candidate == location {
+			return true
+		}
+	}
+	return false
+}
+
+// bossDamage 宠物对首领造成的伤害
+func bossDamage(pet *models.Pet, boss *models.WorldBoss) int {
+	base := pet.Attack + pet.Level*2 - boss.Defense/2
+	if pet.Personality == models.PersonalityBrave {
+		base += base / 5
+	}
+	if base < 1 {
+		base = 1
+	}
+	return base*4/5 + rand.Intn(base*2/5+1)
+}
+
+// bossEncounter 宠物攻击所在地点的首领并承受反击，调用方需持有锁
+func (ps *PetService) bossEncounter(pet *models.Pet, boss *models.WorldBoss) models.Event {
+	now := time.Now()
+	damage := bossDamage(pet, boss)
+	if damage > boss.HP {
+		damage = boss.HP
+	}
+	boss.HP -= damage
+	ps.boss.bossDirty = true
+
+	contribution, exists := ps.boss.contributions[pet.ID]
+	if !exists {
+		contribution = &models.BossContribution{BossID: boss.ID, PetID: pet.ID, PetName: pet.Name, Owner: pet.Owner}
+		ps.boss.contributions[pet.ID] = contribution
+	}
+	contribution.Damage += damage
+	contribution.Hits++
+	ps.boss.dirty[pet.ID] = true
+
+	// 首领反击，宠物防御在 TakeDamage 中结算
+	before := pet.Health
+	pet.TakeDamage(boss.Attack + rand.Intn(boss.Attack/2+1))
+	taken := before - pet.Health
+	ps.stateManager.UpdateHP(pet.ID, pet.Health)
+	ps.stateManager.IncrementActionCount(pet.ID)
+
+	message := ps.narrate(pet, Narrative{Kind: NarrateBossHit, Enemy: boss.Name, Damage: damage, Taken: taken, BossHP: boss.HP, BossMaxHP: boss.MaxHP})
+	event := models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventBoss,
+		Message:   message,
+		Timestamp: now,
+		Data:      models.EventData{Location: boss.Location, Enemy: boss.Name, Damage: damage},
+	}
+
+	hit := &BossHit{PetID: pet.ID, PetName: pet.Name, Damage: damage}
+	ps.notify(Notification{Type: NotificationBossUpdate, PetID: pet.ID, Owner: pet.Owner, Message: event.Message, Data: ps.boss.update(hit)})
+
+	if boss.HP <= 0 {
+		event.Data.IsVictory = true
+		event.Message = ps.narrate(pet, Narrative{Kind: NarrateBossFinalBlow, Enemy: boss.Name, Damage: damage})
+		ps.defeatBoss(pet, now)
+	}
+	return event
+}
+
+// defeatBoss 首领被击败，按伤害比例分配金币、经验，伤害最高者额外获得稀有物品，调用方需持有锁
+func (ps *PetService) defeatBoss(killer *models.Pet, now time.Time) {
+	boss := ps.boss.active
+	boss.Status = models.BossDefeated
+	boss.EndedAt = &now
+
+	board := ps.boss.leaderboard(0)
+	totalDamage := 0
+	for _, contribution := range board {
+		totalDamage += contribution.Damage
+	}
+
+	for i, entry := range board {
+		pet, exists := ps.pets[entry.PetID]
+		contribution := ps.boss.contributions[entry.PetID]
+		if !exists || totalDamage == 0 {
+			continue
+		}
+
+		coins := boss.RewardPool * entry.Damage / totalDamage
+		exp := boss.ExpPool * entry.Damage / totalDamage
+		contribution.Reward = coins
+		ps.creditCoins(pet, coins, models.AccountMint, models.ReasonBossReward, boss.ID)
+		pet.GainExperience(exp)
+
+		narrative := Narrative{Kind: NarrateBossReward, Enemy: boss.Name, Damage: entry.Damage, Rank: i + 1, Coins: coins, Experience: exp}
+		data := models.EventData{Location: boss.Location, Enemy: boss.Name, Damage: entry.Damage, Coins: coins, Experience: exp, IsVictory: true}
+		if i == 0 {
+			treasure := models.RareFinds[rand.Intn(len(models.RareFinds))]
+			pet.AddItem(treasure)
+			data.RareItem = treasure.Name
+			data.Items = []models.Item{treasure}
+			narrative.Kind = NarrateBossTopReward
+			narrative.Item = treasure.Name
+		}
+
+		ps.addEvent(models.Event{
+			ID:        uuid.New().String(),
+			PetID:     pet.ID,
+			PetName:   pet.Name,
+			Type:      models.EventBoss,
+			Message:   ps.narrate(pet, narrative),
+			Timestamp: now,
+			Data:      data,
+		})
+		if pet != killer {
+			ps.savePetToDatabase(pet)
+		}
+	}
+
+	// 首领终局状态随击杀者一起写入，避免重启后复活
+	ps.ledger.addRecord(killer.ID, database.ConvertToDBWorldBoss(boss))
+	for _, contribution := range ps.boss.contributions {
+		ps.ledger.addRecord(killer.ID, database.ConvertToDBBossContribution(contribution))
+	}
+
+	message := fmt.Sprintf("🏆 世界首领%s在%s被击败！%d只宠物参与讨伐，最后一击: %s",
+		boss.Name, boss.Location, len(board), killer.Name)
+	ps.notify(Notification{Type: NotificationBossDefeated, PetID: killer.ID, Owner: killer.Owner, Message: message, Data: ps.boss.update(nil)})
+	log.Printf("World boss %s defeated by %s", boss.Name, killer.Name)
+
+	ps.endBoss(now)
+}
+
+// escapeBoss 首领超时离开，参与者没有奖励，调用方需持有锁
+func (ps *PetService) escapeBoss(now time.Time) {
+	boss := ps.boss.active
+	boss.Status = models.BossEscaped
+	boss.EndedAt = &now
+	ps.boss.bossDirty = true
+
+	message := fmt.Sprintf("世界首领%s带着%d/%d HP离开了%s", boss.Name, boss.HP, boss.MaxHP, boss.Location)
+	ps.notify(Notification{Type: NotificationBossEscaped, Message: message, Data: ps.boss.update(nil)})
+	log.Printf("World boss %s escaped from %s", boss.Name, boss.Location)
+
+	boss, contributions := ps.boss.takeDirty()
+	if err := ps.boss.repo.SaveBossState(boss, contributions); err != nil {
+		log.Printf("Warning: failed to save world boss: %v", err)
+	}
+	ps.endBoss(now)
+}
+
+// endBoss 清理已结束的首领战并安排下一只首领
+func (ps *PetService) endBoss(now time.Time) {
+	ps.boss.active = nil
+	ps.boss.contributions = make(map[string]*models.BossContribution)
+	ps.boss.dirty = make(map[string]bool)
+	ps.boss.bossDirty = false
+	ps.boss.nextSpawnAt = now.Add(bossSpawnInterval + time.Duration(rand.Int63n(int64(GameDayDuration))))
+}
+
+// runBossScheduler 定期召唤首领、处理超时并保存战况
+func (ps *PetService) runBossScheduler() {
+	ticker := time.NewTicker(bossFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ps.quit:
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+
+		ps.mutex.Lock()
+		if ps.boss.active == nil && !now.Before(ps.boss.nextSpawnAt) {
+			if _, err := ps.spawnBoss("", "", now); err != nil {
+				log.Printf("Warning: failed to spawn world boss: %v", err)
+			}
+		}
+		if ps.boss.active != nil && !now.Before(ps.boss.active.ExpiresAt) {
+			ps.escapeBoss(now)
+		}
+		boss, contributions := ps.boss.takeDirty()
+		ps.mutex.Unlock()
+
+		if boss == nil {
+			continue
+		}
+		if err := ps.boss.repo.SaveBossState(boss, contributions); err != nil {
+			log.Printf("Warning: failed to save world boss: %v", err)
+		}
+	}
+}
+
+// GetWorldBoss 获取当前首领战和完整伤害排行，以及最近的首领战记录
+func (ps *PetService) GetWorldBoss() (map[string]interface{}, error) {
+	ps.mutex.RLock()
+	result := map[string]interface{}{
+		"boss":          nil,
+		"leaderboard":   []models.BossContribution{},
+		"next_spawn_at": ps.boss.nextSpawnAt,
+		"timestamp":     time.Now(),
+	}
+	if ps.boss.active != nil {
+		update := ps.boss.update(nil)
+		result["boss"] = update.Boss
+		result["leaderboard"] = ps.boss.leaderboard(0)
+		delete(result, "next_spawn_at")
+	}
+	ps.mutex.RUnlock()
+
+	recent, err := ps.boss.repo.GetRecentBosses(5)
+	if err != nil {
+		return nil, err
+	}
+	result["recent"] = recent
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// TestBossRaid 同一时间只有一只首领，击败后按伤害比例瓜分奖励池，伤害最高者额外获得稀有物品
+func TestBossRaid(t *testing.T) {
+	ps := newTestPetService(t)
+	alice := newTestPet(t, ps, "alice", models.PersonalityGreedy)
+	bob := newTestPet(t, ps, "bob", models.PersonalityGreedy)
+
+	if _, err := ps.SpawnBoss("unknown", "北方森林"); !errors.Is(err, ErrBossNotFound) {
+		t.Errorf("Expected unknown boss error, got %v", err)
+	}
+	boss, err := ps.SpawnBoss("crystal_golem", "北方森林")
+	if err != nil {
+		t.Fatalf("Failed to spawn boss: %v", err)
+	}
+	if _, err := ps.SpawnBoss("", ""); !errors.Is(err, ErrBossActive) {
+		t.Errorf("Expected second boss to be rejected, got %v", err)
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	active := ps.boss.bossAt(boss.Location)
+	if active == nil {
+		t.Fatal("Expected boss active at its location")
+	}
+	alice.Location = boss.Location
+	ps.bossEncounter(alice, active)
+	hit := ps.boss.contributions[alice.ID]
+	if hit == nil || hit.Hits != 1 || active.HP != active.MaxHP-hit.Damage {
+		t.Fatalf("Expected the hit recorded against the boss, got %+v (HP %d)", hit, active.HP)
+	}
+
+	// 固定伤害让奖励分配可预期：3:1 瓜分 3000 金币
+	hit.Damage = 300
+	ps.boss.contributions[bob.ID] = &models.BossContribution{BossID: boss.ID, PetID: bob.ID, PetName: bob.Name, Owner: bob.Owner, Damage: 100, Hits: 1}
+	aliceCoins, bobCoins := alice.Coins, bob.Coins
+	aliceItems, bobItems := len(alice.Inventory), len(bob.Inventory)
+
+	ps.defeatBoss(alice, time.Now())
+	ps.savePetToDatabase(alice)
+
+	if alice.Coins != aliceCoins+2250 || bob.Coins != bobCoins+750 {
+		t.Errorf("Expected rewards 2250/750, got %d/%d", alice.Coins-aliceCoins, bob.Coins-bobCoins)
+	}
+	if len(alice.Inventory) != aliceItems+1 || len(bob.Inventory) != bobItems {
+		t.Error("Expected only the top damage dealer to receive a rare item")
+	}
+	if ps.boss.active != nil {
+		t.Error("Expected boss fight to end after defeat")
+	}
+
+	assertReconciled(t, ps)
+	stored, err := ps.boss.repo.GetRecentBosses(1)
+	if err != nil || len(stored) != 1 || stored[0].Status != models.BossDefeated {
+		t.Errorf("Expected defeated boss to be stored, got %+v (%v)", stored, err)
+	}
+	if active, err := ps.boss.repo.GetActiveBoss(); err != nil || active != nil {
+		t.Errorf("Expected no active boss after restart, got %+v (%v)", active, err)
+	}
+}
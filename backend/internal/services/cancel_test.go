@@ -0,0 +1,108 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// rewindAction 把宠物进行中的行动提前到已完成 progress 的位置，调用方需持有锁
+func rewindAction(t *testing.T, ps *PetService, pet *models.Pet, progress float64) *models.PetAction {
+	t.Helper()
+	action, exists := ps.actions.inflight[pet.ID]
+	if !exists {
+		t.Fatalf("Expected an action in flight, got status %s", pet.Status)
+	}
+	action.StartedAt = time.Now().Add(-time.Duration(progress * float64(action.Duration) * float64(time.Second)))
+	return action
+}
+
+// TestCancelCraftingReturnsMaterials 中途取消制作按剩余进度退回材料，工本费不退
+func TestCancelCraftingReturnsMaterials(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "crafter", models.PersonalityCurious)
+	recipe, _ := models.FindRecipe("energy_crystal")
+
+	ps.mutex.Lock()
+	giveMaterials(pet, recipe)
+	ps.creditCoins(pet, 50, models.AccountMint, models.ReasonAddCoins, "")
+	ps.mutex.Unlock()
+
+	if _, err := ps.ExecuteCommand(pet.ID, "craft", map[string]interface{}{"recipe": recipe.ID}); err != nil {
+		t.Fatalf("Failed to start crafting: %v", err)
+	}
+
+	ps.mutex.Lock()
+	coins := pet.Coins
+	rewindAction(t, ps, pet, 0.25)
+	ps.mutex.Unlock()
+
+	if _, err := ps.ExecuteCommand(pet.ID, "cancel", nil); err != nil {
+		t.Fatalf("Failed to cancel crafting: %v", err)
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	// 2 个水晶做了四分之一，退回 1 个
+	crystal := pet.FindItem(recipe.Inputs[0].ItemID)
+	if crystal == nil || crystal.Quantity != 1 {
+		t.Errorf("Expected the unused material returned, got %+v", crystal)
+	}
+	if pet.FindItem(recipe.Output.ID) != nil {
+		t.Error("Expected no output from a cancelled craft")
+	}
+	if pet.Coins != coins || pet.Status != models.StatusIdle {
+		t.Errorf("Expected idle pet keeping %d coins, got %s with %d", coins, pet.Status, pet.Coins)
+	}
+	if _, inflight := ps.actions.inflight[pet.ID]; inflight {
+		t.Error("Expected cancelled action to leave the tracker")
+	}
+	if _, _, _, err := ps.cancelAction(pet); err == nil {
+		t.Error("Expected nothing left to cancel")
+	}
+	assertReconciled(t, ps)
+}
+
+// TestRecallExploration 召回过半的探索带回部分收获，宠物回到起始村庄并放弃整个计划
+func TestRecallExploration(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "explorer", models.PersonalityCurious)
+
+	ps.mutex.Lock()
+	pet.Location = "北方森林"
+	ps.beginAction(pet, ActionExplore, models.StatusExploring, "", map[string]interface{}{"location": pet.Location}, 100)
+	steps, err := ParseActionPlan("rest → socialize")
+	if err != nil {
+		t.Fatalf("Failed to parse plan: %v", err)
+	}
+	if err := ps.enqueueActions(pet, steps); err != nil {
+		t.Fatalf("Failed to queue plan: %v", err)
+	}
+	rewindAction(t, ps, pet, 0.6)
+	coins, experience := pet.Coins, pet.Experience
+	ps.mutex.Unlock()
+
+	result, err := ps.ExecuteCommand(pet.ID, "recall", nil)
+	if err != nil {
+		t.Fatalf("Failed to recall: %v", err)
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if progress := result.(map[string]interface{})["progress"].(float64); progress < 0.5 {
+		t.Errorf("Expected recall after half of the exploration, got progress %.2f", progress)
+	}
+	if pet.Location != bankLocation || pet.Status != models.StatusIdle {
+		t.Errorf("Expected idle pet back at %s, got %s at %s", bankLocation, pet.Status, pet.Location)
+	}
+	if len(pet.ActionQueue) != 0 {
+		t.Errorf("Expected recall to clear the plan, got %d steps", len(pet.ActionQueue))
+	}
+	if pet.Coins <= coins || pet.Experience <= experience && pet.Level == 1 {
+		t.Errorf("Expected partial rewards, got coins %d -> %d and experience %d -> %d", coins, pet.Coins, experience, pet.Experience)
+	}
+	assertReconciled(t, ps)
+}
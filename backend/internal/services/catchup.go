@@ -0,0 +1,281 @@
+package services
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"time"
+
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// catchUpMinGap 短于该时长的停机不做离线结算
+	catchUpMinGap = 2 * time.Minute
+	// catchUpMaxWindow 离线结算最多覆盖的时长
+	catchUpMaxWindow = 12 * time.Hour
+	// catchUpStep 离线模拟的步长，每步对应一次行动决策
+	catchUpStep = time.Minute
+	// catchUpTicksPerStep 每步相当于 runGlobalAI 的属性衰减次数
+	catchUpTicksPerStep = 2
+)
+
+// CatchUpSummary 离线期间的活动汇总
+type CatchUpSummary struct {
+	Elapsed      time.Duration
+	Simulated    time.Duration
+	Explorations int
+	Victories    int
+	Defeats      int
+	Discoveries  int
+	Meals        int
+	Rests        int
+	Experience   int
+	Damage       int
+	LevelsGained int
+	Items        []models.Item
+	// 按来源汇总的收入，结算时每种来源记一笔账
+	Income   map[models.LedgerReason]int
+	FoodCost int
+	Starving bool
+}
+
+// addItem 汇总获得的物品，同一物品合并数量
+func (s *CatchUpSummary) addItem(item models.Item) {
+	for i := range s.Items {
+		if s.Items[i].ID == item.ID {
+			s.Items[i].Quantity += item.Quantity
+			return
+		}
+	}
+	s.Items = append(s.Items, item)
+}
+
+// catchUpSeed 由宠物和离线起点决定的随机种子，同样的输入总是得到同样的结果
+func catchUpSeed(pet *models.Pet, since time.Time) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(pet.ID))
+	return int64(hash.Sum64()) ^ since.UnixNano()
+}
+
+// simulateOffline 按简化的规则快进宠物在 [since, now) 期间的属性衰减和活动，
+// 金币变化只汇总不入账。离线期间不会死亡，也不计世界资源、首领和世界事件
+func simulateOffline(pet *models.Pet, since, now time.Time) *CatchUpSummary {
+	summary := &CatchUpSummary{
+		Elapsed: now.Sub(since),
+		Income:  make(map[models.LedgerReason]int),
+	}
+	if summary.Elapsed > catchUpMaxWindow {
+		since = now.Add(-catchUpMaxWindow)
+	}
+	summary.Simulated = now.Sub(since)
+
+	rng := rand.New(rand.NewSource(catchUpSeed(pet, since)))
+	coins := pet.Coins
+
+	for t := since; !t.Add(catchUpStep).After(now); t = t.Add(catchUpStep) {
+		exploring := false
+
+		switch {
+		case pet.Hunger < 40:
+			summary.Meals++
+			if food := pet.FindConsumable("hunger"); food != nil {
+				pet.UseItem(food.ID)
+			} else if cost := 10 + rng.Intn(10); coins >= cost {
+				coins -= cost
+				summary.FoodCost += cost
+				pet.Feed(25 + rng.Intn(20))
+			} else {
+				pet.Feed(10 + rng.Intn(15))
+			}
+
+		case pet.Energy < 30 || pet.Health*2 < pet.MaxHealth:
+			summary.Rests++
+			pet.RestoreEnergy(20 + rng.Intn(20))
+			pet.Heal(10)
+
+		case rng.Intn(100) < 70:
+			exploring = true
+			summary.Explorations++
+			pet.Location = models.Locations[rng.Intn(len(models.Locations))]
+			coins += simulateOfflineEncounter(pet, conditionsAt(pet.Location, t), rng, summary)
+
+		default:
+			pet.IncreaseSocial(5)
+		}
+
+		for tick := 0; tick < catchUpTicksPerStep; tick++ {
+			if exploring {
+				pet.ConsumeEnergy(5)
+				pet.ConsumeHunger(5)
+			} else {
+				pet.ConsumeEnergy(2)
+				pet.ConsumeHunger(3)
+			}
+			pet.DecreaseSocial(1)
+
+			if pet.Hunger < 20 {
+				summary.Starving = true
+				offlineDamage(pet, 5, summary)
+			}
+		}
+	}
+
+	return summary
+}
+
+// simulateOfflineEncounter 离线探索时的一次遭遇，返回获得的金币
+func simulateOfflineEncounter(pet *models.Pet, conditions models.Conditions, rng *rand.Rand, summary *CatchUpSummary) int {
+	switch rollEncounter(conditions.EncounterWeights(), rng.Intn) {
+	case models.EventBattle:
+		monster := models.Monsters[rng.Intn(len(models.Monsters))]
+		if conditions.Night() && rng.Intn(2) == 0 {
+			monster = models.NightMonsters[rng.Intn(len(models.NightMonsters))]
+		}
+		if !battleWins(pet, monster, rng.Intn) {
+			summary.Defeats++
+			damage := monster.Attack - pet.Defense
+			if damage < 1 {
+				damage = 1
+			}
+			offlineDamage(pet, damage, summary)
+			return 0
+		}
+		summary.Victories++
+		summary.Experience += monster.ExpReward
+		if pet.GainExperience(monster.ExpReward) {
+			summary.LevelsGained++
+		}
+		summary.Income[models.ReasonBattle] += monster.CoinReward
+		return monster.CoinReward
+
+	case models.EventDiscovery:
+		summary.Discoveries++
+		coins := rng.Intn(20) + 5
+		material := models.Materials[rng.Intn(len(models.Materials))]
+		pet.AddItem(material)
+		summary.addItem(material)
+		summary.Income[models.ReasonDiscovery] += coins
+		return coins
+
+	case models.EventReward:
+		coins := rng.Intn(50) + 10
+		summary.Income[models.ReasonReward] += coins
+		return coins
+
+	case models.EventSocial:
+		pet.IncreaseSocial(10)
+	}
+	return 0
+}
+
+// offlineDamage 离线期间受到伤害，至少保留1点生命
+func offlineDamage(pet *models.Pet, damage int, summary *CatchUpSummary) {
+	before := pet.Health
+	pet.TakeDamage(damage)
+	if pet.Health < 1 {
+		pet.Health = 1
+	}
+	summary.Damage += before - pet.Health
+}
+
+// formatElapsed 形如 "3小时25分钟"
+func formatElapsed(d time.Duration) string {
+	hours := int(d / time.Hour)
+	minutes := int(d%time.Hour) / int(time.Minute)
+	if hours == 0 {
+		return fmt.Sprintf("%d分钟", minutes)
+	}
+	return fmt.Sprintf("%d小时%d分钟", hours, minutes)
+}
+
+// digestMessage 离线摘要的文字，调用方需持有锁
+func (ps *PetService) digestMessage(pet *models.Pet, summary *CatchUpSummary, netCoins int) string {
+	parts := []string{
+		fmt.Sprintf("探索%d次（战斗%d胜%d负，发现%d次）", summary.Explorations, summary.Victories, summary.Defeats, summary.Discoveries),
+		fmt.Sprintf("金币%+d、经验+%d", netCoins, summary.Experience),
+	}
+	if summary.Meals > 0 || summary.Rests > 0 {
+		parts = append(parts, fmt.Sprintf("进食%d次、休息%d次", summary.Meals, summary.Rests))
+	}
+	if len(summary.Items) > 0 {
+		names := make([]string, len(summary.Items))
+		for i, item := range summary.Items {
+			names[i] = fmt.Sprintf("%sx%d", item.Name, item.Quantity)
+		}
+		parts = append(parts, "收集了"+strings.Join(names, "、"))
+	}
+	if summary.Damage > 0 {
+		parts = append(parts, fmt.Sprintf("累计受伤%d点", summary.Damage))
+	}
+	if summary.LevelsGained > 0 {
+		parts = append(parts, fmt.Sprintf("升到了%d级", pet.Level))
+	}
+	if summary.Starving {
+		parts = append(parts, "还饿过肚子")
+	}
+
+	message := ps.narrate(pet, Narrative{Kind: NarrateDigest, Elapsed: formatElapsed(summary.Elapsed), Summary: strings.Join(parts, "，")})
+	if summary.Simulated < summary.Elapsed {
+		message += fmt.Sprintf("（只结算了最近%s）", formatElapsed(summary.Simulated))
+	}
+	return message
+}
+
+// catchUpPet 服务器停机后为没有进行中行动的宠物补算离线进度，结果合并为一条摘要事件，调用方需持有锁
+func (ps *PetService) catchUpPet(pet *models.Pet, now time.Time) *CatchUpSummary {
+	// 升级前的数据没有模拟时刻，从最后活动时间算起
+	since := pet.SimulatedAt
+	if since.IsZero() {
+		since = pet.LastActivity
+	}
+	if !pet.IsAlive() || since.IsZero() || now.Sub(since) < catchUpMinGap {
+		return nil
+	}
+
+	startLevel := pet.Level
+	startCoins := pet.Coins
+	summary := simulateOffline(pet, since, now)
+
+	// 收入按来源各记一笔，再扣除伙食费
+	for _, reason := range []models.LedgerReason{models.ReasonBattle, models.ReasonDiscovery, models.ReasonReward} {
+		if amount := summary.Income[reason]; amount > 0 {
+			ps.creditCoins(pet, amount, models.AccountMint, reason, "离线收益")
+		}
+	}
+	// 逾期贷款可能已从收入中扣款，伙食费不超过现有余额
+	if foodCost := min(summary.FoodCost, pet.Coins); foodCost > 0 {
+		ps.debitCoins(pet, foodCost, models.AccountShop, models.ReasonFood, "离线伙食费")
+	}
+	// 以实际到手的金币为准，扣去还贷后可能少于离线收益
+	netCoins := pet.Coins - startCoins
+
+	// 调用方已确认没有进行中的行动，离线期间宠物一直处于空闲
+	pet.Status = models.StatusIdle
+	pet.SimulatedAt = now
+
+	event := models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventDigest,
+		Message:   ps.digestMessage(pet, summary, netCoins),
+		Timestamp: now,
+		Data: models.EventData{
+			Location:   pet.Location,
+			Experience: summary.Experience,
+			Coins:      netCoins,
+			Items:      summary.Items,
+			Damage:     summary.Damage,
+		},
+	}
+	if pet.Level > startLevel {
+		event.Data.NewLevel = pet.Level
+	}
+	ps.addEvent(event)
+	ps.savePetToDatabase(pet)
+
+	return summary
+}
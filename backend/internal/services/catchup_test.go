@@ -0,0 +1,122 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// clonePet 复制宠物用于模拟，背包单独复制以免相互影响
+func clonePet(pet *models.Pet) *models.Pet {
+	clone := *pet
+	clone.Inventory = append([]models.Item(nil), pet.Inventory...)
+	return &clone
+}
+
+// TestSimulateOfflineDeterministic 同一宠物从同一时刻开始的离线模拟总是得到同样的结果，且最多结算12小时
+func TestSimulateOfflineDeterministic(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "sleeper", models.PersonalityCurious)
+
+	ps.mutex.Lock()
+	original := clonePet(pet)
+	ps.mutex.Unlock()
+
+	now := time.Now()
+	since := now.Add(-3 * time.Hour)
+	first, second := clonePet(original), clonePet(original)
+	a := simulateOffline(first, since, now)
+	b := simulateOffline(second, since, now)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Expected identical summaries, got %+v and %+v", a, b)
+	}
+	if first.Level != second.Level || first.Experience != second.Experience || first.Health != second.Health ||
+		first.Hunger != second.Hunger || first.Energy != second.Energy || first.Location != second.Location {
+		t.Errorf("Expected identical pets after simulation, got %+v and %+v", first, second)
+	}
+	if a.Explorations+a.Meals+a.Rests == 0 {
+		t.Error("Expected some offline activity in three hours")
+	}
+	if first.Health < 1 {
+		t.Error("Expected pets to survive offline simulation")
+	}
+
+	long := simulateOffline(clonePet(original), now.Add(-2*catchUpMaxWindow), now)
+	if long.Simulated != catchUpMaxWindow || long.Elapsed != 2*catchUpMaxWindow {
+		t.Errorf("Expected simulation capped at %v, got %v of %v", catchUpMaxWindow, long.Simulated, long.Elapsed)
+	}
+}
+
+// TestCatchUpPet 离线收益和伙食费记入账本，并从结算时刻继续模拟，最后活动时间不变
+func TestCatchUpPet(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "sleeper", models.PersonalityGreedy)
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	now := time.Now()
+	lastActivity := pet.LastActivity
+	pet.SimulatedAt = now.Add(-time.Minute)
+	if summary := ps.catchUpPet(pet, now); summary != nil {
+		t.Errorf("Expected no catch-up for a short gap, got %+v", summary)
+	}
+
+	pet.SimulatedAt = now.Add(-3 * time.Hour)
+	expected := simulateOffline(clonePet(pet), pet.SimulatedAt, now)
+	coins := pet.Coins
+	summary := ps.catchUpPet(pet, now)
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("Expected catch-up to match the simulation, got %+v and %+v", summary, expected)
+	}
+
+	income := 0
+	for _, amount := range summary.Income {
+		income += amount
+	}
+	if pet.Coins != max(coins+income-summary.FoodCost, 0) {
+		t.Errorf("Expected coins %d + %d - %d, got %d", coins, income, summary.FoodCost, pet.Coins)
+	}
+	if !pet.SimulatedAt.Equal(now) || !pet.LastActivity.Equal(lastActivity) || pet.Status != models.StatusIdle {
+		t.Errorf("Expected idle pet simulated to %v, got %s simulated to %v (last activity %v)", now, pet.Status, pet.SimulatedAt, pet.LastActivity)
+	}
+
+	assertReconciled(t, ps)
+}
+
+// TestCatchUpDigestAfterGarnish 逾期贷款从离线收益中扣款时，摘要报告实际到手的金币
+func TestCatchUpDigestAfterGarnish(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "debtor", models.PersonalityGreedy)
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if _, err := ps.borrow(pet, 100); err != nil {
+		t.Fatalf("Failed to borrow: %v", err)
+	}
+	now := time.Now()
+	loan := ps.bank.loans[pet.ID]
+	loan.DueAt = now.Add(-4 * time.Hour)
+	loan.Status = models.LoanOverdue
+	outstanding := loan.Outstanding
+
+	pet.SimulatedAt = now.Add(-3 * time.Hour)
+	coins := pet.Coins
+	summary := ps.catchUpPet(pet, now)
+	if summary == nil || len(summary.Income) == 0 {
+		t.Fatalf("Expected offline income in three hours, got %+v", summary)
+	}
+	if loan.Outstanding >= outstanding {
+		t.Fatalf("Expected offline income garnished for the overdue loan, outstanding %d -> %d", outstanding, loan.Outstanding)
+	}
+
+	digest := ps.events[len(ps.events)-1]
+	if digest.Type != models.EventDigest || digest.Data.Coins != pet.Coins-coins {
+		t.Errorf("Expected digest to report %d coins received, got %d", pet.Coins-coins, digest.Data.Coins)
+	}
+	ps.savePetToDatabase(pet)
+	assertReconciled(t, ps)
+}
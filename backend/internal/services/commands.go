@@ -17,6 +17,11 @@ func (ps *PetService) ExecuteCommand(petID, command string, params map[string]in
 		return nil, fmt.Errorf("pet not found")
 	}
 
+	return ps.dispatchCommand(pet, command, params)
+}
+
+// dispatchCommand 执行一条宠物指令，调用方需持有锁
+func (ps *PetService) dispatchCommand(pet *models.Pet, command string, params map[string]interface{}) (interface{}, error) {
 	switch command {
 	case "rest":
 		return ps.executeRestCommand(pet, params)
@@ -30,6 +35,58 @@ func (ps *PetService) ExecuteCommand(petID, command string, params map[string]in
 		return ps.executeAddCoinsCommand(pet, params)
 	case "friends":
 		return ps.executeFriendsCommand(pet, params)
+	case "deposit":
+		return ps.executeDepositCommand(pet, params)
+	case "withdraw":
+		return ps.executeWithdrawCommand(pet, params)
+	case "bank":
+		return ps.executeBankCommand(pet, params)
+	case "bank_deposit":
+		return ps.executeBankDepositCommand(pet, params)
+	case "bank_withdraw":
+		return ps.executeBankWithdrawCommand(pet, params)
+	case "borrow":
+		return ps.executeBorrowCommand(pet, params)
+	case "repay":
+		return ps.executeRepayCommand(pet, params)
+	case "shop":
+		return ps.executeShopCommand(pet, params)
+	case "buy":
+		return ps.executeBuyCommand(pet, params)
+	case "craft":
+		return ps.executeCraftCommand(pet, params)
+	case "recipes":
+		return ps.executeRecipesCommand(pet, params)
+	case "use":
+		return ps.executeUseCommand(pet, params)
+	case "cancel":
+		return ps.executeCancelCommand(pet, params)
+	case "recall":
+		return ps.executeRecallCommand(pet, params)
+	case "strategy":
+		return ps.executeStrategyCommand(pet, params)
+	case "policy":
+		return ps.executePolicyCommand(pet, params)
+	case "goal":
+		return ps.executeGoalCommand(pet, params)
+	case "goal_clear":
+		return ps.executeGoalClearCommand(pet, params)
+	case "rules":
+		return ps.executeRulesCommand(pet, params)
+	case "rule_add":
+		return ps.executeRuleAddCommand(pet, params)
+	case "rule_update":
+		return ps.executeRuleUpdateCommand(pet, params)
+	case "rule_remove":
+		return ps.executeRuleRemoveCommand(pet, params)
+	case "queue":
+		return ps.executeQueueCommand(pet, params)
+	case "queue_reorder":
+		return ps.executeQueueReorderCommand(pet, params)
+	case "queue_remove":
+		return ps.executeQueueRemoveCommand(pet, params)
+	case "queue_clear":
+		return ps.executeQueueClearCommand(pet, params)
 	default:
 		return nil, fmt.Errorf("unknown command: %s", command)
 	}
@@ -87,7 +144,7 @@ func (ps *PetService) FeedPet(petID string, amount int) error {
 		return fmt.Errorf("not enough coins to feed pet")
 	}
 
-	pet.Coins -= cost
+	ps.debitCoins(pet, cost, models.AccountShop, models.ReasonFood, "主人喂食")
 	pet.Feed(amount)
 
 	event := models.Event{
@@ -100,6 +157,7 @@ func (ps *PetService) FeedPet(petID string, amount int) error {
 		Data:      models.EventData{Coins: -cost},
 	}
 	ps.addEvent(event)
+	ps.savePetToDatabase(pet)
 
 	return nil
 }
@@ -152,6 +210,8 @@ func (ps *PetService) GetPetStatus(petID string) (map[string]interface{}, error)
 			"name":         pet.Name,
 			"owner":        pet.Owner,
 			"personality":  pet.Personality,
+			"traits":       pet.Traits,
+			"strategy":     strategyFor(pet).Name(),
 			"level":        pet.Level,
 			"location":     pet.Location,
 			"status":       pet.Status,
@@ -172,9 +232,18 @@ func (ps *PetService) GetPetStatus(petID string) (map[string]interface{}, error)
 			"coins":       pet.Coins,
 		},
 		"social_data": map[string]interface{}{
-			"friends": pet.Friends,
-			"memory":  pet.Memory,
+			"friends":  pet.Friends,
+			"memories": pet.Memories,
 		},
+		"inventory":      pet.Inventory,
+		"current_action": ps.currentAction(pet, time.Now()),
+		"next_action":    nextQueuedAction(pet),
+		"action_queue":   pet.ActionQueue,
+		"policy":         pet.Policy,
+		"rules":          pet.Rules,
+		"goal":           pet.Goal,
+		"emotions":       emotionSummary(pet, time.Now()),
+		"bank":           ps.bankSummary(pet),
 		"capabilities": map[string]interface{}{
 			"can_explore":   pet.CanExplore(),
 			"can_rest":      pet.CanRest(),
@@ -222,7 +291,7 @@ func (ps *PetService) executeFeedCommand(pet *models.Pet, params map[string]inte
 		return nil, fmt.Errorf("not enough coins to feed pet")
 	}
 
-	pet.Coins -= cost
+	ps.debitCoins(pet, cost, models.AccountShop, models.ReasonFood, "命令喂食")
 	pet.Feed(amount)
 
 	event := models.Event{
@@ -235,6 +304,7 @@ func (ps *PetService) executeFeedCommand(pet *models.Pet, params map[string]inte
 		Data:      models.EventData{Coins: -cost},
 	}
 	ps.addEvent(event)
+	ps.savePetToDatabase(pet)
 
 	return map[string]interface{}{
 		"action":   "feed",
@@ -278,6 +348,16 @@ func (ps *PetService) executeExploreCommand(pet *models.Pet, params map[string]i
 		Priority: 100,
 		Reason:   fmt.Sprintf("接受命令向%s探索", direction),
 		Duration: 60,
+		Params:   make(map[string]interface{}),
+	}
+	// 指定了地点时直接前往
+	if location, ok := params["location"].(string); ok && location != "" {
+		if !isExploreLocation(location) {
+			return nil, fmt.Errorf("未知的地点: %s", location)
+		}
+		direction = location
+		action.Reason = fmt.Sprintf("接受命令前往%s探索", location)
+		action.Params["location"] = location
 	}
 
 	ps.executeExploreAction(pet, action)
@@ -288,6 +368,35 @@ func (ps *PetService) executeExploreCommand(pet *models.Pet, params map[string]i
 	}, nil
 }
 
+func (ps *PetService) executeCancelCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	action, progress, err := ps.interruptAction(pet, "接受命令中止行动", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":    "cancel",
+		"cancelled": action.Type,
+		"progress":  progress,
+		"message":   fmt.Sprintf("%s 中止了%s", pet.Name, action.Status),
+	}, nil
+}
+
+func (ps *PetService) executeRecallCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	action, progress, err := ps.interruptAction(pet, "被主人召回"+bankLocation, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":    "recall",
+		"cancelled": action.Type,
+		"progress":  progress,
+		"location":  pet.Location,
+		"message":   fmt.Sprintf("%s 中止了%s，回到了%s", pet.Name, action.Status, pet.Location),
+	}, nil
+}
+
 func (ps *PetService) executeAddCoinsCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
 	amount := 100
 	if a, ok := params["amount"].(float64); ok {
@@ -302,7 +411,7 @@ func (ps *PetService) executeAddCoinsCommand(pet *models.Pet, params map[string]
 	}
 	
 	oldCoins := pet.Coins
-	pet.Coins += amount
+	ps.creditCoins(pet, amount, models.AccountMint, models.ReasonAddCoins, "调试指令")
 	
 	ps.addEvent(models.Event{
 		ID:        uuid.New().String(),
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// weatherInterval 各地天气变化的周期
+const weatherInterval = GameDayDuration / 4
+
+// gameEpoch 游戏时钟的起点，时钟和天气都由时间推算，重启后保持一致
+var gameEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// gameClockAt 现实时间对应的游戏时钟
+func gameClockAt(now time.Time) models.GameClock {
+	elapsed := now.Sub(gameEpoch)
+	minutes := int((elapsed % GameDayDuration) * 24 * 60 / GameDayDuration)
+	clock := models.GameClock{
+		Day:    int(elapsed/GameDayDuration) + 1,
+		Hour:   minutes / 60,
+		Minute: minutes % 60,
+	}
+
+	switch {
+	case clock.Hour >= 5 && clock.Hour < 7:
+		clock.Period = models.TimeDawn
+	case clock.Hour >= 7 && clock.Hour < 18:
+		clock.Period = models.TimeDay
+	case clock.Hour >= 18 && clock.Hour < 20:
+		clock.Period = models.TimeDusk
+	default:
+		clock.Period = models.TimeNight
+	}
+	return clock
+}
+
+// weatherAt 地点在当前周期的天气及其结束时间，同一地点同一周期的结果固定
+func weatherAt(location string, now time.Time) (models.Weather, time.Time) {
+	slot := int64(now.Sub(gameEpoch) / weatherInterval)
+	until := gameEpoch.Add(time.Duration(slot+1) * weatherInterval)
+
+	climate, exists := models.LocationClimates[location]
+	if !exists || climate.Sheltered {
+		return models.WeatherClear, until
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(location))
+	roll := rand.New(rand.NewSource(int64(hash.Sum64()) ^ slot)).Intn(100)
+
+	switch {
+	case roll < climate.Storm:
+		return models.WeatherStorm, until
+	case roll < climate.Storm+climate.Rain:
+		return models.WeatherRain, until
+	case roll < climate.Storm+climate.Rain+climate.Fog:
+		return models.WeatherFog, until
+	}
+	return models.WeatherClear, until
+}
+
+// conditionsAt 地点当前的时间和天气
+func conditionsAt(location string, now time.Time) models.Conditions {
+	weather, until := weatherAt(location, now)
+	return models.Conditions{
+		Location:     location,
+		Clock:        gameClockAt(now),
+		Weather:      weather,
+		WeatherUntil: until,
+		Sheltered:    models.LocationClimates[location].Sheltered,
+	}
+}
+
+// rollEncounter 按权重抽取随机事件类型，intn 为随机数来源
+func rollEncounter(weights map[models.EventType]int, intn func(n int) int) models.EventType {
+	order := []models.EventType{
+		models.EventExplore, models.EventBattle, models.EventDiscovery,
+		models.EventSocial, models.EventReward,
+	}
+
+	total := 0
+	for _, eventType := range order {
+		total += weights[eventType]
+	}
+	roll := intn(total)
+	for _, eventType := range order {
+		if roll < weights[eventType] {
+			return eventType
+		}
+		roll -= weights[eventType]
+	}
+	return models.EventExplore
+}
+
+// GetWorldConditions 获取游戏时钟和各地点的天气
+func (ps *PetService) GetWorldConditions() map[string]interface{} {
+	now := time.Now()
+	locations := make([]models.Conditions, 0, len(models.Locations)+1)
+	for _, location := range append([]string{bankLocation}, models.Locations...) {
+		locations = append(locations, conditionsAt(location, now))
+	}
+
+	return map[string]interface{}{
+		"clock":     gameClockAt(now),
+		"locations": locations,
+		"timestamp": now,
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// TestGameClock 游戏时钟由现实时间推算，时段随游戏内的小时变化
+func TestGameClock(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return gameEpoch.Add(time.Duration(day)*GameDayDuration + time.Duration(hour)*GameDayDuration/24)
+	}
+	cases := []struct {
+		when   time.Time
+		day    int
+		hour   int
+		period models.TimeOfDay
+	}{
+		{at(0, 0), 1, 0, models.TimeNight},
+		{at(0, 6), 1, 6, models.TimeDawn},
+		{at(0, 12), 1, 12, models.TimeDay},
+		{at(0, 19), 1, 19, models.TimeDusk},
+		{at(2, 21), 3, 21, models.TimeNight},
+	}
+	for _, c := range cases {
+		clock := gameClockAt(c.when)
+		if clock.Day != c.day || clock.Hour != c.hour || clock.Period != c.period {
+			t.Errorf("Expected day %d %02d:00 %s, got %s %s", c.day, c.hour, c.period, clock, clock.Period)
+		}
+	}
+}
+
+// TestWeatherDeterministic 同一地点同一周期的天气固定，室内地点总是晴朗
+func TestWeatherDeterministic(t *testing.T) {
+	start := gameEpoch.Add(100 * weatherInterval)
+	for location := range models.LocationClimates {
+		first, until := weatherAt(location, start)
+		second, _ := weatherAt(location, start.Add(weatherInterval-time.Second))
+		if first != second || !until.Equal(start.Add(weatherInterval)) {
+			t.Errorf("Expected %s weather %s to hold until %v, got %s until %v", location, first, start.Add(weatherInterval), second, until)
+		}
+		if models.LocationClimates[location].Sheltered && first != models.WeatherClear {
+			t.Errorf("Expected sheltered %s to be clear, got %s", location, first)
+		}
+	}
+}
+
+// TestConditionsEffects 夜晚和暴风雨让战斗更多、社交更少、更耗体力，勇敢的宠物比谨慎的宠物更敢夜里出门，室内不受影响
+func TestConditionsEffects(t *testing.T) {
+	stormyNight := models.Conditions{Clock: models.GameClock{Period: models.TimeNight}, Weather: models.WeatherStorm}
+	weights := stormyNight.EncounterWeights()
+	if weights[models.EventBattle] != 19 || weights[models.EventSocial] != 1 || weights[models.EventReward] != 6 {
+		t.Errorf("Expected battle 19, social clamped to 1 and reward 6, got %v", weights)
+	}
+	if drain := stormyNight.EnergyDrain(); drain != 4 {
+		t.Errorf("Expected a stormy night to drain 4 extra energy, got %d", drain)
+	}
+	brave := stormyNight.ExploreModifier(models.TraitsFor(models.PersonalityBrave))
+	cautious := stormyNight.ExploreModifier(models.TraitsFor(models.PersonalityCautious))
+	if brave <= cautious || cautious >= 0 {
+		t.Errorf("Expected a brave pet to mind the storm less, got %d and %d", brave, cautious)
+	}
+
+	sheltered := stormyNight
+	sheltered.Sheltered = true
+	if sheltered.Night() || sheltered.EnergyDrain() != 0 || sheltered.ExploreModifier(models.TraitsFor(models.PersonalityCautious)) != 0 {
+		t.Error("Expected sheltered locations to ignore night and weather")
+	}
+	if weights := sheltered.EncounterWeights(); weights[models.EventBattle] != 10 || weights[models.EventSocial] != 10 {
+		t.Errorf("Expected even weights indoors, got %v", weights)
+	}
+}
+
+// TestRollEncounter 按固定顺序和权重区间抽取事件类型
+func TestRollEncounter(t *testing.T) {
+	weights := map[models.EventType]int{
+		models.EventExplore: 1, models.EventBattle: 19, models.EventDiscovery: 10,
+		models.EventSocial: 1, models.EventReward: 6,
+	}
+	for roll, expected := range map[int]models.EventType{
+		0:  models.EventExplore,
+		1:  models.EventBattle,
+		19: models.EventBattle,
+		20: models.EventDiscovery,
+		30: models.EventSocial,
+		36: models.EventReward,
+	} {
+		got := rollEncounter(weights, func(n int) int {
+			if n != 37 {
+				t.Fatalf("Expected total weight 37, got %d", n)
+			}
+			return roll
+		})
+		if got != expected {
+			t.Errorf("Expected roll %d to be %s, got %s", roll, expected, got)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+// canCraft 检查宠物是否可以制作配方
+func canCraft(pet *models.Pet, recipe models.Recipe) error {
+	if pet.Level < recipe.MinLevel {
+		return fmt.Errorf("需要%d级才能制作%s", recipe.MinLevel, recipe.Name)
+	}
+	if missing := recipe.MissingInputs(pet); len(missing) > 0 {
+		ids := make([]string, 0, len(missing))
+		for id := range missing {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return fmt.Errorf("制作%s缺少材料: %v", recipe.Name, ids)
+	}
+	if pet.Coins < recipe.Coins {
+		return fmt.Errorf("宠物金币不足（当前: %d，需要: %d）", pet.Coins, recipe.Coins)
+	}
+	return nil
+}
+
+// startCrafting 消耗材料和工本费开始制作，制作完成后按成功率获得成品
+func (ps *PetService) startCrafting(pet *models.Pet, recipe models.Recipe, reason string) error {
+	if err := canCraft(pet, recipe); err != nil {
+		return err
+	}
+
+	for _, input := range recipe.Inputs {
+		pet.RemoveItem(input.ItemID, input.Quantity)
+	}
+	ps.debitCoins(pet, recipe.Coins, models.AccountShop, models.ReasonCrafting, recipe.Name)
+
+	message := fmt.Sprintf("[%s] %s", pet.Name, reason)
+	if reason == "" {
+		message = ps.narrate(pet, Narrative{Kind: NarrateCraftStart, Item: recipe.Name})
+	}
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventReward,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data:      models.EventData{Coins: -recipe.Coins},
+	})
+
+	params := map[string]interface{}{"recipe": recipe.ID}
+	ps.beginAction(pet, ActionCraft, models.StatusCrafting, reason, params, recipe.Duration)
+	return nil
+}
+
+// finishCrafting 制作完成，调用方需持有锁
+func (ps *PetService) finishCrafting(pet *models.Pet, recipe models.Recipe) {
+	pet.Status = models.StatusIdle
+
+	event := models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventReward,
+		Timestamp: time.Now(),
+	}
+	if rand.Intn(100) < recipe.SuccessChance(pet.Level) {
+		pet.AddItem(recipe.Output)
+		pet.GainExperience(recipe.Duration / 2)
+		event.Message = ps.narrate(pet, Narrative{Kind: NarrateCrafted, Item: recipe.Output.Name})
+		event.Data.Items = []models.Item{recipe.Output}
+	} else {
+		event.Message = ps.narrate(pet, Narrative{Kind: NarrateCraftFailed, Item: recipe.Name})
+	}
+	ps.addEvent(event)
+	ps.savePetToDatabase(pet)
+}
+
+func (ps *PetService) completeCraftAction(pet *models.Pet, action *models.PetAction) {
+	recipeID, _ := action.Params["recipe"].(string)
+	recipe, exists := models.FindRecipe(recipeID)
+	if !exists {
+		pet.Status = models.StatusIdle
+		return
+	}
+	ps.finishCrafting(pet, recipe)
+}
+
+// cancelCraftAction 按剩余进度收回未用掉的材料，工本费不退
+func (ps *PetService) cancelCraftAction(pet *models.Pet, action *models.PetAction, progress float64) string {
+	recipeID, _ := action.Params["recipe"].(string)
+	recipe, exists := models.FindRecipe(recipeID)
+	if !exists {
+		return "材料都已耗尽"
+	}
+
+	returned := make([]string, 0, len(recipe.Inputs))
+	for _, input := range recipe.Inputs {
+		quantity := int(float64(input.Quantity) * (1 - progress))
+		material, exists := models.FindMaterial(input.ItemID)
+		if quantity <= 0 || !exists {
+			continue
+		}
+		material.Quantity = quantity
+		pet.AddItem(material)
+		returned = append(returned, fmt.Sprintf("%sx%d", material.Name, quantity))
+	}
+	if len(returned) == 0 {
+		return "材料都已耗尽"
+	}
+	return "收回了" + strings.Join(returned, "、")
+}
+
+func (ps *PetService) executeCraftAction(pet *models.Pet, action Action) {
+	recipeID, _ := action.Params["recipe"].(string)
+	recipe, exists := models.FindRecipe(recipeID)
+	if !exists {
+		return
+	}
+	ps.startCrafting(pet, recipe, action.Reason)
+}
+
+func (ps *PetService) executeCraftCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	if !pet.CanRest() {
+		return nil, fmt.Errorf("宠物当前无法制作，状态: %s", pet.Status)
+	}
+
+	recipeID, _ := params["recipe"].(string)
+	recipe, exists := models.FindRecipe(recipeID)
+	if !exists {
+		return nil, fmt.Errorf("unknown recipe: %s", recipeID)
+	}
+
+	if err := ps.startCrafting(pet, recipe, ""); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":   "craft",
+		"recipe":   recipe.ID,
+		"duration": recipe.Duration,
+		"chance":   recipe.SuccessChance(pet.Level),
+		"coins":    pet.Coins,
+		"message":  fmt.Sprintf("%s 开始制作%s，预计%d秒，成功率%d%%", pet.Name, recipe.Name, recipe.Duration, recipe.SuccessChance(pet.Level)),
+	}, nil
+}
+
+func (ps *PetService) executeRecipesCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	recipes := make([]map[string]interface{}, 0, len(models.Recipes))
+	for _, recipe := range models.Recipes {
+		info := map[string]interface{}{
+			"id":        recipe.ID,
+			"name":      recipe.Name,
+			"inputs":    recipe.Inputs,
+			"coins":     recipe.Coins,
+			"duration":  recipe.Duration,
+			"min_level": recipe.MinLevel,
+			"chance":    recipe.SuccessChance(pet.Level),
+			"output":    recipe.Output,
+			"craftable": canCraft(pet, recipe) == nil,
+		}
+		if missing := recipe.MissingInputs(pet); len(missing) > 0 {
+			info["missing"] = missing
+		}
+		recipes = append(recipes, info)
+	}
+
+	return map[string]interface{}{
+		"action":  "recipes",
+		"recipes": recipes,
+		"message": fmt.Sprintf("共有 %d 个配方", len(recipes)),
+	}, nil
+}
+
+func (ps *PetService) executeUseCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	itemID, _ := params["item"].(string)
+	item, ok := pet.UseItem(itemID)
+	if !ok {
+		return nil, fmt.Errorf("%s 没有可以使用的%s", pet.Name, itemID)
+	}
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventReward,
+		Message:   ps.narrate(pet, Narrative{Kind: NarrateUseItem, Item: item.Name}),
+		Timestamp: time.Now(),
+	})
+	ps.savePetToDatabase(pet)
+
+	return map[string]interface{}{
+		"action":  "use",
+		"item":    item.ID,
+		"health":  pet.Health,
+		"energy":  pet.Energy,
+		"hunger":  pet.Hunger,
+		"message": fmt.Sprintf("%s 使用了%s", pet.Name, item.Name),
+	}, nil
+}
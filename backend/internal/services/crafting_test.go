@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+
+	"miningpet/internal/models"
+)
+
+// giveMaterials 把配方需要的材料放进宠物背包，调用方需持有锁
+func giveMaterials(pet *models.Pet, recipe models.Recipe) {
+	for _, input := range recipe.Inputs {
+		material, _ := models.FindMaterial(input.ItemID)
+		material.Quantity = input.Quantity
+		pet.AddItem(material)
+	}
+}
+
+// TestCrafting 制作消耗材料和工本费，完成后按成功率得到成品
+func TestCrafting(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "crafter", models.PersonalityCurious)
+	recipe, _ := models.FindRecipe("healing_potion")
+
+	ps.mutex.Lock()
+	if err := canCraft(pet, recipe); err == nil {
+		t.Error("Expected crafting without materials to fail")
+	}
+	giveMaterials(pet, recipe)
+	ps.creditCoins(pet, 50, models.AccountMint, models.ReasonAddCoins, "")
+	coins := pet.Coins
+	ps.mutex.Unlock()
+
+	if _, err := ps.ExecuteCommand(pet.ID, "craft", map[string]interface{}{"recipe": recipe.ID}); err != nil {
+		t.Fatalf("Failed to start crafting: %v", err)
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	for _, input := range recipe.Inputs {
+		if pet.FindItem(input.ItemID) != nil {
+			t.Errorf("Expected %s to be consumed", input.ItemID)
+		}
+	}
+	if pet.Coins != coins-recipe.Coins {
+		t.Errorf("Expected crafting fee %d paid, coins %d -> %d", recipe.Coins, coins, pet.Coins)
+	}
+	assertReconciled(t, ps)
+	action, inflight := ps.actions.inflight[pet.ID]
+	if !inflight || pet.Status != models.StatusCrafting {
+		t.Fatalf("Expected crafting action in flight, got status %s", pet.Status)
+	}
+
+	experience := pet.Experience
+	ps.finishAction(pet.ID, action.ID)
+	if pet.Status != models.StatusIdle {
+		t.Errorf("Expected pet idle after crafting, got %s", pet.Status)
+	}
+	if crafted := pet.FindItem(recipe.Output.ID) != nil; crafted != (pet.Experience > experience || pet.Level > 1) {
+		t.Errorf("Expected experience only for successful crafting (crafted=%v)", crafted)
+	}
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+)
+
+const (
+	// economyBucketSize 遥测时间桶的最小粒度
+	economyBucketSize = 5 * time.Minute
+	// economyMaxPoints 单次查询最多返回的数据点
+	economyMaxPoints = 1000
+
+	FlowMint = "mint" // 金币产出（水龙头）
+	FlowBurn = "burn" // 金币消耗（下水道）
+)
+
+// ErrInvalidTimeseries 时间序列查询参数不合法
+var ErrInvalidTimeseries = errors.New("invalid timeseries query")
+
+// economyKey 内存中累加的时间桶键
+type economyKey struct {
+	bucketStart time.Time
+	flow        string
+	source      string
+}
+
+// EconomyService 经济遥测服务
+// 观察每一条账本分录，按时间桶统计金币的产出与消耗
+type EconomyService struct {
+	repo    *database.EconomyRepository
+	pending map[economyKey]*database.DBEconomyBucket
+	mutex   sync.Mutex
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+// EconomyPoint 时间序列中的一个数据点
+type EconomyPoint struct {
+	Start       time.Time        `json:"start"`
+	Minted      map[string]int64 `json:"minted"`
+	Burned      map[string]int64 `json:"burned"`
+	MintedTotal int64            `json:"minted_total"`
+	BurnedTotal int64            `json:"burned_total"`
+	Net         int64            `json:"net"`
+	MoneySupply int64            `json:"money_supply"` // 该时间段结束时的货币总量
+}
+
+// NewEconomyService 创建经济遥测服务
+func NewEconomyService() *EconomyService {
+	es := &EconomyService{
+		repo:    database.NewEconomyRepository(),
+		pending: make(map[economyKey]*database.DBEconomyBucket),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go es.runFlush()
+	return es
+}
+
+// classifyEntry 判断分录属于产出还是消耗（银行放贷计为产出，还贷计为消耗）
+func classifyEntry(entry *models.LedgerEntry) (string, bool) {
+	switch {
+	case entry.Credit == models.AccountMint || entry.Credit == models.AccountBank:
+		return FlowMint, true
+	case entry.Debit == models.AccountShop || entry.Debit == models.AccountTreasury || entry.Debit == models.AccountBank:
+		return FlowBurn, true
+	default:
+		return "", false
+	}
+}
+
+// Record 记录一条分录对经济的影响
+func (es *EconomyService) Record(entry *models.LedgerEntry) {
+	flow, ok := classifyEntry(entry)
+	if !ok {
+		return
+	}
+
+	key := economyKey{
+		bucketStart: entry.CreatedAt.UTC().Truncate(economyBucketSize),
+		flow:        flow,
+		source:      string(entry.Reason),
+	}
+
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+	es.add(key, int64(entry.Amount), 1)
+}
+
+// add 累加到内存中的时间桶，调用方需持有 es.mutex
+func (es *EconomyService) add(key economyKey, amount, count int64) {
+	bucket, exists := es.pending[key]
+	if !exists {
+		bucket = &database.DBEconomyBucket{
+			BucketStart: key.bucketStart,
+			Flow:        key.flow,
+			Source:      key.source,
+		}
+		es.pending[key] = bucket
+	}
+	bucket.Amount += amount
+	bucket.Count += count
+}
+
+// Flush 将内存中的增量写入数据库，写入失败时增量并回内存，下次刷新时重试
+func (es *EconomyService) Flush() error {
+	es.mutex.Lock()
+	deltas := make([]*database.DBEconomyBucket, 0, len(es.pending))
+	for _, bucket := range es.pending {
+		deltas = append(deltas, bucket)
+	}
+	es.pending = make(map[economyKey]*database.DBEconomyBucket)
+	es.mutex.Unlock()
+
+	if err := es.repo.AddToBuckets(deltas); err != nil {
+		es.mutex.Lock()
+		for _, delta := range deltas {
+			es.add(economyKey{bucketStart: delta.BucketStart, flow: delta.Flow, source: delta.Source}, delta.Amount, delta.Count)
+		}
+		es.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (es *EconomyService) runFlush() {
+	defer close(es.done)
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-es.quit:
+			return
+		case <-ticker.C:
+		}
+		if err := es.Flush(); err != nil {
+			log.Printf("Warning: failed to flush economy telemetry: %v", err)
+		}
+	}
+}
+
+// Stop 停止定期刷新并写入内存中剩余的增量
+func (es *EconomyService) Stop() {
+	close(es.quit)
+	<-es.done
+	if err := es.Flush(); err != nil {
+		log.Printf("Warning: failed to flush economy telemetry: %v", err)
+	}
+}
+
+// Timeseries 按指定间隔聚合 [from, to) 内的经济数据，currentSupply 为当前货币总量
+func (es *EconomyService) Timeseries(from, to time.Time, interval time.Duration, currentSupply int64) ([]EconomyPoint, error) {
+	if interval < economyBucketSize || interval%economyBucketSize != 0 {
+		return nil, fmt.Errorf("%w: interval must be a multiple of %v", ErrInvalidTimeseries, economyBucketSize)
+	}
+
+	from = from.UTC().Truncate(interval)
+	to = to.UTC()
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: invalid time range", ErrInvalidTimeseries)
+	}
+
+	count := int((to.Sub(from) + interval - 1) / interval)
+	if count > economyMaxPoints {
+		return nil, fmt.Errorf("%w: too many points (max: %d)", ErrInvalidTimeseries, economyMaxPoints)
+	}
+
+	if err := es.Flush(); err != nil {
+		return nil, err
+	}
+
+	buckets, err := es.repo.GetBuckets(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]EconomyPoint, count)
+	for i := range points {
+		points[i] = EconomyPoint{
+			Start:  from.Add(time.Duration(i) * interval),
+			Minted: make(map[string]int64),
+			Burned: make(map[string]int64),
+		}
+	}
+
+	for _, bucket := range buckets {
+		index := int(bucket.BucketStart.UTC().Sub(from) / interval)
+		if index < 0 || index >= count {
+			continue
+		}
+		point := &points[index]
+		switch bucket.Flow {
+		case FlowMint:
+			point.Minted[bucket.Source] += bucket.Amount
+			point.MintedTotal += bucket.Amount
+		case FlowBurn:
+			point.Burned[bucket.Source] += bucket.Amount
+			point.BurnedTotal += bucket.Amount
+		}
+	}
+
+	// 从当前货币总量倒推每个时间段结束时的货币总量
+	after, err := es.repo.GetFlowTotalsSince(to)
+	if err != nil {
+		return nil, err
+	}
+	supply := currentSupply - (after[FlowMint] - after[FlowBurn])
+	for i := count - 1; i >= 0; i-- {
+		points[i].Net = points[i].MintedTotal - points[i].BurnedTotal
+		points[i].MoneySupply = supply
+		supply -= points[i].Net
+	}
+
+	return points, nil
+}
+
+// moneySupply 当前流通中的金币总量（宠物金币、主人钱包、银行存款与拍卖托管）
+func (ps *PetService) moneySupply() int64 {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	var supply int64
+	for _, pet := range ps.pets {
+		supply += int64(pet.Coins)
+	}
+	return supply + ps.wallets.totalBalance() + ps.bank.totalDeposits() + ps.auctions.totalEscrow()
+}
+
+// GetEconomyTimeseries 获取经济时间序列
+func (ps *PetService) GetEconomyTimeseries(from, to time.Time, interval time.Duration) (map[string]interface{}, error) {
+	supply := ps.moneySupply()
+
+	points, err := ps.economy.Timeseries(from, to, interval, supply)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"from":           from.UTC().Truncate(interval),
+		"to":             to.UTC(),
+		"interval":       interval.String(),
+		"current_supply": supply,
+		"points":         points,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+)
+
+// TestEconomyFlushRetry 遥测写入失败时增量留在内存，下次刷新一并写入
+func TestEconomyFlushRetry(t *testing.T) {
+	ps := newTestPetService(t)
+	es := ps.economy
+	account := models.PetAccount("pet")
+
+	es.Record(models.NewLedgerEntry("pet", account, models.AccountMint, 30, models.ReasonBattle, ""))
+	if err := database.DB.Migrator().DropTable(&database.DBEconomyBucket{}); err != nil {
+		t.Fatalf("Failed to drop economy buckets: %v", err)
+	}
+	if err := es.Flush(); err == nil {
+		t.Fatal("Expected flush to fail without the buckets table")
+	}
+
+	es.Record(models.NewLedgerEntry("pet", account, models.AccountMint, 20, models.ReasonBattle, ""))
+	if err := database.DB.AutoMigrate(&database.DBEconomyBucket{}); err != nil {
+		t.Fatalf("Failed to recreate economy buckets: %v", err)
+	}
+	if err := es.Flush(); err != nil {
+		t.Fatalf("Failed to flush economy telemetry: %v", err)
+	}
+
+	now := time.Now()
+	buckets, err := es.repo.GetBuckets(now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get economy buckets: %v", err)
+	}
+	var amount, count int64
+	for _, bucket := range buckets {
+		if bucket.Flow == FlowMint && bucket.Source == string(models.ReasonBattle) {
+			amount += bucket.Amount
+			count += bucket.Count
+		}
+	}
+	if amount != 50 || count != 2 {
+		t.Errorf("Expected 50 coins minted in 2 entries, got %d in %d", amount, count)
+	}
+}
+
+// TestClassifyEntry 新产生的金币和银行放贷计为产出，消费、手续费和还贷计为消耗，账户之间的转移不计入
+func TestClassifyEntry(t *testing.T) {
+	pet := models.PetAccount("pet")
+	cases := []struct {
+		name   string
+		debit  models.LedgerAccount
+		credit models.LedgerAccount
+		flow   string
+	}{
+		{"battle reward", pet, models.AccountMint, FlowMint},
+		{"loan", pet, models.AccountBank, FlowMint},
+		{"food", models.AccountShop, pet, FlowBurn},
+		{"fee", models.AccountTreasury, models.WalletAccount("owner"), FlowBurn},
+		{"loan repayment", models.AccountBank, pet, FlowBurn},
+		{"deposit", models.BankDepositAccount("pet"), pet, ""},
+		{"wallet deposit", models.WalletAccount("owner"), pet, ""},
+		{"auction bid", models.EscrowAccount("auction"), pet, ""},
+		{"loan interest", models.LoanReceivableAccount("pet"), models.AccountTreasury, ""},
+	}
+	for _, c := range cases {
+		flow, ok := classifyEntry(models.NewLedgerEntry("pet", c.debit, c.credit, 10, models.ReasonReward, ""))
+		if flow != c.flow || ok != (c.flow != "") {
+			t.Errorf("%s: expected flow %q, got %q (%v)", c.name, c.flow, flow, ok)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+const (
+	// lonelySocial 社交度低于该值说明宠物已经很久没有和其他宠物来往
+	lonelySocial = 25
+	// lonelinessPerTick 孤立期间每次属性更新积累的孤独
+	lonelinessPerTick = 0.05
+	// emotionExploreInfluence 恐惧和喜悦对探索优先级的影响
+	emotionExploreInfluence = 40
+	// emotionRestInfluence 恐惧让宠物想躲起来休息
+	emotionRestInfluence = 30
+	// emotionSocialInfluence 孤独让宠物想找伙伴
+	emotionSocialInfluence = 40
+)
+
+// emotionNames 情绪在消息中的说法
+var emotionNames = map[models.EmotionKind]string{
+	models.EmotionJoy:        "喜悦",
+	models.EmotionFear:       "恐惧",
+	models.EmotionSadness:    "难过",
+	models.EmotionLoneliness: "孤独",
+}
+
+// feelExplore 探索中的遭遇引发的情绪，调用方需持有锁
+func (ps *PetService) feelExplore(pet *models.Pet, event models.Event, levelBefore int) {
+	now := time.Now()
+	switch event.Type {
+	case models.EventRareFind:
+		pet.Feel(models.EmotionJoy, 0.8, "找到了稀有的"+event.Data.RareItem, now)
+	case models.EventDiscovery:
+		if event.Data.Coins > 0 {
+			pet.Feel(models.EmotionJoy, 0.3, fmt.Sprintf("捡到了%d金币", event.Data.Coins), now)
+		}
+	case models.EventBattle, models.EventBoss:
+		if event.Data.IsVictory {
+			pet.Feel(models.EmotionJoy, 0.4, "打败了"+event.Data.Enemy, now)
+		} else if event.Type == models.EventBattle {
+			pet.Feel(models.EmotionFear, 0.5, "输给了"+event.Data.Enemy, now)
+			pet.Feel(models.EmotionSadness, 0.2, "输给了"+event.Data.Enemy, now)
+		}
+		if nearDeath(pet) {
+			pet.Feel(models.EmotionFear, 0.5, "差点丢了性命", now)
+		}
+	case models.EventSocial:
+		pet.Feel(models.EmotionJoy, 0.2, "在路上遇到了伙伴", now)
+		pet.Soothe(models.EmotionLoneliness, 0.5, now)
+	}
+	if pet.Level > levelBefore {
+		pet.Feel(models.EmotionJoy, 0.6, fmt.Sprintf("升到了%d级", pet.Level), now)
+	}
+}
+
+// feelIsolation 长时间没有社交的宠物渐渐感到孤独，调用方需持有锁
+func feelIsolation(pet *models.Pet) {
+	if pet.Social < lonelySocial && pet.Status != models.StatusSocializing {
+		pet.Feel(models.EmotionLoneliness, lonelinessPerTick, "很久没有和其他宠物来往", time.Now())
+	}
+}
+
+// emotionAdjust 情绪对各类行为优先级的影响：恐惧让宠物不敢出门而想躲起来休息，喜悦让宠物跃跃欲试，孤独让宠物想找伙伴
+func emotionAdjust(pet *models.Pet, actionType ActionType, now time.Time) int {
+	switch actionType {
+	case ActionExplore:
+		joy := pet.Emotions.Level(models.EmotionJoy, now)
+		fear := pet.Emotions.Level(models.EmotionFear, now)
+		return int((joy/2 - fear) * emotionExploreInfluence)
+	case ActionRest:
+		return int(pet.Emotions.Level(models.EmotionFear, now) * emotionRestInfluence)
+	case ActionSocialize:
+		return int(pet.Emotions.Level(models.EmotionLoneliness, now) * emotionSocialInfluence)
+	}
+	return 0
+}
+
+// emotionSummary 状态信息中宠物仍在感受的情绪及其当前强度
+func emotionSummary(pet *models.Pet, now time.Time) []map[string]interface{} {
+	summary := make([]map[string]interface{}, 0, len(pet.Emotions))
+	for _, emotion := range pet.Emotions {
+		level := emotion.Current(now)
+		if level < models.EmotionThreshold {
+			continue
+		}
+		summary = append(summary, map[string]interface{}{
+			"kind":      emotion.Kind,
+			"name":      emotionNames[emotion.Kind],
+			"intensity": math.Round(level*100) / 100,
+			"cause":     emotion.Cause,
+		})
+	}
+	return summary
+}
+
+// feltEmotion 宠物此刻明显感受到的情绪，用于让行为理由和消息带上情绪
+func feltEmotion(pet *models.Pet, kind models.EmotionKind) (models.Emotion, bool) {
+	strongest, ok := pet.Emotions.Strongest(time.Now())
+	if !ok || strongest.Kind != kind {
+		return models.Emotion{}, false
+	}
+	return strongest, true
+}
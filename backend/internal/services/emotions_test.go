@@ -0,0 +1,109 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// calmPet 身体状况对心情得分没有影响的宠物，心情完全由情绪决定
+func calmPet() *models.Pet {
+	pet := models.NewPet("calm")
+	pet.Health, pet.Energy, pet.Hunger, pet.Social = 70, 50, 50, 50
+	pet.Emotions = nil
+	return pet
+}
+
+// TestEmotionDecay 情绪按各自的半衰期衰减，同种情绪叠加不超过 1，已经消散的情绪被清理
+func TestEmotionDecay(t *testing.T) {
+	now := time.Now()
+	joy := models.Emotion{Kind: models.EmotionJoy, Intensity: 0.8, FeltAt: now}
+	if level := joy.Current(now.Add(models.EmotionHalfLife[models.EmotionJoy])); math.Abs(level-0.4) > 1e-9 {
+		t.Errorf("Expected joy halved after its half-life, got %.3f", level)
+	}
+	if level := joy.Current(now.Add(-time.Minute)); level != 0.8 {
+		t.Errorf("Expected no decay before the emotion was felt, got %.3f", level)
+	}
+	// 恐惧比喜悦更持久
+	fear := models.Emotion{Kind: models.EmotionFear, Intensity: 0.8, FeltAt: now}
+	if later := now.Add(time.Hour); fear.Current(later) <= joy.Current(later) {
+		t.Error("Expected fear to outlast joy")
+	}
+
+	pet := calmPet()
+	pet.Emotions = models.Emotions{{Kind: models.EmotionSadness, Intensity: 0.1, FeltAt: now.Add(-3 * time.Hour)}}
+	pet.Feel(models.EmotionJoy, 0.5, "", now)
+	pet.Feel(models.EmotionJoy, 0.5, "又一次好运", now)
+	if level := pet.Emotions.Level(models.EmotionJoy, now); math.Abs(level-0.75) > 1e-9 {
+		t.Errorf("Expected stacked joy 0.75, got %.3f", level)
+	}
+	if len(pet.Emotions) != 1 || pet.Emotions[0].Cause != "又一次好运" {
+		t.Errorf("Expected faded sadness cleared and the latest cause kept, got %+v", pet.Emotions)
+	}
+	pet.Feel(models.EmotionJoy, 5, "", now)
+	if level := pet.Emotions.Level(models.EmotionJoy, now); level != 1 {
+		t.Errorf("Expected joy capped at 1, got %.3f", level)
+	}
+	pet.Soothe(models.EmotionJoy, 0.5, now)
+	if level := pet.Emotions.Level(models.EmotionJoy, now); level != 0.5 {
+		t.Errorf("Expected soothing to halve joy, got %.3f", level)
+	}
+}
+
+// TestEmotionsBlendIntoMood 情绪叠加在身体状况之上决定心情，身体不差时情绪最多让宠物沮丧而不是疲惫
+func TestEmotionsBlendIntoMood(t *testing.T) {
+	cases := []struct {
+		name      string
+		kind      models.EmotionKind
+		intensity float64
+		weak      bool
+		expected  models.PetMood
+	}{
+		{"calm", "", 0, false, models.MoodNeutral},
+		{"a little joy", models.EmotionJoy, 0.3, false, models.MoodHappy},
+		{"great joy", models.EmotionJoy, 1, false, models.MoodExcited},
+		{"terrified", models.EmotionFear, 1, false, models.MoodSad},
+		{"lonely", models.EmotionLoneliness, 0.7, false, models.MoodSad},
+		{"exhausted", "", 0, true, models.MoodTired},
+		{"exhausted but joyful", models.EmotionJoy, 1, true, models.MoodNeutral},
+	}
+	for _, c := range cases {
+		pet := calmPet()
+		if c.weak {
+			// 生命和体力都很低，得分 -5
+			pet.Health, pet.Energy = 20, 10
+		}
+		if c.kind != "" {
+			pet.Feel(c.kind, c.intensity, c.name, time.Now())
+		} else {
+			pet.RestoreEnergy(0)
+		}
+		if pet.Mood != c.expected {
+			t.Errorf("%s: expected mood %s, got %s", c.name, c.expected, pet.Mood)
+		}
+	}
+}
+
+// TestEmotionAdjust 恐惧让宠物不敢出门而想休息，孤独让宠物想找伙伴
+func TestEmotionAdjust(t *testing.T) {
+	now := time.Now()
+	pet := calmPet()
+	pet.Feel(models.EmotionFear, 1, "", now)
+	pet.Feel(models.EmotionLoneliness, 0.5, "", now)
+
+	for actionType, expected := range map[ActionType]int{
+		ActionExplore:   -emotionExploreInfluence,
+		ActionRest:      emotionRestInfluence,
+		ActionSocialize: emotionSocialInfluence / 2,
+		ActionEat:       0,
+	} {
+		if adjust := emotionAdjust(pet, actionType, now); adjust != expected {
+			t.Errorf("Expected %s adjusted by %d, got %d", actionType, expected, adjust)
+		}
+	}
+	if emotion, ok := feltEmotion(pet, models.EmotionFear); !ok || emotion.Kind != models.EmotionFear {
+		t.Errorf("Expected fear to be the strongest felt emotion, got %+v", emotion)
+	}
+}
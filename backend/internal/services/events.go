@@ -42,12 +42,18 @@ func (ps *PetService) GetEventChannel() <-chan models.Event {
 }
 
 func (ps *PetService) generateRandomEvent(pet *models.Pet) models.Event {
-	eventTypes := []models.EventType{
-		models.EventExplore, models.EventBattle, models.EventDiscovery,
-		models.EventSocial, models.EventReward,
+	// 所在地点有世界首领时，大多数时候会加入讨伐
+	if boss := ps.boss.bossAt(pet.Location); boss != nil && rand.Intn(100) < bossEncounterChance {
+		return ps.bossEncounter(pet, boss)
 	}
 
-	eventType := eventTypes[rand.Intn(len(eventTypes))]
+	// 进行中的世界事件（双倍经验、寻宝节等）
+	modifiers := ps.worldEvents.modifiersAt(pet.Location)
+
+	// 昼夜和天气改变各类遭遇的概率
+	conditions := conditionsAt(pet.Location, time.Now())
+
+	eventType := rollEncounter(conditions.EncounterWeights(), rand.Intn)
 	event := models.Event{
 		ID:        uuid.New().String(),
 		PetID:     pet.ID,
@@ -59,8 +65,14 @@ func (ps *PetService) generateRandomEvent(pet *models.Pet) models.Event {
 	switch eventType {
 	case models.EventExplore:
 		location := models.Locations[rand.Intn(len(models.Locations))]
+		// 大多数时候前往资源更好的地方，偶尔随意闲逛
+		if rand.Intn(100) < 70 {
+			if best, _ := ps.aiEngine.chooseLocation(pet); best != "" {
+				location = best
+			}
+		}
 		pet.Location = location
-		event.Message = fmt.Sprintf("[%s] 来到了%s，开始探索...", pet.Name, location)
+		event.Message = ps.narrate(pet, Narrative{Kind: NarrateExplore})
 		event.Data.Location = location
 		
 		// 更新状态管理器中的位置和行动计数
@@ -69,20 +81,26 @@ func (ps *PetService) generateRandomEvent(pet *models.Pet) models.Event {
 
 	case models.EventBattle:
 		monster := models.Monsters[rand.Intn(len(models.Monsters))]
+		if len(modifiers.Monsters) > 0 && rand.Intn(2) == 0 {
+			monster = modifiers.Monsters[rand.Intn(len(modifiers.Monsters))]
+		} else if conditions.Night() && rand.Intn(2) == 0 {
+			monster = models.NightMonsters[rand.Intn(len(models.NightMonsters))]
+		}
+		monster.ExpReward = int(float64(monster.ExpReward) * modifiers.ExpRate())
+		monster.CoinReward = int(float64(monster.CoinReward) * modifiers.CoinRate())
 		victory := ps.simulateBattle(pet, monster)
 		
 		if victory {
 			pet.GainExperience(monster.ExpReward)
-			pet.Coins += monster.CoinReward
-			event.Message = fmt.Sprintf("[%s] 击败了%s！获得经验+%d，金币+%d", 
-				pet.Name, monster.Name, monster.ExpReward, monster.CoinReward)
+			ps.creditCoins(pet, monster.CoinReward, models.AccountMint, models.ReasonBattle, monster.Name)
+			event.Message = ps.narrate(pet, Narrative{Kind: NarrateBattleWon, Enemy: monster.Name, Experience: monster.ExpReward, Coins: monster.CoinReward})
 		} else {
 			damage := monster.Attack - pet.Defense
 			if damage < 1 {
 				damage = 1
 			}
 			pet.TakeDamage(damage)
-			event.Message = fmt.Sprintf("[%s] 被%s击败，受到%d点伤害", pet.Name, monster.Name, damage)
+			event.Message = ps.narrate(pet, Narrative{Kind: NarrateBattleLost, Enemy: monster.Name, Damage: damage})
 			
 			// 更新血量状态
 			ps.stateManager.UpdateHP(pet.ID, pet.Health)
@@ -97,57 +115,63 @@ func (ps *PetService) generateRandomEvent(pet *models.Pet) models.Event {
 		event.Data.Coins = monster.CoinReward
 
 	case models.EventDiscovery:
-		coins := rand.Intn(20) + 5
-		pet.Coins += coins
+		coins := ps.mineOre(pet, int(float64(rand.Intn(20)+5)*modifiers.CoinRate()))
 		discoveries := []string{"宝箱", "神秘水晶", "古老卷轴", "闪光宝石", "魔法药水", "远古符文", "珍稀矿石", "神秘遗物"}
 		discovery := discoveries[rand.Intn(len(discoveries))]
+		ps.creditCoins(pet, coins, models.AccountMint, models.ReasonDiscovery, discovery)
+		if material, ok := models.FindMaterialByName(discovery); ok {
+			pet.AddItem(material)
+			event.Data.Items = []models.Item{material}
+		}
 		
-		discoveryMessages := []string{
-			"发现了%s，获得%d金币！",
-			"在探索中找到%s，收获%d金币！", 
-			"意外挖掘出%s，得到%d金币奖励！",
-			"仔细搜索后发现%s，获得%d金币！",
-			"幸运地遇到%s，赚得%d金币！",
+		kind := NarrateDiscovery
+		if coins == 0 {
+			kind = NarrateDiscoveryDepleted
 		}
-		messageTemplate := discoveryMessages[rand.Intn(len(discoveryMessages))]
-		event.Message = fmt.Sprintf("[%s] %s", pet.Name, fmt.Sprintf(messageTemplate, discovery, coins))
+		event.Message = ps.narrate(pet, Narrative{Kind: kind, Item: discovery, Coins: coins})
 		event.Data.Coins = coins
 
 	case models.EventSocial:
 		friends := []string{"小明", "小红", "阿强", "丽丽", "小虎"}
 		friend := friends[rand.Intn(len(friends))]
-		event.Message = fmt.Sprintf("[%s] 遇到了%s的宠物，成为了朋友！", pet.Name, friend)
+		event.Message = ps.narrate(pet, Narrative{Kind: NarrateSocial, Friend: friend})
 		event.Data.FriendName = friend
 
 	case models.EventReward:
-		if rand.Intn(100) < 5 {
+		if rand.Intn(100) < 5+modifiers.RareFindBonus && ps.takeTreasure(pet) {
 			event.Type = models.EventRareFind
-			rareReward := rand.Intn(1000) + 500
-			pet.Coins += rareReward
-			event.Message = fmt.Sprintf("[%s] 🌟 发现神秘矿石！获得大奖%d金币！", pet.Name, rareReward)
+			rareReward := int(float64(rand.Intn(1000)+500) * modifiers.CoinRate())
+			treasure := models.RareFinds[rand.Intn(len(models.RareFinds))]
+			ps.creditCoins(pet, rareReward, models.AccountMint, models.ReasonRareFind, treasure.Name)
+			pet.AddItem(treasure)
+			event.Message = ps.narrate(pet, Narrative{Kind: NarrateRareFind, Item: treasure.Name, Coins: rareReward})
 			event.Data.Coins = rareReward
+			event.Data.RareItem = treasure.Name
 		} else {
-			coins := rand.Intn(50) + 10
-			pet.Coins += coins
+			coins := ps.mineOre(pet, int(float64(rand.Intn(50)+10)*modifiers.CoinRate()))
+			ps.creditCoins(pet, coins, models.AccountMint, models.ReasonReward, "")
 			
-			rewardMessages := []string{
-				"找到了一些零散的金币：+%d",
-				"发现了闪闪发光的硬币：+%d",
-				"从地上捡到了金币：+%d", 
-				"在岩石缝隙中发现金币：+%d",
-				"挖出了埋在土里的金币：+%d",
-				"在古老树根下找到金币：+%d",
+			kind := NarrateReward
+			if coins == 0 {
+				kind = NarrateRewardDepleted
 			}
-			messageTemplate := rewardMessages[rand.Intn(len(rewardMessages))]
-			event.Message = fmt.Sprintf("[%s] %s", pet.Name, fmt.Sprintf(messageTemplate, coins))
+			event.Message = ps.narrate(pet, Narrative{Kind: kind, Coins: coins})
 			event.Data.Coins = coins
 		}
 	}
 
+	// 消息中附上宠物所在地点的时间和天气
+	event.Message = fmt.Sprintf("%s【%s】", event.Message, conditionsAt(pet.Location, event.Timestamp).Label())
+
 	return event
 }
 
 func (ps *PetService) simulateBattle(pet *models.Pet, monster models.Monster) bool {
+	return battleWins(pet, monster, rand.Intn)
+}
+
+// battleWins 按双方战力和随机数判定胜负，intn 为随机数来源
+func battleWins(pet *models.Pet, monster models.Monster, intn func(n int) int) bool {
 	petPower := pet.Attack + pet.Defense + pet.Level*2
 	monsterPower := monster.Attack + monster.Defense
 	
@@ -161,7 +185,7 @@ func (ps *PetService) simulateBattle(pet *models.Pet, monster models.Monster) bo
 		personalityBonus = 3
 	}
 	
-	return (petPower + personalityBonus + rand.Intn(20)) > (monsterPower + rand.Intn(15))
+	return (petPower + personalityBonus + intn(20)) > (monsterPower + intn(15))
 }
 
 func (ps *PetService) addEvent(event models.Event) {
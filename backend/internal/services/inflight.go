@@ -0,0 +1,327 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+// actionProgressInterval 推送行动进度的间隔
+const actionProgressInterval = 5 * time.Second
+
+const NotificationActionProgress = "action_progress"
+
+// actionCompletion 行动到期后的完成逻辑，调用方需持有锁
+type actionCompletion func(ps *PetService, pet *models.Pet, action *models.PetAction)
+
+// actionCompletions 各类限时行动的完成逻辑，重启后恢复的行动也通过这里完成
+var actionCompletions = map[ActionType]actionCompletion{
+	ActionExplore:   (*PetService).completeExploreAction,
+	ActionRest:      (*PetService).completeRestAction,
+	ActionSocialize: (*PetService).completeSocializeAction,
+	ActionEat:       (*PetService).completeEatAction,
+	ActionBank:      (*PetService).completeBankAction,
+	ActionCraft:     (*PetService).completeCraftAction,
+}
+
+// actionCancellation 行动被中途打断时按完成比例结算，调用方需持有锁
+type actionCancellation func(ps *PetService, pet *models.Pet, action *models.PetAction, progress float64) string
+
+// actionCancellations 各类限时行动被取消或召回时的部分结算，返回结算说明
+var actionCancellations = map[ActionType]actionCancellation{
+	ActionExplore:   (*PetService).cancelExploreAction,
+	ActionRest:      (*PetService).cancelRestAction,
+	ActionSocialize: (*PetService).cancelSocializeAction,
+	ActionEat:       (*PetService).cancelEatAction,
+	ActionBank:      (*PetService).cancelBankAction,
+	ActionCraft:     (*PetService).cancelCraftAction,
+}
+
+// ActionTracker 记录每只宠物进行中的限时行动，由 PetService 的主锁保护
+type ActionTracker struct {
+	repo     *database.ActionRepository
+	inflight map[string]*models.PetAction
+	timers   map[string]*time.Timer
+}
+
+// NewActionTracker 创建行动记录并加载重启前尚未完成的行动
+func NewActionTracker() *ActionTracker {
+	at := &ActionTracker{
+		repo:     database.NewActionRepository(),
+		inflight: make(map[string]*models.PetAction),
+		timers:   make(map[string]*time.Timer),
+	}
+
+	actions, err := at.repo.GetActiveActions()
+	if err != nil {
+		log.Printf("Warning: failed to load in-flight actions from database: %v", err)
+		return at
+	}
+	for _, action := range actions {
+		at.inflight[action.PetID] = action
+	}
+
+	log.Printf("Loaded %d in-flight actions from database", len(actions))
+	return at
+}
+
+// recordAction 把行动的当前状态随宠物一起写入，finishedAt 为空表示仍在进行
+func (ps *PetService) recordAction(action *models.PetAction, finishedAt *time.Time) {
+	dbAction, err := database.ConvertToDBPetAction(action, finishedAt)
+	if err != nil {
+		log.Printf("Failed to convert action %s: %v", action.ID, err)
+		return
+	}
+	ps.ledger.addRecord(action.PetID, dbAction)
+}
+
+// beginAction 宠物开始一项限时行动：切换状态、持久化并在到期时完成，调用方需持有锁
+func (ps *PetService) beginAction(pet *models.Pet, actionType ActionType, status models.PetStatus, reason string, params map[string]interface{}, duration int) *models.PetAction {
+	// 上一个行动尚未结束时先按进度结算，不让它的记录一直停留在进行中
+	if previous, exists := ps.actions.inflight[pet.ID]; exists {
+		if _, _, _, err := ps.cancelAction(pet); err != nil {
+			// 状态已被其他逻辑改变，只把它标记为结束
+			if timer, exists := ps.actions.timers[pet.ID]; exists {
+				timer.Stop()
+			}
+			delete(ps.actions.inflight, pet.ID)
+			delete(ps.actions.timers, pet.ID)
+			now := time.Now()
+			ps.recordAction(previous, &now)
+		}
+	}
+
+	action := &models.PetAction{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		Type:      string(actionType),
+		Status:    status,
+		Reason:    reason,
+		Params:    params,
+		StartedAt: time.Now(),
+		Duration:  duration,
+	}
+
+	pet.Status = status
+	ps.actions.inflight[pet.ID] = action
+	ps.recordAction(action, nil)
+	ps.savePetToDatabase(pet)
+	ps.scheduleAction(action)
+	return action
+}
+
+// scheduleAction 在行动到期时完成它
+func (ps *PetService) scheduleAction(action *models.PetAction) {
+	remaining := time.Until(action.EndsAt())
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if timer, exists := ps.actions.timers[action.PetID]; exists {
+		timer.Stop()
+	}
+	ps.actions.timers[action.PetID] = time.AfterFunc(remaining, func() {
+		ps.mutex.Lock()
+		defer ps.mutex.Unlock()
+		// 服务停止后到期的行动留给下次启动时恢复
+		if ps.stopped() {
+			return
+		}
+		ps.finishAction(action.PetID, action.ID)
+	})
+}
+
+// finishAction 行动到期，执行对应的完成逻辑，调用方需持有锁
+func (ps *PetService) finishAction(petID, actionID string) {
+	action, exists := ps.actions.inflight[petID]
+	if !exists || action.ID != actionID {
+		return
+	}
+	delete(ps.actions.inflight, petID)
+	delete(ps.actions.timers, petID)
+
+	now := time.Now()
+	ps.recordAction(action, &now)
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return
+	}
+
+	// 状态已被其他逻辑改变时不再完成，只把行动标记为结束
+	complete, known := actionCompletions[ActionType(action.Type)]
+	if known && pet.Status == action.Status {
+		before := snapshotOutcome(pet)
+		complete(ps, pet, action)
+		learnFromOutcome(pet, action, before)
+	} else if pet.Status == action.Status {
+		pet.Status = models.StatusIdle
+	}
+	ps.savePetToDatabase(pet)
+	ps.advanceQueue(pet)
+}
+
+// cancelAction 中途打断宠物进行中的行动，按已完成的比例结算后回到空闲，调用方需持有锁
+func (ps *PetService) cancelAction(pet *models.Pet) (*models.PetAction, float64, string, error) {
+	action, exists := ps.actions.inflight[pet.ID]
+	if !exists || pet.Status != action.Status {
+		return nil, 0, "", fmt.Errorf("宠物当前没有可以取消的行动（状态: %s）", pet.Status)
+	}
+
+	if timer, exists := ps.actions.timers[pet.ID]; exists {
+		timer.Stop()
+	}
+	delete(ps.actions.inflight, pet.ID)
+	delete(ps.actions.timers, pet.ID)
+
+	now := time.Now()
+	progress := action.Progress(now)
+	ps.recordAction(action, &now)
+
+	pet.Status = models.StatusIdle
+	var outcome string
+	if cancel, known := actionCancellations[ActionType(action.Type)]; known {
+		outcome = cancel(ps, pet, action, progress)
+	}
+	return action, progress, outcome, nil
+}
+
+// interruptAction 打断行动并发出事件，recall 时宠物回到起始村庄，调用方需持有锁
+func (ps *PetService) interruptAction(pet *models.Pet, cause string, recall bool) (*models.PetAction, float64, error) {
+	action, progress, outcome, err := ps.cancelAction(pet)
+	if err != nil {
+		return nil, 0, err
+	}
+	if recall && pet.Location != bankLocation {
+		pet.Location = bankLocation
+		ps.stateManager.UpdateLocation(pet.ID, bankLocation)
+	}
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventReward,
+		Message:   ps.narrate(pet, Narrative{Kind: NarrateInterrupted, Cause: cause, Activity: string(action.Status), Percent: int(progress * 100), Outcome: outcome}),
+		Timestamp: time.Now(),
+		Data:      models.EventData{Location: pet.Location},
+	})
+	ps.savePetToDatabase(pet)
+
+	// 召回时放弃整个计划，只是中止时继续下一步
+	if recall {
+		ps.clearQueue(pet, false)
+	} else {
+		ps.advanceQueue(pet)
+	}
+	return action, progress, nil
+}
+
+// recoverAction 重启后处理宠物停机前的行动：已到期的立即完成，未到期的继续计时。
+// 返回行动是否仍在进行，调用方需持有锁
+func (ps *PetService) recoverAction(pet *models.Pet, now time.Time) bool {
+	action, exists := ps.actions.inflight[pet.ID]
+	if !exists {
+		// 没有行动记录却停在行动状态（如升级前遗留的数据），直接恢复空闲
+		if pet.IsAlive() && pet.Status != models.StatusIdle {
+			log.Printf("Pet %s was stranded in status %s, returning to idle", pet.Name, pet.Status)
+			pet.Status = models.StatusIdle
+			ps.savePetToDatabase(pet)
+		}
+		return false
+	}
+
+	// 以停机前记录的行动为准，完成逻辑会检查状态
+	pet.Status = action.Status
+
+	if endsAt := action.EndsAt(); !now.Before(endsAt) {
+		ps.finishAction(pet.ID, action.ID)
+		// 离线进度从行动结束时算起
+		if pet.SimulatedAt.Before(endsAt) {
+			pet.SimulatedAt = endsAt
+		}
+		return false
+	}
+
+	ps.scheduleAction(action)
+	log.Printf("Resumed %s action for pet %s (%s remaining)", action.Type, pet.Name, action.EndsAt().Sub(now).Round(time.Second))
+	return true
+}
+
+// currentAction 宠物进行中行动的进度，没有行动时返回 nil，调用方需持有锁
+func (ps *PetService) currentAction(pet *models.Pet, now time.Time) *models.ActionProgress {
+	action, exists := ps.actions.inflight[pet.ID]
+	if !exists || pet.Status != action.Status {
+		return nil
+	}
+	progress := action.ProgressAt(now)
+	return &progress
+}
+
+// nextQueuedAction 计划中下一步尚未开始的行动，调用方需持有锁
+func nextQueuedAction(pet *models.Pet) *models.QueuedAction {
+	for i := range pet.ActionQueue {
+		if !pet.ActionQueue[i].Running() {
+			step := pet.ActionQueue[i]
+			return &step
+		}
+	}
+	return nil
+}
+
+// runActionProgress 定期推送所有进行中行动的进度，供客户端绘制进度条
+func (ps *PetService) runActionProgress() {
+	ticker := time.NewTicker(actionProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ps.quit:
+			return
+		case <-ticker.C:
+		}
+		ps.mutex.RLock()
+		notification, ok := ps.actionProgressNotification(time.Now())
+		ps.mutex.RUnlock()
+
+		if ok {
+			ps.notify(notification)
+		}
+	}
+}
+
+// actionProgressNotification 所有进行中行动的进度推送，没有行动时返回 false，调用方需持有锁
+func (ps *PetService) actionProgressNotification(now time.Time) (Notification, bool) {
+	progress := make([]models.ActionProgress, 0, len(ps.actions.inflight))
+	for petID := range ps.actions.inflight {
+		if pet, exists := ps.pets[petID]; exists {
+			if current := ps.currentAction(pet, now); current != nil {
+				progress = append(progress, *current)
+			}
+		}
+	}
+	if len(progress) == 0 {
+		return Notification{}, false
+	}
+	return Notification{
+		Type:      NotificationActionProgress,
+		Message:   fmt.Sprintf("%d 个行动进行中", len(progress)),
+		Data:      progress,
+		Timestamp: now,
+	}, true
+}
+
+// paramInt 读取整数参数，兼容直接传入的 int 和 JSON 解码后的 float64
+func paramInt(params map[string]interface{}, key string) int {
+	switch v := params[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
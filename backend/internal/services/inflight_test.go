@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+)
+
+// TestRecoverActions 重启后到期的行动立即完成并补算离线进度，未到期的行动继续计时且不补算
+func TestRecoverActions(t *testing.T) {
+	ps := newTestPetService(t)
+	busy := newTestPet(t, ps, "busy", models.PersonalityCautious)
+	done := newTestPet(t, ps, "done", models.PersonalityCautious)
+
+	ps.mutex.Lock()
+	offline := time.Now().Add(-3 * time.Hour)
+	ps.beginAction(busy, ActionRest, models.StatusResting, "", nil, 3600)
+	busy.SimulatedAt = offline
+	ps.savePetToDatabase(busy)
+
+	// 行动在停机期间就已到期
+	finished := ps.beginAction(done, ActionRest, models.StatusResting, "", nil, 600)
+	finished.StartedAt = offline
+	ps.recordAction(finished, nil)
+	done.SimulatedAt = offline
+	ps.savePetToDatabase(done)
+
+	ps.mutex.Unlock()
+
+	// 模拟停机：旧服务的定时器不再触发
+	ps.Stop()
+	database.FlushBatchManagers()
+
+	restarted := NewPetService()
+	t.Cleanup(restarted.Stop)
+	restarted.mutex.Lock()
+	defer restarted.mutex.Unlock()
+
+	pet := restarted.pets[busy.ID]
+	if _, inflight := restarted.actions.inflight[busy.ID]; !inflight || pet.Status != models.StatusResting {
+		t.Errorf("Expected unfinished action to resume, got status %s", pet.Status)
+	}
+	if _, scheduled := restarted.actions.timers[busy.ID]; !scheduled {
+		t.Error("Expected resumed action to be rescheduled")
+	}
+	if !pet.SimulatedAt.Equal(busy.SimulatedAt) {
+		t.Errorf("Expected no catch-up while an action is in flight, got simulated to %v", pet.SimulatedAt)
+	}
+
+	pet = restarted.pets[done.ID]
+	if _, inflight := restarted.actions.inflight[done.ID]; inflight || pet.Status != models.StatusIdle {
+		t.Errorf("Expected expired action to complete on recovery, got status %s", pet.Status)
+	}
+	if !pet.SimulatedAt.After(finished.EndsAt()) {
+		t.Errorf("Expected catch-up after the action ended at %v, got simulated to %v", finished.EndsAt(), pet.SimulatedAt)
+	}
+
+	database.FlushBatchManagers()
+	active, err := restarted.actions.repo.GetActiveActions()
+	if err != nil {
+		t.Fatalf("Failed to get active actions: %v", err)
+	}
+	if len(active) != 1 || active[0].PetID != busy.ID {
+		t.Errorf("Expected only the resumed action stored as active, got %d", len(active))
+	}
+}
+
+// TestBeginActionSettlesPrevious 行动进行中又开始新行动时，旧行动先结算并标记为结束，不会在重启后被恢复
+func TestBeginActionSettlesPrevious(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "restless", models.PersonalityCurious)
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet.Energy = 20
+	ps.beginAction(pet, ActionRest, models.StatusResting, "", nil, 100)
+	rewindAction(t, ps, pet, 0.5)
+	explore := ps.beginAction(pet, ActionExplore, models.StatusExploring, "", map[string]interface{}{"location": pet.Location}, 100)
+	if pet.Energy <= 20 {
+		t.Errorf("Expected the interrupted rest to restore some energy, got %d", pet.Energy)
+	}
+
+	// 状态被其他逻辑改掉的行动不再结算，只标记为结束
+	pet.Status = models.StatusIdle
+	rest := ps.beginAction(pet, ActionRest, models.StatusResting, "", nil, 100)
+
+	database.FlushBatchManagers()
+	actions, err := ps.actions.repo.GetActiveActions()
+	if err != nil {
+		t.Fatalf("Failed to load active actions: %v", err)
+	}
+	if len(actions) != 1 || actions[0].ID != rest.ID {
+		t.Errorf("Expected only the rest action %s active, got %+v (explore %s)", rest.ID, actions, explore.ID)
+	}
+	if len(ps.actions.timers) != 1 || ps.actions.inflight[pet.ID] != rest {
+		t.Errorf("Expected a single tracked action, got %d timers", len(ps.actions.timers))
+	}
+	assertReconciled(t, ps)
+}
+
+// TestActionProgressNotification 进度推送只包含仍在进行的行动，带有完成比例、剩余时间和预计完成时间
+func TestActionProgressNotification(t *testing.T) {
+	ps := newTestPetService(t)
+	busy := newTestPet(t, ps, "busy", models.PersonalityCautious)
+	other := newTestPet(t, ps, "other", models.PersonalityCurious)
+
+	ps.mutex.Lock()
+	now := time.Now()
+	if _, ok := ps.actionProgressNotification(now); ok {
+		t.Error("Expected no progress notification without actions in flight")
+	}
+
+	action := ps.beginAction(busy, ActionRest, models.StatusResting, "休息一下", nil, 100)
+	action.StartedAt = now.Add(-50 * time.Second)
+	// 状态已被其他逻辑改变的行动不再推送
+	ps.beginAction(other, ActionSocialize, models.StatusSocializing, "", nil, 100)
+	other.Status = models.StatusIdle
+
+	notification, ok := ps.actionProgressNotification(now)
+	ps.mutex.Unlock()
+	if !ok || notification.Type != NotificationActionProgress || !notification.Timestamp.Equal(now) {
+		t.Fatalf("Expected an action progress notification, got %+v", notification)
+	}
+	progress, ok := notification.Data.([]models.ActionProgress)
+	if !ok || len(progress) != 1 {
+		t.Fatalf("Expected progress for the resting pet only, got %+v", notification.Data)
+	}
+	expected := models.ActionProgress{
+		PetID:     busy.ID,
+		ActionID:  action.ID,
+		Type:      string(ActionRest),
+		Status:    models.StatusResting,
+		Reason:    "休息一下",
+		StartedAt: action.StartedAt,
+		EndsAt:    action.StartedAt.Add(100 * time.Second),
+		Duration:  100,
+		Percent:   50,
+		Remaining: 50,
+	}
+	if progress[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, progress[0])
+	}
+
+	status, err := ps.GetPetStatus(busy.ID)
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	if current, ok := status["current_action"].(*models.ActionProgress); !ok || current == nil || current.ActionID != action.ID {
+		t.Errorf("Expected the status to report the current action, got %+v", status["current_action"])
+	}
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+)
+
+// LedgerService 金币账本服务
+// 每一次金币变化都会生成一条分录，并与宠物数据在同一事务中落库
+type LedgerService struct {
+	repo       *database.LedgerRepository
+	walletRepo *database.WalletRepository
+
+	// 尚未落库的账本变化，随下一次宠物保存一并写入
+	pending map[string]*database.LedgerChanges
+	// 最近一次对账结果
+	lastReport *LedgerReconcileReport
+	mutex      sync.Mutex
+}
+
+// LedgerMismatch 对账差异
+type LedgerMismatch struct {
+	PetID         string `json:"pet_id"`
+	PetCoins      int    `json:"pet_coins"`
+	LedgerBalance int    `json:"ledger_balance"`
+	Difference    int    `json:"difference"`
+}
+
+// WalletMismatch 钱包对账差异
+type WalletMismatch struct {
+	Owner         string `json:"owner"`
+	WalletBalance int    `json:"wallet_balance"`
+	LedgerBalance int    `json:"ledger_balance"`
+	Difference    int    `json:"difference"`
+}
+
+// LedgerReconcileReport 对账报告
+type LedgerReconcileReport struct {
+	CheckedAt        time.Time        `json:"checked_at"`
+	PetCount         int              `json:"pet_count"`
+	WalletCount      int              `json:"wallet_count"`
+	Mismatches       []LedgerMismatch `json:"mismatches"`
+	WalletMismatches []WalletMismatch `json:"wallet_mismatches"`
+}
+
+// NewLedgerService 创建账本服务
+func NewLedgerService() *LedgerService {
+	return &LedgerService{
+		repo:       database.NewLedgerRepository(),
+		walletRepo: database.NewWalletRepository(),
+		pending:    make(map[string]*database.LedgerChanges),
+	}
+}
+
+func (ls *LedgerService) pendingFor(petID string) *database.LedgerChanges {
+	pending, exists := ls.pending[petID]
+	if !exists {
+		pending = &database.LedgerChanges{}
+		ls.pending[petID] = pending
+	}
+	return pending
+}
+
+func (ls *LedgerService) addPending(entry *models.LedgerEntry) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	pending := ls.pendingFor(entry.PetID)
+	pending.Entries = append(pending.Entries, entry)
+}
+
+// addWalletDelta 登记一笔与宠物一起落库的钱包余额变化
+func (ls *LedgerService) addWalletDelta(petID, owner string, delta int) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	pending := ls.pendingFor(petID)
+	pending.WalletDeltas = append(pending.WalletDeltas, database.WalletDelta{Owner: owner, Delta: delta})
+}
+
+// addRecord 登记一条与宠物一起落库的记录快照，按登记顺序写入，后登记的覆盖先登记的
+func (ls *LedgerService) addRecord(petID string, record interface{}) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	pending := ls.pendingFor(petID)
+	pending.Records = append(pending.Records, record)
+}
+
+// takePending 取出宠物所有待写的账本变化
+func (ls *LedgerService) takePending(petID string) database.LedgerChanges {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	pending, exists := ls.pending[petID]
+	if !exists {
+		return database.LedgerChanges{}
+	}
+	delete(ls.pending, petID)
+	return *pending
+}
+
+// restorePending 把没能落库的账本变化放回待写队列，排在之后登记的变化之前，随下一次保存重试
+func (ls *LedgerService) restorePending(petID string, changes database.LedgerChanges) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	pending := ls.pendingFor(petID)
+	pending.Entries = append(changes.Entries, pending.Entries...)
+	pending.WalletDeltas = append(changes.WalletDeltas, pending.WalletDeltas...)
+	pending.Records = append(changes.Records, pending.Records...)
+}
+
+// ensureOpeningBalances 为账本上线前已有金币、但没有任何分录的宠物补记期初余额
+func (ls *LedgerService) ensureOpeningBalances(pets []*models.Pet) error {
+	balances, err := ls.repo.GetPetBalances()
+	if err != nil {
+		return err
+	}
+
+	var entries []*models.LedgerEntry
+	for _, pet := range pets {
+		if _, hasEntries := balances[pet.ID]; hasEntries || pet.Coins == 0 {
+			continue
+		}
+		entries = append(entries, openingEntry(pet))
+	}
+
+	if err := ls.repo.CreateEntries(entries); err != nil {
+		return err
+	}
+
+	if len(entries) > 0 {
+		log.Printf("Recorded opening ledger balances for %d pets", len(entries))
+	}
+	return nil
+}
+
+func openingEntry(pet *models.Pet) *models.LedgerEntry {
+	if pet.Coins > 0 {
+		return models.NewLedgerEntry(pet.ID, models.PetAccount(pet.ID), models.AccountMint, pet.Coins, models.ReasonOpening, "期初余额")
+	}
+	return models.NewLedgerEntry(pet.ID, models.AccountMint, models.PetAccount(pet.ID), -pet.Coins, models.ReasonOpening, "期初余额")
+}
+
+// Reconcile 核对数据库中每只宠物的金币与账本余额
+func (ls *LedgerService) Reconcile() (*LedgerReconcileReport, error) {
+	coins, err := ls.repo.GetPersistedPetCoins()
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := ls.repo.GetPetBalances()
+	if err != nil {
+		return nil, err
+	}
+
+	wallets, err := ls.walletRepo.GetPersistedWalletBalances()
+	if err != nil {
+		return nil, err
+	}
+
+	walletBalances, err := ls.repo.GetAccountBalances(string(models.WalletAccount("")))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LedgerReconcileReport{
+		CheckedAt:        time.Now(),
+		PetCount:         len(coins),
+		WalletCount:      len(wallets),
+		Mismatches:       make([]LedgerMismatch, 0),
+		WalletMismatches: make([]WalletMismatch, 0),
+	}
+
+	for petID, petCoins := range coins {
+		balance := balances[petID]
+		if balance != petCoins {
+			report.Mismatches = append(report.Mismatches, LedgerMismatch{
+				PetID:         petID,
+				PetCoins:      petCoins,
+				LedgerBalance: balance,
+				Difference:    petCoins - balance,
+			})
+		}
+	}
+
+	for owner, walletBalance := range wallets {
+		balance := walletBalances[models.WalletAccount(owner)]
+		if balance != walletBalance {
+			report.WalletMismatches = append(report.WalletMismatches, WalletMismatch{
+				Owner:         owner,
+				WalletBalance: walletBalance,
+				LedgerBalance: balance,
+				Difference:    walletBalance - balance,
+			})
+		}
+	}
+
+	ls.mutex.Lock()
+	ls.lastReport = report
+	ls.mutex.Unlock()
+
+	return report, nil
+}
+
+// LastReport 获取最近一次对账结果
+func (ls *LedgerService) LastReport() *LedgerReconcileReport {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	return ls.lastReport
+}
+
+// recordLedgerEntry 登记一条分录，等待随宠物一起落库，落库后才计入经济遥测
+func (ps *PetService) recordLedgerEntry(entry *models.LedgerEntry) {
+	ps.ledger.addPending(entry)
+}
+
+// recordEconomy 已落库的分录计入经济遥测
+func (ps *PetService) recordEconomy(entries []*models.LedgerEntry) {
+	for _, entry := range entries {
+		ps.economy.Record(entry)
+	}
+}
+
+// creditCoins 从指定账户向宠物转入金币
+func (ps *PetService) creditCoins(pet *models.Pet, amount int, from models.LedgerAccount, reason models.LedgerReason, memo string) {
+	if amount <= 0 {
+		return
+	}
+	pet.Coins += amount
+	ps.recordLedgerEntry(models.NewLedgerEntry(pet.ID, models.PetAccount(pet.ID), from, amount, reason, memo))
+
+	if from == models.AccountMint && isGarnishable(reason) {
+		ps.garnishReward(pet, amount)
+	}
+}
+
+// debitCoins 从宠物向指定账户转出金币
+func (ps *PetService) debitCoins(pet *models.Pet, amount int, to models.LedgerAccount, reason models.LedgerReason, memo string) {
+	if amount <= 0 {
+		return
+	}
+	pet.Coins -= amount
+	ps.recordLedgerEntry(models.NewLedgerEntry(pet.ID, to, models.PetAccount(pet.ID), amount, reason, memo))
+}
+
+// ReconcileLedger 立即执行一次对账
+func (ps *PetService) ReconcileLedger() (*LedgerReconcileReport, error) {
+	return ps.ledger.Reconcile()
+}
+
+// runLedgerReconciliation 定期对账
+func (ps *PetService) runLedgerReconciliation() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ps.quit:
+			return
+		case <-ticker.C:
+		}
+		report, err := ps.ledger.Reconcile()
+		if err != nil {
+			log.Printf("Warning: ledger reconciliation failed: %v", err)
+			continue
+		}
+		for _, mismatch := range report.Mismatches {
+			log.Printf("Ledger mismatch: pet %s coins=%d ledger=%d diff=%d",
+				mismatch.PetID, mismatch.PetCoins, mismatch.LedgerBalance, mismatch.Difference)
+		}
+		for _, mismatch := range report.WalletMismatches {
+			log.Printf("Ledger mismatch: wallet %s balance=%d ledger=%d diff=%d",
+				mismatch.Owner, mismatch.WalletBalance, mismatch.LedgerBalance, mismatch.Difference)
+		}
+	}
+}
+
+// GetPetLedger 分页获取宠物的账本分录
+func (ps *PetService) GetPetLedger(petID string, limit, offset int) (map[string]interface{}, error) {
+	pet, exists := ps.GetPet(petID)
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	account := models.PetAccount(pet.ID)
+	entries, total, err := ps.ledger.repo.GetEntriesByAccount(account, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.mutex.RLock()
+	coins := pet.Coins
+	ps.mutex.RUnlock()
+
+	return map[string]interface{}{
+		"pet_id":  pet.ID,
+		"account": account,
+		"coins":   coins,
+		"entries": entries,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	}, nil
+}
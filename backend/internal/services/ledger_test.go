@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+)
+
+// TestLedgerWriteFailureRestoresPending 批量写入回滚时分录放回待写队列，下一次保存时重试
+func TestLedgerWriteFailureRestoresPending(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "saver", models.PersonalityGreedy)
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.creditCoins(pet, 10, models.AccountMint, models.ReasonAddCoins, "")
+	committed := ps.ledger.pending[pet.ID].Entries[0]
+	ps.savePetToDatabase(pet)
+	database.FlushBatchManagers()
+
+	// 与已落库的分录主键冲突，整个写入回滚
+	ps.creditCoins(pet, 20, models.AccountMint, models.ReasonReward, "")
+	duplicate := ps.ledger.pending[pet.ID].Entries[0]
+	id := duplicate.ID
+	duplicate.ID = committed.ID
+	ps.savePetToDatabase(pet)
+	database.FlushBatchManagers()
+
+	if restored := pendingAmount(ps, pet.ID, models.ReasonReward); restored != 20 {
+		t.Fatalf("Expected the failed entry back in pending, got %d", restored)
+	}
+
+	duplicate.ID = id
+	ps.savePetToDatabase(pet)
+	if pending := pendingAmount(ps, pet.ID, models.ReasonReward); pending != 0 {
+		t.Errorf("Expected the retried entry to be taken, got %d pending", pending)
+	}
+	assertReconciled(t, ps)
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// memoryLocationInfluence 对地点的回忆对地点评分的影响
+	memoryLocationInfluence = 0.6
+	// memorySocialInfluence 对其他宠物的美好回忆最多给社交优先级增加的数值
+	memorySocialInfluence = 30
+)
+
+// remember 记下一段经历并持久化，记忆满了时被遗忘的一条标记为已遗忘，调用方需持有锁并随后保存宠物
+func (ps *PetService) remember(pet *models.Pet, memory models.Memory) {
+	memory.ID = uuid.New().String()
+	memory.PetID = pet.ID
+	if memory.CreatedAt.IsZero() {
+		memory.CreatedAt = time.Now()
+	}
+	memory.Valence = clampInt(memory.Valence, -100, 100)
+	memory.Importance = clampInt(memory.Importance, 1, 100)
+
+	forgotten := pet.AddMemory(memory)
+	if forgotten == nil || forgotten.ID != memory.ID {
+		ps.ledger.addRecord(pet.ID, database.ConvertToDBPetMemory(&memory, false))
+	}
+	if forgotten != nil {
+		ps.ledger.addRecord(pet.ID, database.ConvertToDBPetMemory(forgotten, true))
+	}
+}
+
+func clampInt(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+// memoryFromEvent 探索中值得记住的遭遇，平淡的事件不会留下记忆
+func memoryFromEvent(pet *models.Pet, event models.Event) (models.Memory, bool) {
+	// 去掉消息末尾的时间天气标签
+	summary := event.Message
+	if i := strings.LastIndex(summary, "【"); i > 0 {
+		summary = summary[:i]
+	}
+	memory := models.Memory{Summary: summary, Location: pet.Location, CreatedAt: event.Timestamp}
+
+	switch event.Type {
+	case models.EventBattle:
+		if event.Data.IsVictory {
+			memory.Kind, memory.Valence, memory.Importance = models.MemoryBattleWon, 40, 30
+		} else {
+			// 伤得越重越难忘
+			hurt := 0
+			if pet.MaxHealth > 0 {
+				hurt = (pet.MaxHealth - pet.Health) * 100 / pet.MaxHealth
+			}
+			memory.Kind, memory.Valence, memory.Importance = models.MemoryBattleLost, -30-hurt/2, 30+hurt/2
+		}
+	case models.EventRareFind:
+		memory.Kind, memory.Valence, memory.Importance = models.MemoryRareFind, 80, 90
+	case models.EventDiscovery:
+		if event.Data.Coins == 0 {
+			return models.Memory{}, false
+		}
+		memory.Kind, memory.Valence, memory.Importance = models.MemoryDiscovery, 25, 15
+	case models.EventSocial:
+		memory.Kind, memory.Valence, memory.Importance = models.MemoryMeeting, 30, 20
+		memory.PartnerName = event.Data.FriendName
+	case models.EventBoss:
+		memory.Kind, memory.Valence, memory.Importance = models.MemoryBoss, 30, 60
+	default:
+		return models.Memory{}, false
+	}
+	return memory, true
+}
+
+// rememberExplore 记下探索的遭遇以及途中的升级，调用方需持有锁
+func (ps *PetService) rememberExplore(pet *models.Pet, event models.Event, levelBefore int) {
+	if memory, ok := memoryFromEvent(pet, event); ok {
+		ps.remember(pet, memory)
+	}
+	if pet.Level > levelBefore {
+		ps.remember(pet, models.Memory{
+			Kind:       models.MemoryLevelUp,
+			Summary:    fmt.Sprintf("在%s升到了%d级", pet.Location, pet.Level),
+			Location:   pet.Location,
+			Valence:    60,
+			Importance: 70,
+		})
+	}
+}
+
+// locationFeeling 宠物对地点的整体感受，范围 [-1, 1]
+func locationFeeling(pet *models.Pet, location string, now time.Time) float64 {
+	feeling := 0.0
+	for _, memory := range pet.Memories {
+		if memory.Location == location {
+			feeling += memory.Feeling(now)
+		}
+	}
+	return math.Max(-1, math.Min(1, feeling))
+}
+
+// partnerFeelings 宠物对交往过的其他宠物的感受，键为宠物 ID
+func partnerFeelings(pet *models.Pet, now time.Time) map[string]float64 {
+	feelings := make(map[string]float64)
+	for _, memory := range pet.Memories {
+		if memory.PartnerID != "" {
+			feelings[memory.PartnerID] += memory.Feeling(now)
+		}
+	}
+	return feelings
+}
+
+// choosePartner 挑选社交对象：优先找相处愉快的宠物，避开有不愉快回忆的，调用方需持有锁
+func (ps *PetService) choosePartner(pet *models.Pet) *models.Pet {
+	feelings := partnerFeelings(pet, time.Now())
+
+	var partner *models.Pet
+	best := 0.0
+	for _, other := range ps.pets {
+		if other.ID == pet.ID || !other.IsAlive() {
+			continue
+		}
+		feeling := feelings[other.ID]
+		if feeling < 0 {
+			continue
+		}
+		if partner == nil || feeling > best {
+			partner, best = other, feeling
+		}
+	}
+	return partner
+}
+
+// fondestFeeling 宠物对交往过的宠物最美好的感受，没有美好回忆时为 0
+func fondestFeeling(pet *models.Pet, now time.Time) float64 {
+	fondest := 0.0
+	for _, feeling := range partnerFeelings(pet, now) {
+		fondest = math.Max(fondest, feeling)
+	}
+	return math.Min(1, fondest)
+}
+
+// RecalledMemory 查询结果中的一条记忆及其当前的印象深浅
+type RecalledMemory struct {
+	models.Memory
+	Strength  float64 `json:"strength"`
+	Forgotten bool    `json:"forgotten"`
+}
+
+// MemoryFilter 记忆查询条件，空字段表示不限制
+type MemoryFilter struct {
+	Kind      string
+	Location  string
+	PartnerID string
+	// Forgotten 查询已被遗忘的记忆
+	Forgotten bool
+	// SortBy 为 "strength" 时按印象深浅排序，否则最近的在前
+	SortBy string
+	Limit  int
+}
+
+// QueryMemories 查询宠物的记忆：默认是仍记得的记忆，Forgotten 时从数据库查询已遗忘的
+func (ps *PetService) QueryMemories(petID string, filter MemoryFilter) ([]RecalledMemory, error) {
+	ps.mutex.RLock()
+	pet, exists := ps.pets[petID]
+	var memories []models.Memory
+	if exists && !filter.Forgotten {
+		for _, memory := range pet.Memories {
+			if (filter.Kind == "" || string(memory.Kind) == filter.Kind) &&
+				(filter.Location == "" || memory.Location == filter.Location) &&
+				(filter.PartnerID == "" || memory.PartnerID == filter.PartnerID) {
+				memories = append(memories, memory)
+			}
+		}
+	}
+	ps.mutex.RUnlock()
+
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+	if filter.Forgotten {
+		var err error
+		memories, err = ps.memories.QueryMemories(petID, database.MemoryQuery{
+			Kind:      filter.Kind,
+			Location:  filter.Location,
+			PartnerID: filter.PartnerID,
+			Forgotten: true,
+			Limit:     filter.Limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	recalled := make([]RecalledMemory, 0, len(memories))
+	for _, memory := range memories {
+		recalled = append(recalled, RecalledMemory{Memory: memory, Strength: math.Round(memory.Strength(now)*10) / 10, Forgotten: filter.Forgotten})
+	}
+	if filter.SortBy == "strength" {
+		sort.SliceStable(recalled, func(i, j int) bool { return recalled[i].Strength > recalled[j].Strength })
+	} else {
+		sort.SliceStable(recalled, func(i, j int) bool { return recalled[i].CreatedAt.After(recalled[j].CreatedAt) })
+	}
+	if filter.Limit > 0 && len(recalled) > filter.Limit {
+		recalled = recalled[:filter.Limit]
+	}
+	return recalled, nil
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"miningpet/internal/models"
+)
+
+// NarrativeKind 需要叙述的事件种类
+type NarrativeKind string
+
+const (
+	NarrateExplore           NarrativeKind = "explore"            // 来到新的地点
+	NarrateExploreIntent     NarrativeKind = "explore_intent"     // 想要出门探索的理由
+	NarrateBattleWon         NarrativeKind = "battle_won"         // 打败怪物
+	NarrateBattleLost        NarrativeKind = "battle_lost"        // 被怪物打败
+	NarrateDiscovery         NarrativeKind = "discovery"          // 发现物品和金币
+	NarrateDiscoveryDepleted NarrativeKind = "discovery_depleted" // 发现物品但矿脉已空
+	NarrateSocial            NarrativeKind = "social"             // 路上结识朋友
+	NarrateRareFind          NarrativeKind = "rare_find"          // 稀有发现
+	NarrateReward            NarrativeKind = "reward"             // 捡到金币
+	NarrateRewardDepleted    NarrativeKind = "reward_depleted"    // 矿脉枯竭
+	NarrateRestDone          NarrativeKind = "rest_done"          // 休息完毕
+	NarrateSocialDone        NarrativeKind = "social_done"        // 社交结束
+	NarrateLonelinessEased   NarrativeKind = "loneliness_eased"   // 社交结束，孤独得到缓解
+	NarrateChat              NarrativeKind = "chat"               // 和附近的宠物交流
+	NarrateEatItem           NarrativeKind = "eat_item"           // 吃掉背包里的食物
+	NarrateForage            NarrativeKind = "forage"             // 出发寻找免费的食物
+	NarrateForaged           NarrativeKind = "foraged"            // 找到免费的食物
+	NarrateBuyFood           NarrativeKind = "buy_food"           // 出发购买食物
+	NarrateMeal              NarrativeKind = "meal"               // 吃完买来的食物
+	NarrateStarving          NarrativeKind = "starving"           // 因饥饿受伤
+	NarrateBankDeposit       NarrativeKind = "bank_deposit"       // 存款
+	NarrateBankWithdraw      NarrativeKind = "bank_withdraw"      // 取款
+	NarrateInterest          NarrativeKind = "interest"           // 存款结息
+	NarrateLoan              NarrativeKind = "loan"               // 借款
+	NarrateLoanRepaid        NarrativeKind = "loan_repaid"        // 还款
+	NarrateLoanGarnished     NarrativeKind = "loan_garnished"     // 逾期贷款从奖励中扣款
+	NarrateLoanCleared       NarrativeKind = "loan_cleared"       // 还清贷款
+	NarrateLoanOverdue       NarrativeKind = "loan_overdue"       // 贷款逾期
+	NarrateAuctionListed     NarrativeKind = "auction_listed"     // 上架拍卖
+	NarrateAuctionBid        NarrativeKind = "auction_bid"        // 出价竞拍
+	NarrateAuctionUnsold     NarrativeKind = "auction_unsold"     // 流拍
+	NarrateAuctionSold       NarrativeKind = "auction_sold"       // 拍出，从卖家的角度叙述
+	NarrateBuyGear           NarrativeKind = "buy_gear"           // 在商店买装备
+	NarrateCraftStart        NarrativeKind = "craft_start"        // 开始制作
+	NarrateCrafted           NarrativeKind = "crafted"            // 制作成功
+	NarrateCraftFailed       NarrativeKind = "craft_failed"       // 制作失败
+	NarrateUseItem           NarrativeKind = "use_item"           // 使用物品
+	NarrateBossHit           NarrativeKind = "boss_hit"           // 攻击首领并受到反击
+	NarrateBossFinalBlow     NarrativeKind = "boss_final_blow"    // 击倒首领
+	NarrateBossReward        NarrativeKind = "boss_reward"        // 按伤害排名分得讨伐奖励
+	NarrateBossTopReward     NarrativeKind = "boss_top_reward"    // 伤害第一额外获得稀有物品
+	NarrateInterrupted       NarrativeKind = "interrupted"        // 行动被取消或召回
+	NarrateDigest            NarrativeKind = "digest"             // 离线期间的摘要
+)
+
+// Narrative 叙述一个事件所需的结构化数据，叙述结果不含宠物名前缀
+type Narrative struct {
+	Kind        NarrativeKind         `json:"kind"`
+	PetName     string                `json:"pet_name"`
+	Personality models.PetPersonality `json:"personality"`
+	Mood        models.PetMood        `json:"mood"`
+	Location    string                `json:"location,omitempty"`
+	Enemy       string                `json:"enemy,omitempty"`
+	Item        string                `json:"item,omitempty"`
+	Friend      string                `json:"friend,omitempty"`
+	Counterpart string                `json:"counterpart,omitempty"` // 交易的另一方
+	Cause       string                `json:"cause,omitempty"`       // 行动被打断的原因
+	Activity    string                `json:"activity,omitempty"`    // 被打断的行动
+	Outcome     string                `json:"outcome,omitempty"`     // 被打断的行动已有的结果
+	Elapsed     string                `json:"elapsed,omitempty"`     // 离线时长
+	Summary     string                `json:"summary,omitempty"`     // 离线期间的经历
+	Coins       int                   `json:"coins,omitempty"`
+	Experience  int                   `json:"experience,omitempty"`
+	Damage      int                   `json:"damage,omitempty"`
+	Taken       int                   `json:"taken,omitempty"` // 受到的反击伤害
+	Energy      int                   `json:"energy,omitempty"`
+	Hunger      int                   `json:"hunger,omitempty"`
+	Quantity    int                   `json:"quantity,omitempty"`
+	Balance     int                   `json:"balance,omitempty"` // 存款余额或剩余欠款
+	Fee         int                   `json:"fee,omitempty"`
+	Days        int                   `json:"days,omitempty"`
+	Percent     int                   `json:"percent,omitempty"`
+	Rank        int                   `json:"rank,omitempty"`
+	Attack      int                   `json:"attack,omitempty"`
+	Defense     int                   `json:"defense,omitempty"`
+	BossHP      int                   `json:"boss_hp,omitempty"`
+	BossMaxHP   int                   `json:"boss_max_hp,omitempty"`
+}
+
+// Narrator 把结构化的事件数据叙述为文字
+type Narrator interface {
+	Narrate(narrative Narrative) string
+}
+
+// AsyncNarrator 需要等待外部服务的叙述器。持有锁时只用 Draft 立即给出叙述，
+// 没有现成结果时再在锁外调用 Rewrite 改写，改写成功后更新事件消息
+type AsyncNarrator interface {
+	Narrator
+	// Draft 立即给出叙述，ready 为 true 时已是最终结果，无需改写
+	Draft(narrative Narrative) (text string, ready bool)
+	// Rewrite 改写 Draft 给出的叙述，可能阻塞，不可用时返回 false
+	Rewrite(narrative Narrative, draft string) (string, bool)
+}
+
+// narrationQueueSize 等待改写的事件消息数，队列满时事件保留模板叙述
+const narrationQueueSize = 100
+
+// narrationJob 一条等待改写的事件消息
+type narrationJob struct {
+	narrator  AsyncNarrator
+	petID     string
+	prefix    string // 宠物名前缀
+	draft     string
+	narrative Narrative
+}
+
+// narrationTemplates 各类事件的通用叙述，{name} 为事件数据或语法符号
+var narrationTemplates = map[NarrativeKind][]string{
+	NarrateExplore: {"来到了{location}，开始探索..."},
+	NarrateExploreIntent: {
+		"想要寻找新的冒险",
+		"对未知的地方充满好奇",
+		"希望找到一些宝藏",
+	},
+	NarrateBattleWon:  {"击败了{enemy}！获得经验+{exp}，金币+{coins}"},
+	NarrateBattleLost: {"被{enemy}击败，受到{damage}点伤害"},
+	NarrateDiscovery: {
+		"发现了{item}，获得{coins}金币！",
+		"在探索中找到{item}，收获{coins}金币！",
+		"意外挖掘出{item}，得到{coins}金币奖励！",
+		"仔细搜索后发现{item}，获得{coins}金币！",
+		"幸运地遇到{item}，赚得{coins}金币！",
+	},
+	NarrateDiscoveryDepleted: {"发现了{item}，可惜{location}的矿脉已经被挖空了"},
+	NarrateSocial:            {"遇到了{friend}的宠物，成为了朋友！"},
+	NarrateRareFind:          {"🌟 发现{item}！获得大奖{coins}金币！"},
+	NarrateReward: {
+		"找到了一些零散的金币：+{coins}",
+		"发现了闪闪发光的硬币：+{coins}",
+		"从地上捡到了金币：+{coins}",
+		"在岩石缝隙中发现金币：+{coins}",
+		"挖出了埋在土里的金币：+{coins}",
+		"在古老树根下找到金币：+{coins}",
+	},
+	NarrateRewardDepleted:  {"在{location}挖了半天，矿脉已经枯竭了"},
+	NarrateRestDone:        {"休息完毕，恢复了{energy}点体力"},
+	NarrateSocialDone:      {"社交结束，心情变好了"},
+	NarrateLonelinessEased: {"社交结束，终于不再感到孤单了"},
+	NarrateChat:            {"与 {friend} 愉快地交流"},
+	NarrateEatItem:         {"吃掉了背包里的{item}，饱食度+{hunger}"},
+	NarrateForage:          {"寻找免费的食物..."},
+	NarrateForaged:         {"找到了一些免费食物，饱食度+{hunger}"},
+	NarrateBuyFood:         {"购买食物中..."},
+	NarrateMeal:            {"花费{coins}金币买了美味的食物，饱食度+{hunger}"},
+	NarrateStarving:        {"因为饥饿失去了{damage}点生命值"},
+	NarrateBankDeposit:     {"在{location}银行存入了{coins}金币，存款余额{balance}"},
+	NarrateBankWithdraw:    {"从{location}银行取出了{coins}金币，存款余额{balance}"},
+	NarrateInterest:        {"的银行存款获得了{coins}金币利息，存款余额{balance}"},
+	NarrateLoan:            {"向{location}银行借了{coins}金币，需在{days}个游戏日内归还"},
+	NarrateLoanRepaid:      {"归还了{coins}金币贷款，剩余欠款{balance}"},
+	NarrateLoanGarnished:   {"的逾期贷款从奖励中扣除了{coins}金币，剩余欠款{balance}"},
+	NarrateLoanCleared:     {"还清了{location}银行的贷款"},
+	NarrateLoanOverdue:     {"的贷款已逾期，欠款{balance}金币，之后获得的奖励将被扣除{percent}%用于还款"},
+	NarrateAuctionListed:   {"在拍卖行上架了{item} x{quantity}，起拍价{coins}金币"},
+	NarrateAuctionBid:      {"出价{coins}金币竞拍{item}"},
+	NarrateAuctionUnsold:   {"的{item}流拍了，物品已退回背包"},
+	NarrateAuctionSold:     {"的{item} x{quantity}被 {counterpart} 以{coins}金币拍得（拍卖行佣金{fee}）"},
+	NarrateBuyGear:         {"花{coins}金币买下了{item}（攻击+{attack} 防御+{defense}）"},
+	NarrateCraftStart:      {"开始制作{item}..."},
+	NarrateCrafted:         {"成功制作了{item}！"},
+	NarrateCraftFailed:     {"制作{item}失败了，材料都浪费了..."},
+	NarrateUseItem:         {"使用了{item}"},
+	NarrateBossHit:         {"对{enemy}造成{damage}点伤害，受到反击-{taken} HP（首领剩余 {boss_hp}/{boss_max_hp}）"},
+	NarrateBossFinalBlow:   {"🏆 对{enemy}打出最后一击（{damage}点伤害），首领倒下了！"},
+	NarrateBossReward:      {"参与讨伐{enemy}，造成{damage}点伤害（第{rank}名），分得{coins}金币、{exp}经验"},
+	NarrateBossTopReward:   {"参与讨伐{enemy}，造成{damage}点伤害（第{rank}名），分得{coins}金币、{exp}经验，并作为伤害第一获得{item}"},
+	NarrateInterrupted:     {"{cause}，{activity}进行到{percent}%，{outcome}"},
+	NarrateDigest:          {"在你离开的{elapsed}里：{summary}"},
+}
+
+// personalityTemplates 带有性格色彩的叙述，与通用叙述各占一半机会
+var personalityTemplates = map[models.PetPersonality]map[NarrativeKind][]string{
+	models.PersonalityBrave: {
+		NarrateExplore:       {"大步踏进{location}，开始探索..."},
+		NarrateExploreIntent: {"勇敢地踏上冒险之路"},
+		NarrateBattleWon:     {"{boldly}迎战{enemy}，一举击败！获得经验+{exp}，金币+{coins}"},
+		NarrateBattleLost:    {"不服输地冲向{enemy}，还是败下阵来，受到{damage}点伤害"},
+		NarrateBossHit:       {"{boldly}扑向{enemy}，造成{damage}点伤害，受到反击-{taken} HP（首领剩余 {boss_hp}/{boss_max_hp}）"},
+		NarrateInterrupted:   {"{cause}，{activity}进行到{percent}%，只好不甘心地收手，{outcome}"},
+	},
+	models.PersonalityGreedy: {
+		NarrateExploreIntent: {"想要寻找更多财富"},
+		NarrateBattleWon:     {"打倒了{enemy}，第一时间清点战利品：经验+{exp}，金币+{coins}"},
+		NarrateDiscovery:     {"两眼放光地{found}{item}，获得{coins}金币！"},
+		NarrateRareFind:      {"🌟 发现{item}！抱着大奖{coins}金币笑得合不拢嘴！"},
+		NarrateReward:        {"一枚都不放过，{found}金币：+{coins}"},
+		NarrateForage:        {"舍不得花钱，四处寻找免费的食物..."},
+		NarrateMeal:          {"心疼地花了{coins}金币买食物，饱食度+{hunger}"},
+		NarrateInterest:      {"的银行存款又生出了{coins}金币利息，存款余额{balance}，乐开了花"},
+		NarrateAuctionBid:    {"咬咬牙出价{coins}金币，非要拿下{item}"},
+		NarrateBossReward:    {"讨伐{enemy}时拼命输出{damage}点伤害（第{rank}名），美滋滋地分得{coins}金币、{exp}经验"},
+	},
+	models.PersonalityFriendly: {
+		NarrateSocial:     {"热情地和{friend}的宠物打招呼，成为了朋友！"},
+		NarrateSocialDone: {"依依不舍地和伙伴道别，心情变好了"},
+		NarrateChat:       {"热情地拉着 {friend} 聊个不停"},
+		NarrateDigest:     {"在你离开的{elapsed}里一直惦记着你：{summary}"},
+	},
+	models.PersonalityCautious: {
+		NarrateExplore:     {"小心翼翼地来到了{location}，先观察了一番..."},
+		NarrateBattleWon:   {"小心周旋后击败了{enemy}，获得经验+{exp}，金币+{coins}"},
+		NarrateBattleLost:  {"早就觉得{enemy}不好惹，果然被击败，受到{damage}点伤害"},
+		NarrateSocial:      {"犹豫了一会儿，还是和{friend}的宠物成为了朋友"},
+		NarrateBankDeposit: {"把{coins}金币稳稳地存进了{location}银行，存款余额{balance}"},
+		NarrateLoanCleared: {"还清了{location}银行的贷款，终于松了一口气"},
+	},
+	models.PersonalityCurious: {
+		NarrateExplore:       {"好奇地来到了{location}，四处张望..."},
+		NarrateExploreIntent: {"的好奇心驱使着探索"},
+		NarrateDiscovery:     {"对{item}研究了好一会儿，顺便收获{coins}金币！"},
+		NarrateCrafted:       {"反复琢磨，终于做出了{item}！"},
+		NarrateUseItem:       {"好奇地试用了{item}"},
+	},
+}
+
+// narrationSymbols 叙述中可替换的词语，让同一模板有不同说法
+var narrationSymbols = map[string][]string{
+	"found":  {"发现了", "找到了", "挖出了"},
+	"boldly": {"毫不畏惧地", "大吼一声", "正面"},
+}
+
+var narrationSlot = regexp.MustCompile(`\{(\w+)\}`)
+
+// TemplateNarrator 用模板语法叙述事件的本地叙述器
+type TemplateNarrator struct {
+	intn func(n int) int
+}
+
+// NewTemplateNarrator 创建使用全局随机数的模板叙述器
+func NewTemplateNarrator() *TemplateNarrator {
+	return &TemplateNarrator{intn: rand.Intn}
+}
+
+// Narrate 在通用和性格叙述中挑选一个模板，代入事件数据并展开语法符号
+func (n *TemplateNarrator) Narrate(narrative Narrative) string {
+	templates := narrationTemplates[narrative.Kind]
+	if variants := personalityTemplates[narrative.Personality][narrative.Kind]; len(variants) > 0 && (len(templates) == 0 || n.intn(2) == 0) {
+		templates = variants
+	}
+	if len(templates) == 0 {
+		return string(narrative.Kind)
+	}
+
+	slots := map[string]string{
+		"location":    narrative.Location,
+		"enemy":       narrative.Enemy,
+		"item":        narrative.Item,
+		"friend":      narrative.Friend,
+		"counterpart": narrative.Counterpart,
+		"cause":       narrative.Cause,
+		"activity":    narrative.Activity,
+		"outcome":     narrative.Outcome,
+		"elapsed":     narrative.Elapsed,
+		"summary":     narrative.Summary,
+		"coins":       strconv.Itoa(narrative.Coins),
+		"exp":         strconv.Itoa(narrative.Experience),
+		"damage":      strconv.Itoa(narrative.Damage),
+		"taken":       strconv.Itoa(narrative.Taken),
+		"energy":      strconv.Itoa(narrative.Energy),
+		"hunger":      strconv.Itoa(narrative.Hunger),
+		"quantity":    strconv.Itoa(narrative.Quantity),
+		"balance":     strconv.Itoa(narrative.Balance),
+		"fee":         strconv.Itoa(narrative.Fee),
+		"days":        strconv.Itoa(narrative.Days),
+		"percent":     strconv.Itoa(narrative.Percent),
+		"rank":        strconv.Itoa(narrative.Rank),
+		"attack":      strconv.Itoa(narrative.Attack),
+		"defense":     strconv.Itoa(narrative.Defense),
+		"boss_hp":     strconv.Itoa(narrative.BossHP),
+		"boss_max_hp": strconv.Itoa(narrative.BossMaxHP),
+	}
+	return narrationSlot.ReplaceAllStringFunc(templates[n.intn(len(templates))], func(slot string) string {
+		name := slot[1 : len(slot)-1]
+		if value, exists := slots[name]; exists {
+			return value
+		}
+		if words := narrationSymbols[name]; len(words) > 0 {
+			return words[n.intn(len(words))]
+		}
+		return slot
+	})
+}
+
+// SetNarrator 替换事件消息的叙述器，例如接入外部文本生成服务
+func (ps *PetService) SetNarrator(narrator Narrator) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.narrator = narrator
+}
+
+// narrate 叙述宠物经历的事件，返回带宠物名前缀的消息，调用方需持有锁。
+// 外部叙述器只在这里给出草稿，改写交给 runNarrationRewriter 在锁外完成
+func (ps *PetService) narrate(pet *models.Pet, narrative Narrative) string {
+	narrative.PetName = pet.Name
+	narrative.Personality = pet.Personality
+	narrative.Mood = pet.Mood
+	if narrative.Location == "" {
+		narrative.Location = pet.Location
+	}
+
+	prefix := fmt.Sprintf("[%s] ", pet.Name)
+	async, ok := ps.narrator.(AsyncNarrator)
+	if !ok {
+		return prefix + ps.narrator.Narrate(narrative)
+	}
+	draft, ready := async.Draft(narrative)
+	if !ready {
+		select {
+		case ps.narrations <- narrationJob{narrator: async, petID: pet.ID, prefix: prefix, draft: draft, narrative: narrative}:
+		default:
+		}
+	}
+	return prefix + draft
+}
+
+// runNarrationRewriter 在锁外逐条改写事件消息。草稿在持有锁时生成，事件也在同一次持锁中记录，
+// 所以拿到锁时事件已经在列表里
+func (ps *PetService) runNarrationRewriter() {
+	for {
+		var job narrationJob
+		select {
+		case <-ps.quit:
+			return
+		case job = <-ps.narrations:
+		}
+		text, ok := job.narrator.Rewrite(job.narrative, job.draft)
+		if !ok {
+			continue
+		}
+		ps.mutex.Lock()
+		ps.patchNarration(job, text)
+		ps.mutex.Unlock()
+	}
+}
+
+// patchNarration 把事件消息中的草稿换成改写后的叙述，落库并以相同的事件ID重新推送，
+// 前端 useWebSocket 收到已有ID的事件时原地替换。事件被去重丢弃时不做任何事，调用方需持有锁
+func (ps *PetService) patchNarration(job narrationJob, text string) {
+	draft := job.prefix + job.draft
+	for i := len(ps.events) - 1; i >= 0; i-- {
+		event := &ps.events[i]
+		if event.PetID != job.petID || !strings.HasPrefix(event.Message, draft) {
+			continue
+		}
+		// 保留草稿之后追加的内容，如世界状况标注
+		event.Message = job.prefix + text + strings.TrimPrefix(event.Message, draft)
+		ps.eventRepo.UpdateEventMessageBatch(event.ID, event.Message)
+
+		select {
+		case ps.eventsCh <- *event:
+		default:
+		}
+		return
+	}
+}
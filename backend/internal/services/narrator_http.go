@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"miningpet/internal/cache"
+)
+
+const (
+	// DefaultNarratorTimeout 外部叙述服务的默认超时，改写在锁外进行，超时只会让事件保留模板叙述
+	DefaultNarratorTimeout = 3 * time.Second
+	// narratorCacheTTL 相同事件数据的叙述缓存时间
+	narratorCacheTTL = 10 * time.Minute
+	// narratorRetryDelay 外部服务出错后暂停调用的时间，期间直接使用模板
+	narratorRetryDelay = 30 * time.Second
+	// maxNarrationLength 外部服务返回的叙述最多保留的字数
+	maxNarrationLength = 120
+)
+
+// narrationRequest 发给外部文本生成服务的请求：提示词加结构化事件数据
+type narrationRequest struct {
+	Prompt string    `json:"prompt"`
+	Event  Narrative `json:"event"`
+}
+
+// narrationResponse 外部服务的响应，text 为生成的叙述
+type narrationResponse struct {
+	Text string `json:"text"`
+}
+
+// HTTPNarrator 调用外部文本生成服务叙述事件，结果按事件数据缓存。
+// 请求失败、超时或返回内容不可用时退回模板叙述，并在一段时间内不再调用外部服务。
+// 事件消息先用模板叙述发出，外部服务的改写在锁外完成后再替换
+type HTTPNarrator struct {
+	url      string
+	client   *http.Client
+	fallback Narrator
+	cache    *cache.MemoryCache
+
+	mu      sync.Mutex
+	retryAt time.Time
+}
+
+// NewHTTPNarrator 创建外部叙述器，timeout 不大于 0 时使用默认超时
+func NewHTTPNarrator(url string, timeout time.Duration, fallback Narrator) *HTTPNarrator {
+	if timeout <= 0 {
+		timeout = DefaultNarratorTimeout
+	}
+	return &HTTPNarrator{
+		url:      url,
+		client:   &http.Client{Timeout: timeout},
+		fallback: fallback,
+		cache:    cache.NewMemoryCache(narratorCacheTTL, narratorCacheTTL),
+	}
+}
+
+// Narrate 优先使用缓存和外部服务的叙述，不可用时返回模板叙述，会阻塞到外部服务响应
+func (n *HTTPNarrator) Narrate(narrative Narrative) string {
+	draft, ready := n.Draft(narrative)
+	if ready {
+		return draft
+	}
+	if text, ok := n.Rewrite(narrative, draft); ok {
+		return text
+	}
+	return draft
+}
+
+// Draft 缓存中有相同事件数据的叙述时直接使用，否则给出模板叙述
+func (n *HTTPNarrator) Draft(narrative Narrative) (string, bool) {
+	if key, err := json.Marshal(narrative); err == nil {
+		if text, ok := n.cache.Get(string(key)); ok {
+			return text.(string), true
+		}
+	}
+	return n.fallback.Narrate(narrative), false
+}
+
+// Rewrite 请求外部服务改写模板叙述并缓存结果。服务出错时暂停调用一段时间，
+// 改动了数字的叙述只丢弃这一条
+func (n *HTTPNarrator) Rewrite(narrative Narrative, draft string) (string, bool) {
+	key, err := json.Marshal(narrative)
+	if err != nil || !n.available() {
+		return "", false
+	}
+	text, err := n.generate(narrative, draft)
+	if errors.Is(err, errNarrationNumbers) {
+		log.Printf("Warning: discarding narration: %v", err)
+		return "", false
+	}
+	if err != nil {
+		log.Printf("Warning: narrator unavailable, falling back to templates: %v", err)
+		n.suspend()
+		return "", false
+	}
+	n.cache.Set(string(key), text, 0)
+	return text, true
+}
+
+func (n *HTTPNarrator) available() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return time.Now().After(n.retryAt)
+}
+
+func (n *HTTPNarrator) suspend() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.retryAt = time.Now().Add(narratorRetryDelay)
+}
+
+// errNarrationNumbers 外部服务改动或遗漏了模板叙述中的数字
+var errNarrationNumbers = errors.New("narration changed the numbers of the event")
+
+var narrationNumber = regexp.MustCompile(`\d+`)
+
+// keepsNumbers 叙述是否原样保留了草稿中的每个数字，出现几次就要保留几次
+func keepsNumbers(draft, text string) bool {
+	counts := make(map[string]int)
+	for _, number := range narrationNumber.FindAllString(text, -1) {
+		counts[number]++
+	}
+	for _, number := range narrationNumber.FindAllString(draft, -1) {
+		if counts[number] == 0 {
+			return false
+		}
+		counts[number]--
+	}
+	return true
+}
+
+// generate 请求外部服务改写模板叙述，只取第一行并限制长度，数字与草稿不符时返回 errNarrationNumbers
+func (n *HTTPNarrator) generate(narrative Narrative, draft string) (string, error) {
+	body, err := json.Marshal(narrationRequest{
+		Prompt: fmt.Sprintf("用一句简短的中文叙述宠物游戏中的事件，符合宠物%s的性格，保留所有数字，不要加宠物名字前缀：%s",
+			personalityNames[narrative.Personality], draft),
+		Event: narrative,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("narrator returned status %d", resp.StatusCode)
+	}
+
+	var result narrationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(strings.SplitN(strings.TrimSpace(result.Text), "\n", 2)[0])
+	if text == "" {
+		return "", fmt.Errorf("narrator returned empty text")
+	}
+	if runes := []rune(text); len(runes) > maxNarrationLength {
+		text = string(runes[:maxNarrationLength])
+	}
+	if !keepsNumbers(draft, text) {
+		return "", fmt.Errorf("%w: %q", errNarrationNumbers, text)
+	}
+	return text, nil
+}
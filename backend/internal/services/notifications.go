@@ -0,0 +1,37 @@
+package services
+
+import (
+	"time"
+)
+
+const (
+	NotificationAuctionOutbid  = "auction_outbid"
+	NotificationAuctionSettled = "auction_settled"
+)
+
+// Notification 推送给在线客户端的即时通知，不作为事件保存
+type Notification struct {
+	Type      string      `json:"type"`
+	PetID     string      `json:"pet_id,omitempty"`
+	Owner     string      `json:"owner,omitempty"`
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// GetNotificationChannel 获取通知通道
+func (ps *PetService) GetNotificationChannel() <-chan Notification {
+	return ps.notificationsCh
+}
+
+// notify 发送通知，通道已满时丢弃
+func (ps *PetService) notify(notification Notification) {
+	if notification.Timestamp.IsZero() {
+		notification.Timestamp = time.Now()
+	}
+
+	select {
+	case ps.notificationsCh <- notification:
+	default:
+	}
+}
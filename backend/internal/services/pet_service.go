@@ -1,8 +1,10 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"miningpet/internal/cache"
@@ -12,11 +14,16 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrPetNotFound 宠物不存在
+var ErrPetNotFound = errors.New("pet not found")
+
 type PetService struct {
 	pets         map[string]*models.Pet
 	events       []models.Event
 	mutex        *utils.RWMutexWithMetrics // 使用优化的锁
 	eventsCh     chan models.Event
+	// 即时通知（如拍卖被超价），由 websocket Hub 推送
+	notificationsCh chan Notification
 	aiEngine     *AIEngine
 	activePets   map[string]*time.Ticker
 	recentEvents map[string]time.Time
@@ -24,6 +31,35 @@ type PetService struct {
 	petRepo   *database.PetRepository
 	eventRepo *database.EventRepository
 	
+	// 金币账本
+	ledger *LedgerService
+	// 经济遥测
+	economy *EconomyService
+	// 主人钱包
+	wallets *WalletService
+	// 起始村庄银行
+	bank *BankService
+	// 拍卖行
+	auctions *AuctionService
+	// 世界资源
+	world *WorldService
+	// 世界首领
+	boss *BossService
+	// 世界事件日历
+	worldEvents *WorldEventService
+	// 进行中的限时行动
+	actions *ActionTracker
+	// 宠物记忆
+	memories *database.MemoryRepository
+	// 事件消息的叙述器
+	narrator Narrator
+	// 等待外部叙述器改写的事件消息
+	narrations chan narrationJob
+	// quit 关闭后所有后台任务退出，background 等待它们结束
+	quit       chan struct{}
+	background sync.WaitGroup
+	stopOnce   sync.Once
+	
 	// 内存缓存管理器
 	cacheManager *cache.GameCacheManager
 	// 状态管理器
@@ -42,11 +78,25 @@ func NewPetService() *PetService {
 		events:          make([]models.Event, 0),
 		mutex:           utils.NewRWMutexWithMetrics(),
 		eventsCh:        make(chan models.Event, 100),
+		notificationsCh: make(chan Notification, 100),
 		aiEngine:        NewAIEngine(),
 		activePets:      make(map[string]*time.Ticker),
 		recentEvents:    make(map[string]time.Time),
 		petRepo:         database.NewPetRepository(),
 		eventRepo:       database.NewEventRepository(),
+		ledger:          NewLedgerService(),
+		economy:         NewEconomyService(),
+		wallets:         NewWalletService(),
+		bank:            NewBankService(),
+		auctions:        NewAuctionService(),
+		world:           NewWorldService(),
+		boss:            NewBossService(),
+		worldEvents:     NewWorldEventService(),
+		actions:         NewActionTracker(),
+		memories:        database.NewMemoryRepository(),
+		narrator:        NewTemplateNarrator(),
+		narrations:      make(chan narrationJob, narrationQueueSize),
+		quit:            make(chan struct{}),
 		cacheManager:    cache.NewGameCacheManager(),
 		stateManager:    cache.NewStateManager(),
 		strategyManager: cache.NewStrategyManager(),
@@ -54,6 +104,13 @@ func NewPetService() *PetService {
 		jsonOptimizer:   utils.NewJSONOptimizer(),
 	}
 	
+	// 恢复停机前的行动时可能要做决策，先接好 AI 依赖
+	ps.aiEngine.bank = ps.bank
+	ps.aiEngine.world = ps.world
+	ps.aiEngine.boss = ps.boss
+	ps.aiEngine.events = ps.worldEvents
+	ps.aiEngine.population = func(location string) int { return len(ps.petsAt(location)) }
+	
 	if err := ps.loadPetsFromDatabase(); err != nil {
 		log.Printf("Warning: failed to load pets from database: %v", err)
 	}
@@ -61,28 +118,101 @@ func NewPetService() *PetService {
 	// 预热缓存
 	ps.warmupCache()
 	
-	go ps.runGlobalAI()
+	ps.spawn(ps.runGlobalAI)
+	ps.spawn(ps.runLedgerReconciliation)
+	ps.spawn(ps.runBankScheduler)
+	ps.spawn(ps.runAuctionSettlement)
+	ps.spawn(ps.runWorldRegeneration)
+	ps.spawn(ps.runBossScheduler)
+	ps.spawn(ps.runWorldEventScheduler)
+	ps.spawn(ps.runActionProgress)
+	ps.spawn(ps.runNarrationRewriter)
 	ps.startExistingPetsAI()
 	
 	return ps
 }
 
+// spawn 启动一个后台任务，Stop 会等待它退出
+func (ps *PetService) spawn(run func()) {
+	ps.background.Add(1)
+	go func() {
+		defer ps.background.Done()
+		run()
+	}()
+}
+
+// stopped 服务是否已经停止
+func (ps *PetService) stopped() bool {
+	select {
+	case <-ps.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop 停止所有后台任务并等待它们退出，尚未完成的行动留待下次启动时恢复。
+// 返回后服务不再写入数据库，可以安全地关闭数据库
+func (ps *PetService) Stop() {
+	ps.stopOnce.Do(func() {
+		close(ps.quit)
+
+		ps.mutex.Lock()
+		for _, timer := range ps.actions.timers {
+			timer.Stop()
+		}
+		ps.mutex.Unlock()
+
+		ps.background.Wait()
+		ps.economy.Stop()
+		ps.stateManager.Stop()
+		ps.strategyManager.Stop()
+		ps.cacheManager.Stop()
+	})
+}
+
 func (ps *PetService) loadPetsFromDatabase() error {
 	pets, err := ps.petRepo.GetAllPets()
 	if err != nil {
 		return fmt.Errorf("failed to load pets from database: %w", err)
 	}
 
+	// 先补记期初余额，离线收益的分录才能与之衔接
+	if err := ps.ledger.ensureOpeningBalances(pets); err != nil {
+		log.Printf("Warning: failed to record opening ledger balances: %v", err)
+	}
+
+	memories, err := ps.memories.GetRetainedMemories()
+	if err != nil {
+		log.Printf("Warning: failed to load pet memories: %v", err)
+	}
+
 	ps.mutex.Lock()
+	now := time.Now()
+	caughtUp := 0
 	for _, pet := range pets {
+		if retained, exists := memories[pet.ID]; exists {
+			pet.Memories = retained
+		}
 		ps.pets[pet.ID] = pet
+	}
+	for _, pet := range pets {
+		// 停机前未完成的行动：到期的立即完成，未到期的继续计时。
+		// 完成时计划中的下一步可能已经开始，仍有行动在进行时不补算离线进度
+		resumed := ps.recoverAction(pet, now)
+		if _, busy := ps.actions.inflight[pet.ID]; !resumed && !busy {
+			// 补算停机期间的离线进度
+			if ps.catchUpPet(pet, now) != nil {
+				caughtUp++
+			}
+		}
 		// 同时缓存到内存
 		ps.cacheManager.SetPet(pet.ID, pet)
 		ps.cacheManager.SetPetByOwner(pet.Owner, pet)
 	}
 	ps.mutex.Unlock()
 
-	log.Printf("Loaded %d pets from database", len(pets))
+	log.Printf("Loaded %d pets from database (%d caught up after downtime)", len(pets), caughtUp)
 	return nil
 }
 
@@ -107,10 +237,12 @@ func (ps *PetService) startExistingPetsAI() {
 	}
 	ps.mutex.RUnlock()
 
-	// 在锁外启动AI，避免死锁
+	// startPetAI 只登记并启动 AI 协程，不会再加锁
+	ps.mutex.Lock()
 	for _, pet := range petsToStart {
 		ps.startPetAI(pet)
 	}
+	ps.mutex.Unlock()
 }
 
 func (ps *PetService) savePetToDatabase(pet *models.Pet) {
@@ -127,11 +259,24 @@ func (ps *PetService) savePetToDatabase(pet *models.Pet) {
 		ps.strategyManager.AddData(pet, priority)
 	}
 	
-	// 同时保持原有的批量写入机制
-	ps.petRepo.UpdatePetBatch(pet)
+	// 宠物快照与待写分录在同一事务中批量写入
+	changes := ps.ledger.takePending(pet.ID)
+	ps.petRepo.UpdatePetWithLedger(pet, changes,
+		func() { ps.recordEconomy(changes.Entries) },
+		func() { ps.ledger.restorePending(pet.ID, changes) })
 }
 
+// CreatePet 创建性格随机的宠物
 func (ps *PetService) CreatePet(ownerName string) (*models.Pet, error) {
+	return ps.CreatePetWithTraits(ownerName, models.RandomTraits())
+}
+
+// CreatePetWithTraits 按主人选择的性格特质创建宠物
+func (ps *PetService) CreatePetWithTraits(ownerName string, traits models.PersonalityTraits) (*models.Pet, error) {
+	if err := traits.Validate(); err != nil {
+		return nil, err
+	}
+
 	ps.mutex.Lock()
 	defer ps.mutex.Unlock()
 
@@ -147,11 +292,18 @@ func (ps *PetService) CreatePet(ownerName string) (*models.Pet, error) {
 		return nil, fmt.Errorf("用户 %s 已经拥有宠物 %s，每位训练师只能拥有一只宠物", ownerName, existingPet.Name)
 	}
 
-	pet := models.NewPet(ownerName)
+	pet := models.NewPetWithTraits(ownerName, traits)
+	pet.Strategy = DefaultStrategy
+	
+	if pet.Coins > 0 {
+		ps.recordLedgerEntry(models.NewLedgerEntry(pet.ID, models.PetAccount(pet.ID), models.AccountMint, pet.Coins, models.ReasonStarter, "初始金币"))
+	}
 	
-	if err := ps.petRepo.CreatePet(pet); err != nil {
+	entries := ps.ledger.takePending(pet.ID).Entries
+	if err := ps.petRepo.CreatePetWithLedger(pet, entries); err != nil {
 		return nil, fmt.Errorf("failed to save pet to database: %w", err)
 	}
+	ps.recordEconomy(entries)
 	
 	ps.pets[pet.ID] = pet
 
@@ -284,6 +436,7 @@ func (ps *PetService) GetSystemStats() map[string]interface{} {
 		"events": map[string]interface{}{
 			"in_memory": eventCount,
 		},
+		"ledger":    ps.ledger.LastReport(),
 		"cache":     cacheStats,
 		"states":    stateStats,
 		"storage":   storageStats,
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"miningpet/internal/models"
+)
+
+// TestSetPetGoalValidation 主人设定的目标必须可以达成
+func TestSetPetGoalValidation(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "planner", models.PersonalityGreedy)
+
+	invalid := []struct {
+		kind     models.GoalKind
+		target   int
+		item     string
+		location string
+	}{
+		{models.GoalSaveCoins, 0, "unknown_gear", ""},
+		{models.GoalSaveCoins, pet.Coins, "", ""},
+		{models.GoalReachLevel, pet.Level, "", ""},
+		{models.GoalReachLevel, pet.Level + maxLevelGoal + 1, "", ""},
+		{models.GoalMakeFriend, 0, "", "月球"},
+		{"unknown", 0, "", ""},
+	}
+	for _, goal := range invalid {
+		if _, err := ps.SetPetGoal(pet.ID, goal.kind, goal.target, goal.item, goal.location); err == nil {
+			t.Errorf("Expected goal %+v to be rejected", goal)
+		}
+	}
+	if _, err := ps.SetPetGoal("missing", models.GoalReachLevel, 2, "", ""); !errors.Is(err, ErrPetNotFound) {
+		t.Errorf("Expected pet not found, got %v", err)
+	}
+
+	goal, err := ps.SetPetGoal(pet.ID, models.GoalMakeFriend, 0, "", "北方森林")
+	if err != nil {
+		t.Fatalf("Failed to set goal: %v", err)
+	}
+	if len(goal.Plan) == 0 || goal.Plan[0].Action != string(ActionExplore) || goal.Plan[0].Params["location"] != "北方森林" {
+		t.Errorf("Expected plan to start by exploring the goal location, got %s", planText(goal.Plan))
+	}
+}
+
+// TestGoalBuyGear 为买装备攒钱时先去探索，钱够了改为去银行购买，买到后目标达成
+func TestGoalBuyGear(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "planner", models.PersonalityGreedy)
+	gear := models.GearCatalog[0]
+
+	goal, err := ps.SetPetGoal(pet.ID, models.GoalSaveCoins, 0, gear.ID, "")
+	if err != nil {
+		t.Fatalf("Failed to set goal: %v", err)
+	}
+	if goal.Target != gear.Value || goal.AssignedBy != "owner" {
+		t.Errorf("Expected owner goal to save %d coins, got %+v", gear.Value, goal)
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	for _, step := range pet.Goal.Plan {
+		if step.Action != string(ActionExplore) {
+			t.Errorf("Expected to explore while saving, got plan %s", planText(pet.Goal.Plan))
+		}
+	}
+
+	// 饿了先吃饭，计划重新规划
+	pet.Hunger = 10
+	if cause := ps.aiEngine.stepBlocked(pet, pet.Goal, pet.Goal.Plan[0]); cause == "" {
+		t.Error("Expected exploring while starving to be blocked")
+	}
+	if plan := ps.aiEngine.planGoal(pet, pet.Goal); plan[0].Action != string(ActionEat) {
+		t.Errorf("Expected plan to start with eating, got %s", planText(plan))
+	}
+	pet.Hunger = 100
+
+	ps.creditCoins(pet, gear.Value, models.AccountMint, models.ReasonAddCoins, "")
+	pet.Goal.Plan = ps.aiEngine.planGoal(pet, pet.Goal)
+	if len(pet.Goal.Plan) != 1 || pet.Goal.Plan[0].Action != string(ActionBank) || pet.Goal.Plan[0].Params["item"] != gear.ID {
+		t.Fatalf("Expected a single step buying %s, got %s", gear.Name, planText(pet.Goal.Plan))
+	}
+
+	if !ps.pursueGoal(pet) || pet.Status != models.StatusBanking {
+		t.Fatalf("Expected pet to head to the bank, got status %s", pet.Status)
+	}
+	coins := pet.Coins
+	ps.finishAction(pet.ID, ps.actions.inflight[pet.ID].ID)
+	if pet.FindItem(gear.ID) == nil || pet.Coins != coins-gear.Value {
+		t.Fatalf("Expected %s bought for %d coins, got coins %d -> %d", gear.Name, gear.Value, coins, pet.Coins)
+	}
+
+	ps.pursueGoal(pet)
+	if pet.Goal != nil {
+		t.Errorf("Expected goal achieved after buying the gear, got %+v", pet.Goal)
+	}
+	assertReconciled(t, ps)
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"miningpet/internal/models"
+)
+
+// GetPetPolicy 获取宠物的行为策略设置
+func (ps *PetService) GetPetPolicy(petID string) (models.PetPolicy, error) {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return models.PetPolicy{}, ErrPetNotFound
+	}
+	return pet.Policy, nil
+}
+
+// UpdatePetPolicy 修改宠物的行为策略，未提供的字段保持不变，下一次决策起生效
+func (ps *PetService) UpdatePetPolicy(petID string, update models.PolicyUpdate) (models.PetPolicy, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return models.PetPolicy{}, ErrPetNotFound
+	}
+	if err := ps.updatePolicy(pet, update); err != nil {
+		return models.PetPolicy{}, err
+	}
+	return pet.Policy, nil
+}
+
+// updatePolicy 调用方需持有锁，校验失败时不做任何修改
+func (ps *PetService) updatePolicy(pet *models.Pet, update models.PolicyUpdate) error {
+	policy := update.Apply(pet.Policy)
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	pet.Policy = policy
+	ps.savePetToDatabase(pet)
+	return nil
+}
+
+func (ps *PetService) executePolicyCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	if len(params) > 0 {
+		// 命令参数与 REST 请求体的字段一致
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		var update models.PolicyUpdate
+		if err := json.Unmarshal(data, &update); err != nil {
+			return nil, fmt.Errorf("无效的策略参数: %w", err)
+		}
+		if err := ps.updatePolicy(pet, update); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]interface{}{
+		"action":  "policy",
+		"policy":  pet.Policy,
+		"message": fmt.Sprintf("%s 的冒险倾向为 %d，社交意愿为 %d", pet.Name, pet.Policy.RiskTolerance, pet.Policy.SocialAppetite),
+	}, nil
+}
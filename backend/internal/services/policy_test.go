@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+
+	"miningpet/internal/models"
+)
+
+func intPtr(v int) *int { return &v }
+
+// TestPolicyValidationRanges 各项设置的边界值可以保存，超出范围的修改被拒绝且不改变原有策略
+func TestPolicyValidationRanges(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "policy", models.PersonalityCautious)
+
+	locations := append([]string{}, models.Locations[:models.MaxPreferredLocations]...)
+	valid := map[string]models.PolicyUpdate{
+		"risk 0":        {RiskTolerance: intPtr(0)},
+		"risk 100":      {RiskTolerance: intPtr(100)},
+		"spending max":  {SpendingLimit: intPtr(models.MaxSpendingLimit)},
+		"rest min":      {RestThreshold: intPtr(models.MinRestThreshold)},
+		"rest max":      {RestThreshold: intPtr(models.MaxRestThreshold)},
+		"social 100":    {SocialAppetite: intPtr(100)},
+		"max locations": {PreferredLocations: &locations},
+	}
+	for name, update := range valid {
+		if _, err := ps.UpdatePetPolicy(pet.ID, update); err != nil {
+			t.Errorf("Expected %s to be accepted, got %v", name, err)
+		}
+	}
+
+	before, _ := ps.GetPetPolicy(pet.ID)
+	tooMany := append([]string{}, models.Locations[:models.MaxPreferredLocations+1]...)
+	unknown := []string{"月球"}
+	invalid := map[string]models.PolicyUpdate{
+		"risk -1":        {RiskTolerance: intPtr(-1)},
+		"risk 101":       {RiskTolerance: intPtr(101)},
+		"spending -1":    {SpendingLimit: intPtr(-1)},
+		"spending max+1": {SpendingLimit: intPtr(models.MaxSpendingLimit + 1)},
+		"rest min-1":     {RestThreshold: intPtr(models.MinRestThreshold - 1)},
+		"rest max+1":     {RestThreshold: intPtr(models.MaxRestThreshold + 1)},
+		"social 101":     {SocialAppetite: intPtr(101)},
+		"too many":       {PreferredLocations: &tooMany},
+		"unknown":        {PreferredLocations: &unknown},
+		// 一项合法一项越界时整体拒绝
+		"mixed": {RiskTolerance: intPtr(20), SocialAppetite: intPtr(-5)},
+	}
+	for name, update := range invalid {
+		if _, err := ps.UpdatePetPolicy(pet.ID, update); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+	after, _ := ps.GetPetPolicy(pet.ID)
+	if after.RiskTolerance != before.RiskTolerance || after.SocialAppetite != before.SocialAppetite || len(after.PreferredLocations) != len(before.PreferredLocations) {
+		t.Errorf("Expected rejected updates to leave the policy unchanged, got %+v (was %+v)", after, before)
+	}
+}
+
+// TestPolicyCommandSteersPriorities 命令只修改提供的字段，社交意愿和冒险倾向体现为优先级中的策略因素
+func TestPolicyCommandSteersPriorities(t *testing.T) {
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "steered", models.PersonalityCurious)
+
+	if _, err := ps.ExecuteCommand(pet.ID, "policy", map[string]interface{}{"social_appetite": float64(100), "risk_tolerance": float64(90)}); err != nil {
+		t.Fatalf("Failed to update policy: %v", err)
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if pet.Policy.RestThreshold != models.DefaultPetPolicy().RestThreshold {
+		t.Errorf("Expected rest threshold untouched, got %d", pet.Policy.RestThreshold)
+	}
+	if pet.Policy.FleeHealthPercent() != 13 {
+		t.Errorf("Expected a risk-tolerant pet to flee at 13%%, got %d", pet.Policy.FleeHealthPercent())
+	}
+
+	var social priorityFactors
+	ps.aiEngine.calculateSocializePriority(pet, &social)
+	if value := factorValue(social, factorPolicy); value != 40 {
+		t.Errorf("Expected social policy factor 40, got %d in %+v", value, social)
+	}
+}
+
+// factorValue 优先级构成中某项因素的数值
+func factorValue(factors priorityFactors, name string) int {
+	for _, factor := range factors {
+		if factor.Name == name {
+			return factor.Value
+		}
+	}
+	return 0
+}
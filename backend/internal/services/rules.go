@@ -0,0 +1,613 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+// 自动化规则的限制，规则文本只能引用下面列出的字段和指令，不会被当作代码执行
+const (
+	maxRulesPerPet    = 20
+	maxRuleLength     = 200
+	maxRuleConditions = 5
+	maxRulePriority   = 100
+	// minRuleCooldown 与宠物 AI 的决策间隔一致，更短的冷却没有意义
+	minRuleCooldown     = 15
+	defaultRuleCooldown = 60
+	maxRuleCooldown     = 24 * 60 * 60
+	// ruleEventWindow "event" 条件回看的时间，与宠物 AI 的决策间隔一致
+	ruleEventWindow = 15 * time.Second
+)
+
+var (
+	// ErrTooManyRules 宠物的规则数量已达上限
+	ErrTooManyRules = errors.New("自动化规则数量已达上限")
+	// ErrRuleNotFound 宠物没有这条规则
+	ErrRuleNotFound = errors.New("没有这条自动化规则")
+)
+
+// ruleNumberFields 可用于数值比较的字段，值表示是否支持百分比
+var ruleNumberFields = map[string]bool{
+	"health":  true,
+	"energy":  true,
+	"hunger":  false,
+	"social":  false,
+	"coins":   false,
+	"level":   false,
+	"deposit": false,
+	"debt":    false,
+}
+
+// ruleTextFields 可用于文本比较的字段
+var ruleTextFields = map[string]bool{
+	"status":   true,
+	"location": true,
+	"mood":     true,
+}
+
+// ruleCommands 规则可以执行的指令，值为参数对应的字段，以 ! 结尾表示参数必填
+var ruleCommands = map[string]string{
+	"feed":          "amount",
+	"rest":          "duration",
+	"socialize":     "",
+	"explore":       "location",
+	"craft":         "recipe!",
+	"deposit":       "amount!",
+	"withdraw":      "amount!",
+	"bank_deposit":  "amount!",
+	"bank_withdraw": "amount!",
+	"repay":         "amount!",
+	"cancel":        "",
+	"recall":        "",
+}
+
+var ruleEventTypes = map[models.EventType]bool{
+	models.EventExplore:   true,
+	models.EventBattle:    true,
+	models.EventDiscovery: true,
+	models.EventSocial:    true,
+	models.EventReward:    true,
+	models.EventLevelUp:   true,
+	models.EventRareFind:  true,
+	models.EventTransfer:  true,
+	models.EventBoss:      true,
+}
+
+// tokenizeRule 把规则文本切分为单词、数字、比较符号和百分号
+func tokenizeRule(source string) []string {
+	tokens := make([]string, 0)
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("<>=!", r):
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else {
+				tokens = append(tokens, string(r))
+				i++
+			}
+		case r == '%':
+			tokens = append(tokens, "%")
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("<>=!%", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		}
+	}
+	return tokens
+}
+
+// ParseRule 解析形如 "if health < 40% and status == 等待中 then rest" 的规则文本。
+// 条件之间用 and 连接；"event battle" 表示最近发生过该类事件
+func ParseRule(source string) (models.AutomationRule, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return models.AutomationRule{}, fmt.Errorf("规则为空")
+	}
+	if len([]rune(source)) > maxRuleLength {
+		return models.AutomationRule{}, fmt.Errorf("规则不能超过%d个字符", maxRuleLength)
+	}
+
+	tokens := tokenizeRule(source)
+	if len(tokens) == 0 || strings.ToLower(tokens[0]) != "if" {
+		return models.AutomationRule{}, fmt.Errorf("规则应以 if 开头")
+	}
+	then := -1
+	for i, token := range tokens {
+		if strings.ToLower(token) == "then" {
+			then = i
+			break
+		}
+	}
+	if then < 0 {
+		return models.AutomationRule{}, fmt.Errorf("规则缺少 then")
+	}
+
+	conditions := make([]models.RuleCondition, 0)
+	clause := make([]string, 0)
+	for _, token := range append(tokens[1:then], "and") {
+		if strings.ToLower(token) != "and" {
+			clause = append(clause, token)
+			continue
+		}
+		condition, err := parseRuleCondition(clause)
+		if err != nil {
+			return models.AutomationRule{}, err
+		}
+		conditions = append(conditions, condition)
+		clause = clause[:0]
+	}
+	if len(conditions) > maxRuleConditions {
+		return models.AutomationRule{}, fmt.Errorf("一条规则最多%d个条件", maxRuleConditions)
+	}
+
+	command, params, err := parseRuleAction(tokens[then+1:])
+	if err != nil {
+		return models.AutomationRule{}, err
+	}
+
+	return models.AutomationRule{
+		Source:     source,
+		Conditions: conditions,
+		Command:    command,
+		Params:     params,
+	}, nil
+}
+
+func parseRuleCondition(tokens []string) (models.RuleCondition, error) {
+	if len(tokens) == 0 {
+		return models.RuleCondition{}, fmt.Errorf("条件为空")
+	}
+	field := strings.ToLower(tokens[0])
+
+	if field == "event" {
+		if len(tokens) != 2 || !ruleEventTypes[models.EventType(tokens[1])] {
+			return models.RuleCondition{}, fmt.Errorf("无效的事件条件: %s", strings.Join(tokens, " "))
+		}
+		return models.RuleCondition{Field: field, Op: "==", Text: tokens[1]}, nil
+	}
+
+	if len(tokens) < 3 {
+		return models.RuleCondition{}, fmt.Errorf("无效的条件: %s", strings.Join(tokens, " "))
+	}
+	op := tokens[1]
+
+	if ruleTextFields[field] {
+		if op == "is" {
+			op = "=="
+		}
+		if op != "==" && op != "!=" {
+			return models.RuleCondition{}, fmt.Errorf("%s 只能用 == 或 != 比较", field)
+		}
+		return models.RuleCondition{Field: field, Op: op, Text: strings.Join(tokens[2:], " ")}, nil
+	}
+
+	allowPercent, ok := ruleNumberFields[field]
+	if !ok {
+		return models.RuleCondition{}, fmt.Errorf("未知的字段: %s", tokens[0])
+	}
+	switch op {
+	case "<", "<=", ">", ">=", "==", "!=":
+	default:
+		return models.RuleCondition{}, fmt.Errorf("未知的比较符号: %s", op)
+	}
+	percent := len(tokens) == 4 && tokens[3] == "%"
+	if len(tokens) > 4 || (len(tokens) == 4 && !percent) {
+		return models.RuleCondition{}, fmt.Errorf("无效的条件: %s", strings.Join(tokens, " "))
+	}
+	if percent && !allowPercent {
+		return models.RuleCondition{}, fmt.Errorf("%s 不支持百分比", field)
+	}
+	value, err := strconv.Atoi(tokens[2])
+	if err != nil || value < 0 {
+		return models.RuleCondition{}, fmt.Errorf("%s 的比较值应为非负整数: %s", field, tokens[2])
+	}
+	return models.RuleCondition{Field: field, Op: op, Value: value, Percent: percent}, nil
+}
+
+func parseRuleAction(tokens []string) (string, map[string]interface{}, error) {
+	if len(tokens) == 0 {
+		return "", nil, fmt.Errorf("then 之后缺少指令")
+	}
+	command := strings.ToLower(tokens[0])
+	key, ok := ruleCommands[command]
+	if !ok {
+		return "", nil, fmt.Errorf("规则不能执行指令 %s", tokens[0])
+	}
+	required := strings.HasSuffix(key, "!")
+	key = strings.TrimSuffix(key, "!")
+
+	params := make(map[string]interface{})
+	arg := strings.Join(tokens[1:], " ")
+	switch {
+	case arg == "":
+		if required {
+			return "", nil, fmt.Errorf("指令 %s 需要参数 %s", command, key)
+		}
+	case key == "":
+		return "", nil, fmt.Errorf("指令 %s 不需要参数", command)
+	case key == "amount" || key == "duration":
+		// 数值参数与 JSON 请求保持一致，使用 float64
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return "", nil, fmt.Errorf("%s 的参数应为正整数: %s", command, arg)
+		}
+		params[key] = float64(n)
+	case key == "location":
+		if !isExploreLocation(arg) {
+			return "", nil, fmt.Errorf("未知的地点: %s", arg)
+		}
+		params[key] = arg
+	case key == "recipe":
+		if _, exists := models.FindRecipe(arg); !exists {
+			return "", nil, fmt.Errorf("unknown recipe: %s", arg)
+		}
+		params[key] = arg
+	}
+	return command, params, nil
+}
+
+// newAutomationRule 解析规则文本并校验优先级和冷却时间，cooldown 为 0 时使用默认值
+func newAutomationRule(source string, priority, cooldown int) (models.AutomationRule, error) {
+	rule, err := ParseRule(source)
+	if err != nil {
+		return models.AutomationRule{}, err
+	}
+	if cooldown == 0 {
+		cooldown = defaultRuleCooldown
+	}
+	if err := validateRuleSettings(priority, cooldown); err != nil {
+		return models.AutomationRule{}, err
+	}
+
+	rule.ID = uuid.New().String()
+	rule.Priority = priority
+	rule.Cooldown = cooldown
+	rule.Enabled = true
+	rule.CreatedAt = time.Now()
+	return rule, nil
+}
+
+func validateRuleSettings(priority, cooldown int) error {
+	if priority < 0 || priority > maxRulePriority {
+		return fmt.Errorf("priority 应在 0-%d 之间: %d", maxRulePriority, priority)
+	}
+	if cooldown < minRuleCooldown || cooldown > maxRuleCooldown {
+		return fmt.Errorf("cooldown 应在 %d-%d 秒之间: %d", minRuleCooldown, maxRuleCooldown, cooldown)
+	}
+	return nil
+}
+
+// ruleFieldValue 数值字段的当前值，百分比相对生命或体力上限
+func (ps *PetService) ruleFieldValue(pet *models.Pet, condition models.RuleCondition) int {
+	percent := func(value, max int) int {
+		if !condition.Percent {
+			return value
+		}
+		if max <= 0 {
+			return 0
+		}
+		return value * 100 / max
+	}
+
+	switch condition.Field {
+	case "health":
+		return percent(pet.Health, pet.MaxHealth)
+	case "energy":
+		return percent(pet.Energy, pet.MaxEnergy)
+	case "hunger":
+		return pet.Hunger
+	case "social":
+		return pet.Social
+	case "coins":
+		return pet.Coins
+	case "level":
+		return pet.Level
+	case "deposit":
+		return ps.bank.balance(pet.ID)
+	case "debt":
+		if loan := ps.bank.openLoan(pet.ID); loan != nil {
+			return loan.Outstanding
+		}
+	}
+	return 0
+}
+
+// recentPetEvent 宠物在 since 之后是否发生过该类事件
+func (ps *PetService) recentPetEvent(petID string, eventType models.EventType, since time.Time) bool {
+	for i := len(ps.events) - 1; i >= 0 && ps.events[i].Timestamp.After(since); i-- {
+		if ps.events[i].PetID == petID && ps.events[i].Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleMatches 规则的所有条件是否都成立，调用方需持有锁
+func (ps *PetService) ruleMatches(pet *models.Pet, rule models.AutomationRule, now time.Time) bool {
+	for _, condition := range rule.Conditions {
+		var matched bool
+		switch {
+		case condition.Field == "event":
+			// 只看上次触发之后的事件，同一事件不会重复触发规则
+			since := now.Add(-ruleEventWindow)
+			if rule.LastFiredAt != nil && rule.LastFiredAt.After(since) {
+				since = *rule.LastFiredAt
+			}
+			matched = ps.recentPetEvent(pet.ID, models.EventType(condition.Text), since)
+		case ruleTextFields[condition.Field]:
+			var value string
+			switch condition.Field {
+			case "status":
+				value = string(pet.Status)
+			case "location":
+				value = pet.Location
+			case "mood":
+				value = string(pet.Mood)
+			}
+			matched = (value == condition.Text) == (condition.Op == "==")
+		default:
+			value := ps.ruleFieldValue(pet, condition)
+			switch condition.Op {
+			case "<":
+				matched = value < condition.Value
+			case "<=":
+				matched = value <= condition.Value
+			case ">":
+				matched = value > condition.Value
+			case ">=":
+				matched = value >= condition.Value
+			case "==":
+				matched = value == condition.Value
+			case "!=":
+				matched = value != condition.Value
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// applyRules 按优先级评估宠物的自动化规则，触发第一条条件成立且不在冷却中的规则，
+// 返回是否触发。在 AI 决策之前调用，调用方需持有锁
+func (ps *PetService) applyRules(pet *models.Pet) bool {
+	if len(pet.Rules) == 0 {
+		return false
+	}
+
+	order := make([]int, len(pet.Rules))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return pet.Rules[order[a]].Priority > pet.Rules[order[b]].Priority
+	})
+
+	now := time.Now()
+	for _, i := range order {
+		rule := &pet.Rules[i]
+		if !rule.Ready(now) || !ps.ruleMatches(pet, *rule, now) {
+			continue
+		}
+
+		// 失败的规则同样进入冷却，避免每次决策都重复失败
+		rule.LastFiredAt = &now
+		rule.FireCount++
+
+		params := make(map[string]interface{}, len(rule.Params))
+		for key, value := range rule.Params {
+			params[key] = value
+		}
+		message := fmt.Sprintf("[%s] 按主人的规则「%s」行动", pet.Name, rule.Source)
+		if _, err := ps.dispatchCommand(pet, rule.Command, params); err != nil {
+			message = fmt.Sprintf("[%s] 规则「%s」执行失败: %v", pet.Name, rule.Source, err)
+		}
+		ps.addEvent(models.Event{
+			ID:        uuid.New().String(),
+			PetID:     pet.ID,
+			PetName:   pet.Name,
+			Type:      models.EventRule,
+			Message:   message,
+			Timestamp: now,
+			Data:      models.EventData{Location: pet.Location},
+		})
+		ps.savePetToDatabase(pet)
+		return true
+	}
+	return false
+}
+
+// addRule 调用方需持有锁
+func (ps *PetService) addRule(pet *models.Pet, rule models.AutomationRule) error {
+	if len(pet.Rules) >= maxRulesPerPet {
+		return ErrTooManyRules
+	}
+	pet.Rules = append(pet.Rules, rule)
+	ps.savePetToDatabase(pet)
+	return nil
+}
+
+// RuleUpdate 对规则设置的部分修改，未提供的字段保持不变
+type RuleUpdate struct {
+	Enabled  *bool `json:"enabled"`
+	Priority *int  `json:"priority"`
+	Cooldown *int  `json:"cooldown"`
+}
+
+// updateRule 调用方需持有锁
+func (ps *PetService) updateRule(pet *models.Pet, ruleID string, update RuleUpdate) (models.AutomationRule, error) {
+	for i := range pet.Rules {
+		rule := &pet.Rules[i]
+		if rule.ID != ruleID {
+			continue
+		}
+
+		priority, cooldown := rule.Priority, rule.Cooldown
+		if update.Priority != nil {
+			priority = *update.Priority
+		}
+		if update.Cooldown != nil {
+			cooldown = *update.Cooldown
+		}
+		if err := validateRuleSettings(priority, cooldown); err != nil {
+			return models.AutomationRule{}, err
+		}
+
+		rule.Priority, rule.Cooldown = priority, cooldown
+		if update.Enabled != nil {
+			rule.Enabled = *update.Enabled
+		}
+		ps.savePetToDatabase(pet)
+		return *rule, nil
+	}
+	return models.AutomationRule{}, ErrRuleNotFound
+}
+
+// removeRule 调用方需持有锁
+func (ps *PetService) removeRule(pet *models.Pet, ruleID string) error {
+	for i, rule := range pet.Rules {
+		if rule.ID == ruleID {
+			pet.Rules = append(pet.Rules[:i:i], pet.Rules[i+1:]...)
+			ps.savePetToDatabase(pet)
+			return nil
+		}
+	}
+	return ErrRuleNotFound
+}
+
+func (ps *PetService) executeRulesCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	return map[string]interface{}{
+		"action":  "rules",
+		"rules":   pet.Rules,
+		"message": fmt.Sprintf("%s 共有 %d 条自动化规则", pet.Name, len(pet.Rules)),
+	}, nil
+}
+
+func (ps *PetService) executeRuleAddCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	source, _ := params["rule"].(string)
+	rule, err := newAutomationRule(source, paramInt(params, "priority"), paramInt(params, "cooldown"))
+	if err != nil {
+		return nil, err
+	}
+	if err := ps.addRule(pet, rule); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":  "rule_add",
+		"rule":    rule,
+		"message": fmt.Sprintf("%s 学会了新规则「%s」", pet.Name, rule.Source),
+	}, nil
+}
+
+func (ps *PetService) executeRuleUpdateCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	var update RuleUpdate
+	if enabled, ok := params["enabled"].(bool); ok {
+		update.Enabled = &enabled
+	}
+	if _, ok := params["priority"]; ok {
+		priority := paramInt(params, "priority")
+		update.Priority = &priority
+	}
+	if _, ok := params["cooldown"]; ok {
+		cooldown := paramInt(params, "cooldown")
+		update.Cooldown = &cooldown
+	}
+
+	ruleID, _ := params["id"].(string)
+	rule, err := ps.updateRule(pet, ruleID, update)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":  "rule_update",
+		"rule":    rule,
+		"message": fmt.Sprintf("已更新 %s 的规则「%s」", pet.Name, rule.Source),
+	}, nil
+}
+
+func (ps *PetService) executeRuleRemoveCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	ruleID, _ := params["id"].(string)
+	if err := ps.removeRule(pet, ruleID); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":  "rule_remove",
+		"rules":   pet.Rules,
+		"message": fmt.Sprintf("已删除 %s 的一条自动化规则", pet.Name),
+	}, nil
+}
+
+// GetRules 获取宠物的自动化规则
+func (ps *PetService) GetRules(petID string) ([]models.AutomationRule, error) {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return nil, ErrPetNotFound
+	}
+	return append([]models.AutomationRule{}, pet.Rules...), nil
+}
+
+// AddRule 解析并添加一条自动化规则，cooldown 为 0 时使用默认冷却时间
+func (ps *PetService) AddRule(petID, source string, priority, cooldown int) (models.AutomationRule, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return models.AutomationRule{}, ErrPetNotFound
+	}
+	rule, err := newAutomationRule(source, priority, cooldown)
+	if err != nil {
+		return models.AutomationRule{}, err
+	}
+	if err := ps.addRule(pet, rule); err != nil {
+		return models.AutomationRule{}, err
+	}
+	return rule, nil
+}
+
+// UpdateRule 启用、停用规则或修改其优先级和冷却时间
+func (ps *PetService) UpdateRule(petID, ruleID string, update RuleUpdate) (models.AutomationRule, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return models.AutomationRule{}, ErrPetNotFound
+	}
+	return ps.updateRule(pet, ruleID, update)
+}
+
+// RemoveRule 删除一条自动化规则
+func (ps *PetService) RemoveRule(petID, ruleID string) error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet, exists := ps.pets[petID]
+	if !exists {
+		return ErrPetNotFound
+	}
+	return ps.removeRule(pet, ruleID)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+// buyGear 在起始村庄商店购买装备
+func (ps *PetService) buyGear(pet *models.Pet, gearID string) (models.Item, error) {
+	gear, exists := models.FindGear(gearID)
+	if !exists {
+		return models.Item{}, fmt.Errorf("商店没有这件装备: %s", gearID)
+	}
+	if pet.FindItem(gear.ID) != nil {
+		return models.Item{}, fmt.Errorf("%s 已经拥有%s", pet.Name, gear.Name)
+	}
+	if pet.Coins < gear.Value {
+		return models.Item{}, fmt.Errorf("宠物金币不足（当前: %d，需要: %d）", pet.Coins, gear.Value)
+	}
+
+	ps.debitCoins(pet, gear.Value, models.AccountShop, models.ReasonGear, gear.Name)
+	pet.AddItem(gear)
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventReward,
+		Message:   ps.narrate(pet, Narrative{Kind: NarrateBuyGear, Coins: gear.Value, Item: gear.Name, Attack: gear.Attack, Defense: gear.Defense}),
+		Timestamp: time.Now(),
+		Data:      models.EventData{Coins: -gear.Value, Items: []models.Item{gear}},
+	})
+	ps.savePetToDatabase(pet)
+
+	return gear, nil
+}
+
+func (ps *PetService) executeShopCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	items := make([]map[string]interface{}, 0, len(models.GearCatalog))
+	for _, gear := range models.GearCatalog {
+		items = append(items, map[string]interface{}{
+			"id":      gear.ID,
+			"name":    gear.Name,
+			"price":   gear.Value,
+			"attack":  gear.Attack,
+			"defense": gear.Defense,
+			"owned":   pet.FindItem(gear.ID) != nil,
+		})
+	}
+
+	return map[string]interface{}{
+		"action":  "shop",
+		"coins":   pet.Coins,
+		"items":   items,
+		"message": fmt.Sprintf("%s 商店共有 %d 件装备", bankLocation, len(items)),
+	}, nil
+}
+
+func (ps *PetService) executeBuyCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	gearID, _ := params["item"].(string)
+	gear, err := ps.buyGear(pet, gearID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":  "buy",
+		"item":    gear,
+		"coins":   pet.Coins,
+		"attack":  pet.Attack,
+		"defense": pet.Defense,
+		"message": fmt.Sprintf("%s 购买了%s，当前金币: %d", pet.Name, gear.Name, pet.Coins),
+	}, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// traitDrift 一次寻常经历让相关特质变化的幅度
+	traitDrift = 0.01
+	// nearDeathDrift 死里逃生让宠物明显变得谨慎
+	nearDeathDrift = 0.04
+	// nearDeathHealthPercent 战斗后生命低于该百分比视为死里逃生
+	nearDeathHealthPercent = 20
+)
+
+// personalityNames 性格标签在事件消息中的说法
+var personalityNames = map[models.PetPersonality]string{
+	models.PersonalityBrave:    "勇敢",
+	models.PersonalityGreedy:   "贪婪",
+	models.PersonalityFriendly: "友善",
+	models.PersonalityCautious: "谨慎",
+	models.PersonalityCurious:  "好奇",
+}
+
+// nearDeath 宠物是否刚刚死里逃生
+func nearDeath(pet *models.Pet) bool {
+	return pet.Health*100 < pet.MaxHealth*nearDeathHealthPercent
+}
+
+// driftFromExplore 探索中的遭遇慢慢改变宠物的性格：胜仗让宠物更勇敢，险些丧命让宠物更谨慎，调用方需持有锁
+func (ps *PetService) driftFromExplore(pet *models.Pet, event models.Event) {
+	switch event.Type {
+	case models.EventBattle:
+		if event.Data.IsVictory {
+			pet.Traits.Drift(models.TraitCourage, traitDrift)
+		}
+	case models.EventBoss:
+		pet.Traits.Drift(models.TraitCourage, traitDrift)
+	case models.EventRareFind:
+		pet.Traits.Drift(models.TraitCuriosity, traitDrift*2)
+		pet.Traits.Drift(models.TraitGreed, traitDrift)
+	case models.EventDiscovery:
+		pet.Traits.Drift(models.TraitCuriosity, traitDrift)
+		if event.Data.Coins > 0 {
+			pet.Traits.Drift(models.TraitGreed, traitDrift)
+		}
+	case models.EventSocial:
+		pet.Traits.Drift(models.TraitSociability, traitDrift)
+	}
+	if (event.Type == models.EventBattle || event.Type == models.EventBoss) && nearDeath(pet) {
+		pet.Traits.Drift(models.TraitCaution, nearDeathDrift)
+	}
+	ps.updatePersonality(pet)
+}
+
+// driftTrait 让宠物的某项特质变化并更新性格标签，调用方需持有锁
+func (ps *PetService) driftTrait(pet *models.Pet, trait models.Trait, amount float64) {
+	pet.Traits.Drift(trait, amount)
+	ps.updatePersonality(pet)
+}
+
+// updatePersonality 主导特质变化后更新性格标签并记录事件，调用方需持有锁
+func (ps *PetService) updatePersonality(pet *models.Pet) {
+	personality := pet.Traits.Dominant(pet.Personality)
+	if personality == pet.Personality {
+		return
+	}
+	before := pet.Personality
+	pet.Personality = personality
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventPersonality,
+		Message:   fmt.Sprintf("[%s] 经历了许多事情，性格从%s变得%s了", pet.Name, personalityNames[before], personalityNames[personality]),
+		Timestamp: time.Now(),
+	})
+}
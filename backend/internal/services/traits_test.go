@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+
+	"miningpet/internal/models"
+)
+
+// TestTraitWeighting 性格特质按权重折算为优先级，主导特质决定性格标签，越界的特质被拒绝
+func TestTraitWeighting(t *testing.T) {
+	brave := models.TraitsFor(models.PersonalityBrave)
+	if weight := brave.Weigh(map[models.Trait]int{models.TraitCourage: 40, models.TraitCaution: -20}); weight != 22 {
+		t.Errorf("Expected 0.7*40 - 0.3*20 = 22, got %d", weight)
+	}
+	for _, personality := range []models.PetPersonality{models.PersonalityBrave, models.PersonalityGreedy, models.PersonalityFriendly, models.PersonalityCautious, models.PersonalityCurious} {
+		if dominant := models.TraitsFor(personality).Dominant(""); dominant != personality {
+			t.Errorf("Expected traits for %s to be dominated by it, got %s", personality, dominant)
+		}
+	}
+
+	invalid := brave
+	invalid.Greed = 1.2
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected traits above 1 to be rejected")
+	}
+
+	ps := newTestPetService(t)
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	bold, timid := models.NewPet("bold"), models.NewPet("timid")
+	bold.Traits, timid.Traits = brave, models.TraitsFor(models.PersonalityCautious)
+	var boldFactors, timidFactors priorityFactors
+	ps.aiEngine.calculateExplorePriority(bold, &boldFactors)
+	ps.aiEngine.calculateExplorePriority(timid, &timidFactors)
+	if factorValue(boldFactors, factorPersonality) <= factorValue(timidFactors, factorPersonality) {
+		t.Errorf("Expected a brave pet to want to explore more, got %+v and %+v", boldFactors, timidFactors)
+	}
+}
+
+// TestTraitDrift 勇气与谨慎此消彼长，特质限制在 0-1 之间，其他特质各自变化
+func TestTraitDrift(t *testing.T) {
+	traits := models.PersonalityTraits{Courage: 0.5, Greed: 0.5, Sociability: 0.5, Caution: 0.5, Curiosity: 0.5}
+	traits.Drift(models.TraitCourage, 0.1)
+	if traits.Courage != 0.6 || traits.Caution != 0.45 {
+		t.Errorf("Expected courage 0.6 and caution 0.45, got %+v", traits)
+	}
+	traits.Drift(models.TraitGreed, 0.2)
+	if traits.Greed != 0.7 || traits.Courage != 0.6 || traits.Caution != 0.45 {
+		t.Errorf("Expected only greed to change, got %+v", traits)
+	}
+	traits.Drift(models.TraitCaution, 2)
+	if traits.Caution != 1 || traits.Courage != 0 {
+		t.Errorf("Expected traits clamped to 0-1, got %+v", traits)
+	}
+}
+
+// TestPersonalityShift 其他特质明显更突出时性格标签才改变，改变时记录事件
+func TestPersonalityShift(t *testing.T) {
+	traits := models.PersonalityTraits{Courage: 0.54, Greed: 0.3, Sociability: 0.3, Caution: 0.5, Curiosity: 0.3}
+	if dominant := traits.Dominant(models.PersonalityCautious); dominant != models.PersonalityCautious {
+		t.Errorf("Expected a small lead not to change the label, got %s", dominant)
+	}
+	traits.Courage = 0.56
+	if dominant := traits.Dominant(models.PersonalityCautious); dominant != models.PersonalityBrave {
+		t.Errorf("Expected a clear lead to change the label, got %s", dominant)
+	}
+
+	ps := newTestPetService(t)
+	pet := newTestPet(t, ps, "shifty", models.PersonalityCautious)
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	events := len(ps.events)
+	ps.driftFromExplore(pet, models.Event{Type: models.EventBattle, Data: models.EventData{IsVictory: true}})
+	if pet.Traits.Courage != 0.31 || pet.Personality != models.PersonalityCautious || len(ps.events) != events {
+		t.Errorf("Expected a victory to add a little courage only, got %+v as %s", pet.Traits, pet.Personality)
+	}
+
+	ps.driftTrait(pet, models.TraitCourage, 0.5)
+	if pet.Personality != models.PersonalityBrave {
+		t.Fatalf("Expected the pet to become brave, got %s with %+v", pet.Personality, pet.Traits)
+	}
+	if len(ps.events) != events+1 || ps.events[len(ps.events)-1].Type != models.EventPersonality {
+		t.Error("Expected a personality change event")
+	}
+}
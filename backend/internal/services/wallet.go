@@ -0,0 +1,361 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// transferFeeRate 转账手续费率（百分比），最少1金币
+	transferFeeRate = 2
+	// maxTransferAmount 单笔转账上限
+	maxTransferAmount = 100000
+	// maxTransferMemoLength 转账备注最大长度（字符）
+	maxTransferMemoLength = 100
+)
+
+var (
+	// ErrTransferConflict 相同转账ID已被用于不同的转账
+	ErrTransferConflict = errors.New("transfer id already used with different parameters")
+)
+
+// WalletService 主人钱包服务，钱包数据由 PetService 的主锁保护
+type WalletService struct {
+	repo    *database.WalletRepository
+	wallets map[string]*models.Wallet
+}
+
+// TransferRequest 转账请求
+type TransferRequest struct {
+	TransferID string
+	FromOwner  string
+	ToOwner    string
+	Amount     int
+	Memo       string
+}
+
+// NewWalletService 创建钱包服务并加载已有钱包
+func NewWalletService() *WalletService {
+	ws := &WalletService{
+		repo:    database.NewWalletRepository(),
+		wallets: make(map[string]*models.Wallet),
+	}
+
+	wallets, err := ws.repo.GetAllWallets()
+	if err != nil {
+		log.Printf("Warning: failed to load wallets from database: %v", err)
+		return ws
+	}
+	for _, wallet := range wallets {
+		ws.wallets[wallet.Owner] = wallet
+	}
+
+	log.Printf("Loaded %d wallets from database", len(wallets))
+	return ws
+}
+
+// wallet 获取主人钱包，不存在则创建空钱包
+func (ws *WalletService) wallet(owner string) *models.Wallet {
+	wallet, exists := ws.wallets[owner]
+	if !exists {
+		wallet = &models.Wallet{Owner: owner, UpdatedAt: time.Now()}
+		ws.wallets[owner] = wallet
+	}
+	return wallet
+}
+
+func (ws *WalletService) adjust(owner string, delta int) *models.Wallet {
+	wallet := ws.wallet(owner)
+	wallet.Balance += delta
+	wallet.UpdatedAt = time.Now()
+	return wallet
+}
+
+// totalBalance 所有钱包余额之和
+func (ws *WalletService) totalBalance() int64 {
+	var total int64
+	for _, wallet := range ws.wallets {
+		total += int64(wallet.Balance)
+	}
+	return total
+}
+
+// transferFee 计算转账手续费
+func transferFee(amount int) int {
+	fee := amount * transferFeeRate / 100
+	if fee < 1 {
+		fee = 1
+	}
+	return fee
+}
+
+// findPetByOwner 在内存中查找主人的宠物，调用方需持有锁
+func (ps *PetService) findPetByOwner(owner string) *models.Pet {
+	for _, pet := range ps.pets {
+		if pet.Owner == owner {
+			return pet
+		}
+	}
+	return nil
+}
+
+// GetWallet 获取主人钱包
+func (ps *PetService) GetWallet(owner string) (*models.Wallet, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if ps.findPetByOwner(owner) == nil {
+		return nil, ErrPetNotFound
+	}
+
+	wallet := *ps.wallets.wallet(owner)
+	return &wallet, nil
+}
+
+// DepositToWallet 主人把宠物的金币存入钱包
+func (ps *PetService) DepositToWallet(owner string, amount int) (*models.Wallet, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet := ps.findPetByOwner(owner)
+	if pet == nil {
+		return nil, ErrPetNotFound
+	}
+
+	return ps.depositToWallet(pet, amount)
+}
+
+// WithdrawFromWallet 主人从钱包取出金币给宠物
+func (ps *PetService) WithdrawFromWallet(owner string, amount int) (*models.Wallet, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	pet := ps.findPetByOwner(owner)
+	if pet == nil {
+		return nil, ErrPetNotFound
+	}
+
+	return ps.withdrawFromWallet(pet, amount)
+}
+
+func (ps *PetService) depositToWallet(pet *models.Pet, amount int) (*models.Wallet, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if pet.Coins < amount {
+		return nil, fmt.Errorf("宠物金币不足（当前: %d，需要: %d）", pet.Coins, amount)
+	}
+
+	ps.debitCoins(pet, amount, models.WalletAccount(pet.Owner), models.ReasonWalletDeposit, "存入钱包")
+	ps.ledger.addWalletDelta(pet.ID, pet.Owner, amount)
+	wallet := ps.wallets.adjust(pet.Owner, amount)
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventTransfer,
+		Message:   fmt.Sprintf("[%s] 把%d金币交给了主人 %s 保管", pet.Name, amount, pet.Owner),
+		Timestamp: time.Now(),
+		Data:      models.EventData{Coins: -amount},
+	})
+	ps.savePetToDatabase(pet)
+
+	result := *wallet
+	return &result, nil
+}
+
+func (ps *PetService) withdrawFromWallet(pet *models.Pet, amount int) (*models.Wallet, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	wallet := ps.wallets.wallet(pet.Owner)
+	if wallet.Balance < amount {
+		return nil, fmt.Errorf("钱包余额不足（当前: %d，需要: %d）", wallet.Balance, amount)
+	}
+
+	ps.creditCoins(pet, amount, models.WalletAccount(pet.Owner), models.ReasonWalletWithdraw, "钱包取出")
+	ps.ledger.addWalletDelta(pet.ID, pet.Owner, -amount)
+	wallet = ps.wallets.adjust(pet.Owner, -amount)
+
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     pet.ID,
+		PetName:   pet.Name,
+		Type:      models.EventTransfer,
+		Message:   fmt.Sprintf("[%s] 从主人 %s 那里领到了%d金币", pet.Name, pet.Owner, amount),
+		Timestamp: time.Now(),
+		Data:      models.EventData{Coins: amount},
+	})
+	ps.savePetToDatabase(pet)
+
+	result := *wallet
+	return &result, nil
+}
+
+// TransferCoins 在两位主人的钱包之间转账
+// 转账ID由客户端提供：重复提交相同的转账会直接返回已有记录（duplicate 为 true），
+// 同一ID的双方、金额或备注不一致时返回 ErrTransferConflict
+func (ps *PetService) TransferCoins(req TransferRequest) (*models.Transfer, bool, error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if req.TransferID == "" || len(req.TransferID) > 64 {
+		return nil, false, fmt.Errorf("transfer_id is required (max 64 characters)")
+	}
+
+	existing, err := ps.wallets.repo.GetTransfer(req.TransferID)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		if existing.FromOwner != req.FromOwner || existing.ToOwner != req.ToOwner || existing.Amount != req.Amount || existing.Memo != req.Memo {
+			return nil, false, ErrTransferConflict
+		}
+		return existing, true, nil
+	}
+
+	if req.Amount <= 0 {
+		return nil, false, fmt.Errorf("amount must be positive")
+	}
+	if req.Amount > maxTransferAmount {
+		return nil, false, fmt.Errorf("amount too large (max: %d)", maxTransferAmount)
+	}
+	if len([]rune(req.Memo)) > maxTransferMemoLength {
+		return nil, false, fmt.Errorf("memo too long (max: %d)", maxTransferMemoLength)
+	}
+	if req.FromOwner == req.ToOwner {
+		return nil, false, fmt.Errorf("不能给自己转账")
+	}
+
+	fromPet := ps.findPetByOwner(req.FromOwner)
+	if fromPet == nil {
+		return nil, false, ErrPetNotFound
+	}
+	toPet := ps.findPetByOwner(req.ToOwner)
+	if toPet == nil {
+		return nil, false, fmt.Errorf("收款人 %s 不存在", req.ToOwner)
+	}
+
+	fee := transferFee(req.Amount)
+	if balance := ps.wallets.wallet(req.FromOwner).Balance; balance < req.Amount+fee {
+		return nil, false, fmt.Errorf("钱包余额不足（当前: %d，需要: %d，含手续费%d）", balance, req.Amount+fee, fee)
+	}
+
+	transfer := &models.Transfer{
+		ID:        req.TransferID,
+		FromOwner: req.FromOwner,
+		ToOwner:   req.ToOwner,
+		Amount:    req.Amount,
+		Fee:       fee,
+		Memo:      req.Memo,
+		CreatedAt: time.Now(),
+	}
+
+	entries := []*models.LedgerEntry{
+		models.NewLedgerEntry("", models.WalletAccount(req.ToOwner), models.WalletAccount(req.FromOwner), req.Amount, models.ReasonTransfer, req.Memo),
+		models.NewLedgerEntry("", models.AccountTreasury, models.WalletAccount(req.FromOwner), fee, models.ReasonTransferFee, transfer.ID),
+	}
+
+	// 转账记录、双方钱包与分录在同一事务中同步写入
+	if err := ps.wallets.repo.CreateTransfer(transfer, entries); err != nil {
+		return nil, false, err
+	}
+
+	ps.wallets.adjust(req.FromOwner, -(req.Amount + fee))
+	ps.wallets.adjust(req.ToOwner, req.Amount)
+	ps.recordEconomy(entries)
+
+	message := fmt.Sprintf("[%s] 的主人 %s 向 %s 转账%d金币", fromPet.Name, req.FromOwner, req.ToOwner, req.Amount)
+	if req.Memo != "" {
+		message = fmt.Sprintf("%s：%s", message, req.Memo)
+	}
+	ps.addEvent(models.Event{
+		ID:        uuid.New().String(),
+		PetID:     fromPet.ID,
+		PetName:   fromPet.Name,
+		Type:      models.EventTransfer,
+		Message:   message,
+		Timestamp: time.Now(),
+		Data:      models.EventData{Coins: req.Amount, FriendName: req.ToOwner},
+	})
+
+	return transfer, false, nil
+}
+
+// GetTransfers 分页获取主人的转账记录
+func (ps *PetService) GetTransfers(owner string, limit, offset int) (map[string]interface{}, error) {
+	pet, err := ps.GetPetByOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	if pet == nil {
+		return nil, ErrPetNotFound
+	}
+
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	transfers, total, err := ps.wallets.repo.GetTransfersByOwner(owner, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"owner":     owner,
+		"transfers": transfers,
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+	}, nil
+}
+
+func (ps *PetService) executeDepositCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	amount := 0
+	if a, ok := params["amount"].(float64); ok {
+		amount = int(a)
+	}
+
+	wallet, err := ps.depositToWallet(pet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":         "deposit",
+		"amount":         amount,
+		"coins":          pet.Coins,
+		"wallet_balance": wallet.Balance,
+		"message":        fmt.Sprintf("%s 存入钱包 %d 金币，钱包余额: %d", pet.Name, amount, wallet.Balance),
+	}, nil
+}
+
+func (ps *PetService) executeWithdrawCommand(pet *models.Pet, params map[string]interface{}) (interface{}, error) {
+	amount := 0
+	if a, ok := params["amount"].(float64); ok {
+		amount = int(a)
+	}
+
+	wallet, err := ps.withdrawFromWallet(pet, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"action":         "withdraw",
+		"amount":         amount,
+		"coins":          pet.Coins,
+		"wallet_balance": wallet.Balance,
+		"message":        fmt.Sprintf("%s 从钱包取出 %d 金币，当前金币: %d", pet.Name, amount, pet.Coins),
+	}, nil
+}
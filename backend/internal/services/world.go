@@ -0,0 +1,302 @@
+package services
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+)
+
+const (
+	// crowdingPenalty 同一地点每多一只宠物，所有人的产出按该系数递减
+	crowdingPenalty = 0.3
+	// minRichness 矿脉接近枯竭时仍能挖到的最低比例
+	minRichness = 0.1
+)
+
+// WorldService 世界资源服务，各地点的资源池由 PetService 的主锁保护
+type WorldService struct {
+	repo      *database.WorldRepository
+	resources map[string]*models.LocationResources
+	// 自上次保存以来发生变化的地点
+	dirty map[string]bool
+}
+
+// LocationInfo 世界地图上的一个地点
+type LocationInfo struct {
+	Location    string                 `json:"location"`
+	Ore         int                    `json:"ore"`
+	MaxOre      int                    `json:"max_ore"`
+	Treasure    int                    `json:"treasure"`
+	MaxTreasure int                    `json:"max_treasure"`
+	Richness    float64                `json:"richness"`
+	Pets        []string               `json:"pets"`
+	YieldFactor float64                `json:"yield_factor"` // 新来一只宠物时的产出系数
+	Profile     models.ResourceProfile `json:"profile"`
+}
+
+// NewWorldService 创建世界资源服务，加载已保存的资源存量，新地点以满资源开始
+func NewWorldService() *WorldService {
+	ws := &WorldService{
+		repo:      database.NewWorldRepository(),
+		resources: make(map[string]*models.LocationResources),
+		dirty:     make(map[string]bool),
+	}
+
+	saved, err := ws.repo.GetAllResources()
+	if err != nil {
+		log.Printf("Warning: failed to load location resources from database: %v", err)
+	}
+	for _, resource := range saved {
+		ws.resources[resource.Location] = resource
+	}
+
+	now := time.Now()
+	for location, profile := range models.LocationProfiles {
+		if _, exists := ws.resources[location]; exists {
+			continue
+		}
+		ws.resources[location] = &models.LocationResources{
+			Location:        location,
+			Ore:             profile.MaxOre,
+			Treasure:        profile.MaxTreasure,
+			OreRegenAt:      now,
+			TreasureRegenAt: now,
+			UpdatedAt:       now,
+		}
+		ws.dirty[location] = true
+	}
+
+	log.Printf("Loaded resources for %d locations", len(ws.resources))
+	return ws
+}
+
+// crowdFactor 地点有 crowd 只宠物时每只宠物的产出系数
+func crowdFactor(crowd int) float64 {
+	if crowd <= 1 {
+		return 1
+	}
+	return 1 / (1 + crowdingPenalty*float64(crowd-1))
+}
+
+// yieldFactor 综合矿脉充盈度和拥挤程度的产出系数
+func yieldFactor(resource *models.LocationResources, crowd int) float64 {
+	richness := resource.Richness()
+	if richness < minRichness && resource.Ore > 0 {
+		richness = minRichness
+	}
+	return richness * crowdFactor(crowd)
+}
+
+// regenerate 按经过的时间再生资源
+func (ws *WorldService) regenerate(now time.Time) {
+	for location, resource := range ws.resources {
+		profile, exists := models.LocationProfiles[location]
+		if !exists {
+			continue
+		}
+
+		if minutes := int(now.Sub(resource.OreRegenAt) / time.Minute); minutes > 0 {
+			resource.OreRegenAt = resource.OreRegenAt.Add(time.Duration(minutes) * time.Minute)
+			if resource.Ore < profile.MaxOre {
+				resource.Ore += minutes * profile.OreRegen
+				if resource.Ore > profile.MaxOre {
+					resource.Ore = profile.MaxOre
+				}
+			}
+			ws.dirty[location] = true
+		}
+
+		if hours := int(now.Sub(resource.TreasureRegenAt) / time.Hour); hours > 0 {
+			resource.TreasureRegenAt = resource.TreasureRegenAt.Add(time.Duration(hours) * time.Hour)
+			if resource.Treasure < profile.MaxTreasure {
+				resource.Treasure += hours * profile.TreasureRegen
+				if resource.Treasure > profile.MaxTreasure {
+					resource.Treasure = profile.MaxTreasure
+				}
+			}
+			ws.dirty[location] = true
+		}
+
+		if ws.dirty[location] {
+			resource.UpdatedAt = now
+		}
+	}
+}
+
+// takeDirty 取出需要保存的资源快照
+func (ws *WorldService) takeDirty() []models.LocationResources {
+	snapshot := make([]models.LocationResources, 0, len(ws.dirty))
+	for location := range ws.dirty {
+		if resource, exists := ws.resources[location]; exists {
+			snapshot = append(snapshot, *resource)
+		}
+	}
+	ws.dirty = make(map[string]bool)
+	return snapshot
+}
+
+// petsAt 统计地点上存活的宠物，调用方需持有锁
+func (ps *PetService) petsAt(location string) []*models.Pet {
+	var pets []*models.Pet
+	for _, pet := range ps.pets {
+		if pet.Location == location && pet.IsAlive() {
+			pets = append(pets, pet)
+		}
+	}
+	return pets
+}
+
+// mineOre 宠物在所在地点开采金币，产出受矿脉存量和拥挤程度影响并消耗矿脉，调用方需持有锁
+func (ps *PetService) mineOre(pet *models.Pet, base int) int {
+	resource, exists := ps.world.resources[pet.Location]
+	if !exists {
+		return base
+	}
+
+	amount := int(float64(base)*yieldFactor(resource, len(ps.petsAt(pet.Location))) + 0.5)
+	if amount > resource.Ore {
+		amount = resource.Ore
+	}
+	resource.Ore -= amount
+	resource.UpdatedAt = time.Now()
+	ps.world.dirty[resource.Location] = true
+	return amount
+}
+
+// takeTreasure 尝试从所在地点取走一份宝藏，宝藏越少越难找到，调用方需持有锁
+func (ps *PetService) takeTreasure(pet *models.Pet) bool {
+	resource, exists := ps.world.resources[pet.Location]
+	if !exists {
+		return true
+	}
+
+	profile := models.LocationProfiles[pet.Location]
+	if resource.Treasure <= 0 || rand.Intn(profile.MaxTreasure) >= resource.Treasure {
+		return false
+	}
+	resource.Treasure--
+	resource.UpdatedAt = time.Now()
+	ps.world.dirty[resource.Location] = true
+	return true
+}
+
+// runWorldRegeneration 定期再生资源并保存变化
+func (ps *PetService) runWorldRegeneration() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		ps.mutex.Lock()
+		ps.world.regenerate(time.Now())
+		snapshot := ps.world.takeDirty()
+		ps.mutex.Unlock()
+
+		if err := ps.world.repo.SaveResources(snapshot); err != nil {
+			log.Printf("Warning: failed to save location resources: %v", err)
+		}
+
+		select {
+		case <-ps.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// locationInfo 生成地点信息，调用方需持有锁
+func (ps *PetService) locationInfo(location string) LocationInfo {
+	resource := ps.world.resources[location]
+	profile := models.LocationProfiles[location]
+	pets := ps.petsAt(location)
+
+	names := make([]string, 0, len(pets))
+	for _, pet := range pets {
+		names = append(names, pet.Name)
+	}
+	sort.Strings(names)
+
+	return LocationInfo{
+		Location:    location,
+		Ore:         resource.Ore,
+		MaxOre:      profile.MaxOre,
+		Treasure:    resource.Treasure,
+		MaxTreasure: profile.MaxTreasure,
+		Richness:    resource.Richness(),
+		Pets:        names,
+		YieldFactor: yieldFactor(resource, len(pets)+1),
+		Profile:     profile,
+	}
+}
+
+// chooseLocation 综合矿脉、宝藏和拥挤程度为宠物挑选探索地点，返回地点及其评分
+func (ai *AIEngine) chooseLocation(pet *models.Pet) (string, float64) {
+	best, bestScore := "", -1.0
+	for _, location := range models.Locations {
+		resource, exists := ai.world.resources[location]
+		if !exists {
+			continue
+		}
+
+		crowd := ai.population(location)
+		if pet.Location != location {
+			crowd++
+		}
+		score := yieldFactor(resource, crowd)
+
+		profile := models.LocationProfiles[location]
+		treasure := float64(resource.Treasure) / float64(profile.MaxTreasure)
+		score += 0.4 * math.Max(pet.Traits.Greed, pet.Traits.Curiosity) * treasure
+		// 谨慎的宠物更讨厌人多的地方
+		score *= 1 - pet.Traits.Caution*(1-crowdFactor(crowd))
+
+		// 地点限定的世界事件（如寻宝节）吸引宠物前往
+		if ai.events != nil {
+			score += float64(ai.events.localModifiers(location).ExploreBonus) / 30
+		}
+
+		// 首领所在地点吸引健康的宠物前去讨伐，勇敢的宠物尤其积极，主人设定的冒险倾向会放大或抑制
+		if ai.boss != nil && ai.boss.bossAt(location) != nil && pet.Health*2 > pet.MaxHealth {
+			bonus := math.Max(0, 0.1+pet.Traits.Courage-0.3*pet.Traits.Caution)
+			score += bonus * float64(pet.Policy.RiskTolerance) / 50
+		}
+
+		// 主人偏爱的地点
+		if pet.Policy.Prefers(location) {
+			score += 0.5
+		}
+
+		// 过去在这里的收获好坏，以及在这里留下的回忆，比如被打得很惨的地方
+		score += learnedLocationBonus(pet, location, time.Now())
+		score += locationFeeling(pet, location, time.Now()) * memoryLocationInfluence
+
+		// 加一点随机性，避免所有宠物挤向同一个地方
+		score *= 0.85 + ai.rand.Float64()*0.3
+		if score > bestScore {
+			best, bestScore = location, score
+		}
+	}
+	return best, bestScore
+}
+
+// GetWorldMap 获取世界地图：各地点的资源存量与在场宠物
+func (ps *PetService) GetWorldMap() map[string]interface{} {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	locations := make([]LocationInfo, 0, len(ps.world.resources))
+	for _, location := range append([]string{bankLocation}, models.Locations...) {
+		if _, exists := ps.world.resources[location]; exists {
+			locations = append(locations, ps.locationInfo(location))
+		}
+	}
+
+	return map[string]interface{}{
+		"locations": locations,
+		"timestamp": time.Now(),
+	}
+}
@@ -0,0 +1,478 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// worldEventTick 世界事件调度间隔
+	worldEventTick = 30 * time.Second
+	// calendarHorizon 提前按日历排期的时长
+	calendarHorizon = 7 * 24 * time.Hour
+	// maxWorldEventDuration 手动创建的事件最长持续时间
+	maxWorldEventDuration = 30 * 24 * time.Hour
+	// maxWorldEventMultiplier 经验和金币倍率上限
+	maxWorldEventMultiplier = 10
+
+	NotificationWorldEventScheduled = "world_event_scheduled"
+	NotificationWorldEventStarted   = "world_event_started"
+	NotificationWorldEventEnded     = "world_event_ended"
+	NotificationWorldEventCancelled = "world_event_cancelled"
+)
+
+var (
+	// ErrWorldEventNotFound 世界事件不存在
+	ErrWorldEventNotFound = errors.New("world event not found")
+	// ErrWorldEventClosed 世界事件已结束或已取消
+	ErrWorldEventClosed = errors.New("world event is already over")
+)
+
+// WorldEventService 世界事件调度，未结束的事件由 PetService 的主锁保护
+type WorldEventService struct {
+	repo     *database.WorldEventRepository
+	calendar []models.CalendarEntry
+	// 尚未结束的事件，以及结束时间未到的已取消事件（避免日历重复排期）
+	events map[string]*models.WorldEvent
+	dirty  map[string]bool
+}
+
+// WorldEventRequest 创建世界事件的请求
+type WorldEventRequest struct {
+	Name        string
+	Description string
+	Location    string
+	Modifiers   models.WorldEventModifiers
+	StartAt     time.Time
+	EndAt       time.Time
+	CreatedBy   string
+}
+
+// WorldEventPreview 事件预览：校验后的事件、时间重叠的其他事件以及叠加后的效果
+type WorldEventPreview struct {
+	Event       *models.WorldEvent         `json:"event"`
+	Overlapping []models.WorldEvent        `json:"overlapping"`
+	Effective   models.WorldEventModifiers `json:"effective"`
+	Warnings    []string                   `json:"warnings,omitempty"`
+}
+
+// NewWorldEventService 创建世界事件服务并加载尚未结束的事件
+func NewWorldEventService() *WorldEventService {
+	ws := &WorldEventService{
+		repo:     database.NewWorldEventRepository(),
+		calendar: models.WorldCalendar,
+		events:   make(map[string]*models.WorldEvent),
+		dirty:    make(map[string]bool),
+	}
+
+	events, err := ws.repo.GetEventsEndingAfter(time.Now())
+	if err != nil {
+		log.Printf("Warning: failed to load world events from database: %v", err)
+		return ws
+	}
+	for _, event := range events {
+		ws.events[event.ID] = event
+	}
+
+	log.Printf("Loaded %d pending world events from database", len(events))
+	return ws
+}
+
+// collect 叠加符合条件的进行中事件的修正
+func (ws *WorldEventService) collect(match func(event *models.WorldEvent) bool) models.WorldEventModifiers {
+	var combined models.WorldEventModifiers
+	for _, event := range ws.sorted() {
+		if event.Status == models.WorldEventActive && match(event) {
+			combined = combined.Combine(event.Modifiers)
+		}
+	}
+	return combined
+}
+
+// modifiersAt 作用于地点的所有进行中事件（含全服事件）的叠加修正
+func (ws *WorldEventService) modifiersAt(location string) models.WorldEventModifiers {
+	return ws.collect(func(event *models.WorldEvent) bool { return event.AppliesTo(location) })
+}
+
+// globalModifiers 全服事件的叠加修正
+func (ws *WorldEventService) globalModifiers() models.WorldEventModifiers {
+	return ws.collect(func(event *models.WorldEvent) bool { return event.Location == "" })
+}
+
+// localModifiers 只作用于该地点的事件的叠加修正
+func (ws *WorldEventService) localModifiers(location string) models.WorldEventModifiers {
+	return ws.collect(func(event *models.WorldEvent) bool { return event.Location != "" && event.Location == location })
+}
+
+// sorted 按开始时间排列的事件
+func (ws *WorldEventService) sorted() []*models.WorldEvent {
+	events := make([]*models.WorldEvent, 0, len(ws.events))
+	for _, event := range ws.events {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].StartAt.Equal(events[j].StartAt) {
+			return events[i].StartAt.Before(events[j].StartAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+	return events
+}
+
+// takeDirty 取出需要保存的事件快照，并清理已经结束的事件
+func (ws *WorldEventService) takeDirty(now time.Time) []models.WorldEvent {
+	snapshot := make([]models.WorldEvent, 0, len(ws.dirty))
+	for id := range ws.dirty {
+		if event, exists := ws.events[id]; exists {
+			snapshot = append(snapshot, *event)
+		}
+	}
+	ws.dirty = make(map[string]bool)
+
+	for id, event := range ws.events {
+		finished := event.Status == models.WorldEventEnded || event.Status == models.WorldEventCancelled
+		if finished && !event.EndAt.After(now) {
+			delete(ws.events, id)
+		}
+	}
+	return snapshot
+}
+
+// calendarEventID 日历事件的ID由条目和开始日期决定，重复排期时不会生成两次
+func calendarEventID(entry models.CalendarEntry, start time.Time) string {
+	return fmt.Sprintf("%s:%s", entry.ID, start.Format("20060102"))
+}
+
+// expandCalendar 按日历为未来一段时间排期，调用方需持有锁
+func (ps *PetService) expandCalendar(now time.Time) {
+	for _, entry := range ps.worldEvents.calendar {
+		for _, start := range entry.Occurrences(now, now.Add(calendarHorizon)) {
+			id := calendarEventID(entry, start)
+			if _, exists := ps.worldEvents.events[id]; exists {
+				continue
+			}
+			ps.worldEvents.events[id] = &models.WorldEvent{
+				ID:          id,
+				CalendarID:  entry.ID,
+				Name:        entry.Name,
+				Description: entry.Description,
+				Location:    entry.Location,
+				Modifiers:   entry.Modifiers,
+				Status:      models.WorldEventScheduled,
+				StartAt:     start,
+				EndAt:       start.Add(entry.Duration),
+				CreatedBy:   "calendar",
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+			ps.worldEvents.dirty[id] = true
+		}
+	}
+}
+
+// advanceWorldEvents 开始和结束到期的事件，调用方需持有锁
+func (ps *PetService) advanceWorldEvents(now time.Time) {
+	for _, event := range ps.worldEvents.sorted() {
+		switch event.Status {
+		case models.WorldEventScheduled:
+			if !now.Before(event.EndAt) {
+				// 服务器停机期间错过了整个事件
+				event.Status = models.WorldEventEnded
+				event.UpdatedAt = now
+				ps.worldEvents.dirty[event.ID] = true
+			} else if !now.Before(event.StartAt) {
+				ps.startWorldEvent(event, now)
+			}
+		case models.WorldEventActive:
+			if !now.Before(event.EndAt) {
+				ps.endWorldEvent(event, now)
+			}
+		}
+	}
+}
+
+// describeModifiers 事件效果的文字说明
+func describeModifiers(modifiers models.WorldEventModifiers) string {
+	var effects []string
+	if modifiers.ExpRate() != 1 {
+		effects = append(effects, fmt.Sprintf("经验x%.1f", modifiers.ExpRate()))
+	}
+	if modifiers.CoinRate() != 1 {
+		effects = append(effects, fmt.Sprintf("金币x%.1f", modifiers.CoinRate()))
+	}
+	if modifiers.RareFindBonus > 0 {
+		effects = append(effects, fmt.Sprintf("稀有发现+%d%%", modifiers.RareFindBonus))
+	}
+	if len(modifiers.Monsters) > 0 {
+		names := make([]string, len(modifiers.Monsters))
+		for i, monster := range modifiers.Monsters {
+			names[i] = monster.Name
+		}
+		effects = append(effects, "限定怪物: "+strings.Join(names, "、"))
+	}
+	if template, exists := models.FindBossTemplate(modifiers.BossTemplate); exists {
+		effects = append(effects, "世界首领: "+template.Name)
+	}
+	return strings.Join(effects, "，")
+}
+
+func worldEventScope(event *models.WorldEvent) string {
+	if event.Location == "" {
+		return "全服"
+	}
+	return event.Location
+}
+
+// startWorldEvent 事件开始：广播公告，需要时召唤世界首领，调用方需持有锁
+func (ps *PetService) startWorldEvent(event *models.WorldEvent, now time.Time) {
+	event.Status = models.WorldEventActive
+	event.UpdatedAt = now
+	ps.worldEvents.dirty[event.ID] = true
+
+	message := fmt.Sprintf("📣 %s开始了！（%s，持续到%s）%s",
+		event.Name, worldEventScope(event), event.EndAt.Format("01-02 15:04"), describeModifiers(event.Modifiers))
+	ps.notify(Notification{Type: NotificationWorldEventStarted, Message: message, Data: *event})
+	log.Printf("World event %s started", event.ID)
+
+	if event.Modifiers.BossTemplate != "" {
+		if _, err := ps.spawnBoss(event.Modifiers.BossTemplate, event.Location, now); err != nil {
+			log.Printf("Warning: world event %s could not spawn boss: %v", event.ID, err)
+		}
+	}
+}
+
+// endWorldEvent 事件结束并广播公告，调用方需持有锁
+func (ps *PetService) endWorldEvent(event *models.WorldEvent, now time.Time) {
+	event.Status = models.WorldEventEnded
+	event.UpdatedAt = now
+	ps.worldEvents.dirty[event.ID] = true
+
+	message := fmt.Sprintf("%s（%s）结束了，感谢参与！", event.Name, worldEventScope(event))
+	ps.notify(Notification{Type: NotificationWorldEventEnded, Message: message, Data: *event})
+	log.Printf("World event %s ended", event.ID)
+}
+
+// runWorldEventScheduler 按日历排期并推进世界事件
+func (ps *PetService) runWorldEventScheduler() {
+	ticker := time.NewTicker(worldEventTick)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+
+		ps.mutex.Lock()
+		ps.expandCalendar(now)
+		ps.advanceWorldEvents(now)
+		snapshot := ps.worldEvents.takeDirty(now)
+		ps.mutex.Unlock()
+
+		if err := ps.worldEvents.repo.SaveEvents(snapshot); err != nil {
+			log.Printf("Warning: failed to save world events: %v", err)
+		}
+
+		select {
+		case <-ps.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// buildWorldEvent 校验请求并生成事件
+func buildWorldEvent(req WorldEventRequest, now time.Time) (*models.WorldEvent, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("事件名称不能为空")
+	}
+	if req.Location != "" && !isExploreLocation(req.Location) {
+		return nil, fmt.Errorf("未知地点: %s", req.Location)
+	}
+
+	startAt := req.StartAt
+	if startAt.IsZero() {
+		startAt = now
+	}
+	if !req.EndAt.After(startAt) {
+		return nil, fmt.Errorf("结束时间必须晚于开始时间")
+	}
+	if !req.EndAt.After(now) {
+		return nil, fmt.Errorf("结束时间已经过去")
+	}
+	if req.EndAt.Sub(startAt) > maxWorldEventDuration {
+		return nil, fmt.Errorf("事件最长持续%d天", int(maxWorldEventDuration/(24*time.Hour)))
+	}
+
+	modifiers := req.Modifiers
+	if modifiers.ExpMultiplier < 0 || modifiers.ExpMultiplier > maxWorldEventMultiplier ||
+		modifiers.CoinMultiplier < 0 || modifiers.CoinMultiplier > maxWorldEventMultiplier {
+		return nil, fmt.Errorf("倍率必须在0到%d之间", maxWorldEventMultiplier)
+	}
+	if modifiers.RareFindBonus < 0 || modifiers.RareFindBonus > 100 {
+		return nil, fmt.Errorf("稀有发现加成必须在0到100之间")
+	}
+	if modifiers.BossTemplate != "" {
+		if _, exists := models.FindBossTemplate(modifiers.BossTemplate); !exists {
+			return nil, fmt.Errorf("%w: %s", ErrBossNotFound, modifiers.BossTemplate)
+		}
+	}
+	for _, monster := range modifiers.Monsters {
+		if monster.Name == "" || monster.Health <= 0 || monster.ExpReward < 0 || monster.CoinReward < 0 {
+			return nil, fmt.Errorf("限定怪物设定无效: %+v", monster)
+		}
+	}
+
+	createdBy := req.CreatedBy
+	if createdBy == "" {
+		createdBy = "admin"
+	}
+
+	return &models.WorldEvent{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: req.Description,
+		Location:    req.Location,
+		Modifiers:   modifiers,
+		Status:      models.WorldEventScheduled,
+		StartAt:     startAt,
+		EndAt:       req.EndAt,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// PreviewWorldEvent 校验事件并展示它与其他事件叠加后的效果，不会保存
+func (ps *PetService) PreviewWorldEvent(req WorldEventRequest) (*WorldEventPreview, error) {
+	now := time.Now()
+	event, err := buildWorldEvent(req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	preview := &WorldEventPreview{Event: event, Overlapping: []models.WorldEvent{}, Effective: event.Modifiers}
+	for _, other := range ps.worldEvents.sorted() {
+		if other.Status == models.WorldEventEnded || other.Status == models.WorldEventCancelled {
+			continue
+		}
+		if !other.StartAt.Before(event.EndAt) || !event.StartAt.Before(other.EndAt) {
+			continue
+		}
+		preview.Overlapping = append(preview.Overlapping, *other)
+		if other.AppliesTo(event.Location) || event.Location == "" {
+			preview.Effective = preview.Effective.Combine(other.Modifiers)
+		}
+		if other.Modifiers.BossTemplate != "" && event.Modifiers.BossTemplate != "" {
+			preview.Warnings = append(preview.Warnings, fmt.Sprintf("与%s同时召唤首领，只有先开始的事件能召唤成功", other.Name))
+		}
+	}
+	if event.Modifiers.BossTemplate != "" && ps.boss.active != nil && !event.StartAt.After(ps.boss.active.ExpiresAt) {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("世界首领%s仍在进行中，到时可能无法召唤新首领", ps.boss.active.Name))
+	}
+
+	return preview, nil
+}
+
+// CreateWorldEvent 创建世界事件，开始时间已到时立即开始
+func (ps *PetService) CreateWorldEvent(req WorldEventRequest) (*models.WorldEvent, error) {
+	now := time.Now()
+	event, err := buildWorldEvent(req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.mutex.Lock()
+	ps.worldEvents.events[event.ID] = event
+	ps.worldEvents.dirty[event.ID] = true
+
+	message := fmt.Sprintf("📅 新的世界事件：%s（%s），%s开始", event.Name, worldEventScope(event), event.StartAt.Format("01-02 15:04"))
+	ps.notify(Notification{Type: NotificationWorldEventScheduled, Message: message, Data: *event})
+	if !now.Before(event.StartAt) {
+		ps.startWorldEvent(event, now)
+	}
+
+	result := *event
+	snapshot := ps.worldEvents.takeDirty(now)
+	ps.mutex.Unlock()
+
+	if err := ps.worldEvents.repo.SaveEvents(snapshot); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CancelWorldEvent 取消尚未结束的事件，进行中的事件立即失效
+func (ps *PetService) CancelWorldEvent(id string) (*models.WorldEvent, error) {
+	now := time.Now()
+
+	ps.mutex.Lock()
+	event, exists := ps.worldEvents.events[id]
+	if !exists {
+		ps.mutex.Unlock()
+		return nil, ErrWorldEventNotFound
+	}
+	if event.Status == models.WorldEventEnded || event.Status == models.WorldEventCancelled {
+		ps.mutex.Unlock()
+		return nil, ErrWorldEventClosed
+	}
+
+	wasActive := event.Status == models.WorldEventActive
+	event.Status = models.WorldEventCancelled
+	event.UpdatedAt = now
+	ps.worldEvents.dirty[event.ID] = true
+
+	message := fmt.Sprintf("%s（%s）已取消", event.Name, worldEventScope(event))
+	if wasActive {
+		message = fmt.Sprintf("%s（%s）提前结束了", event.Name, worldEventScope(event))
+	}
+	ps.notify(Notification{Type: NotificationWorldEventCancelled, Message: message, Data: *event})
+	log.Printf("World event %s cancelled", event.ID)
+
+	result := *event
+	snapshot := ps.worldEvents.takeDirty(now)
+	ps.mutex.Unlock()
+
+	if err := ps.worldEvents.repo.SaveEvents(snapshot); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetWorldEvents 获取进行中和即将开始的世界事件
+func (ps *PetService) GetWorldEvents() map[string]interface{} {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	active := make([]models.WorldEvent, 0)
+	upcoming := make([]models.WorldEvent, 0)
+	for _, event := range ps.worldEvents.sorted() {
+		switch event.Status {
+		case models.WorldEventActive:
+			active = append(active, *event)
+		case models.WorldEventScheduled:
+			upcoming = append(upcoming, *event)
+		}
+	}
+
+	return map[string]interface{}{
+		"active":    active,
+		"upcoming":  upcoming,
+		"modifiers": ps.worldEvents.globalModifiers(),
+		"timestamp": time.Now(),
+	}
+}
+
+// GetWorldEventHistory 分页获取世界事件记录
+func (ps *PetService) GetWorldEventHistory(status string, limit, offset int) ([]*models.WorldEvent, error) {
+	return ps.worldEvents.repo.GetEvents(status, limit, offset)
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// TestWorldEventLifecycle 无效的事件被拒绝；事件到时开始和结束，进行中的修正按地点叠加；结束后不能再取消
+func TestWorldEventLifecycle(t *testing.T) {
+	ps := newTestPetService(t)
+	ps.mutex.Lock()
+	ps.worldEvents.calendar = nil
+	ps.mutex.Unlock()
+
+	now := time.Now()
+	const location = "神秘洞穴"
+	for name, req := range map[string]WorldEventRequest{
+		"no name":          {EndAt: now.Add(time.Hour)},
+		"unknown location": {Name: "x", Location: "月球", EndAt: now.Add(time.Hour)},
+		"ends first":       {Name: "x", StartAt: now.Add(2 * time.Hour), EndAt: now.Add(time.Hour)},
+		"too long":         {Name: "x", EndAt: now.Add(maxWorldEventDuration + time.Hour)},
+		"multiplier":       {Name: "x", EndAt: now.Add(time.Hour), Modifiers: models.WorldEventModifiers{ExpMultiplier: maxWorldEventMultiplier + 1}},
+		"rare bonus":       {Name: "x", EndAt: now.Add(time.Hour), Modifiers: models.WorldEventModifiers{RareFindBonus: 101}},
+		"unknown boss":     {Name: "x", EndAt: now.Add(time.Hour), Modifiers: models.WorldEventModifiers{BossTemplate: "missing"}},
+	} {
+		if _, err := ps.CreateWorldEvent(req); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+
+	global, err := ps.CreateWorldEvent(WorldEventRequest{Name: "双倍经验", EndAt: now.Add(time.Hour), Modifiers: models.WorldEventModifiers{ExpMultiplier: 2}})
+	if err != nil || global.Status != models.WorldEventActive {
+		t.Fatalf("Expected the global event to start at once, got %+v (%v)", global, err)
+	}
+	local, err := ps.CreateWorldEvent(WorldEventRequest{
+		Name: "寻宝节", Location: location, StartAt: now.Add(time.Hour), EndAt: now.Add(3 * time.Hour),
+		Modifiers: models.WorldEventModifiers{ExpMultiplier: 1.5, CoinMultiplier: 2, RareFindBonus: 10},
+	})
+	if err != nil || local.Status != models.WorldEventScheduled {
+		t.Fatalf("Expected the local event to be scheduled, got %+v (%v)", local, err)
+	}
+
+	ps.mutex.Lock()
+	if modifiers := ps.worldEvents.modifiersAt(location); modifiers.ExpRate() != 2 || modifiers.CoinRate() != 1 {
+		t.Errorf("Expected only the global event to apply before the festival, got %+v", modifiers)
+	}
+
+	ps.advanceWorldEvents(now.Add(90 * time.Minute))
+	if ps.worldEvents.events[global.ID].Status != models.WorldEventEnded || ps.worldEvents.events[local.ID].Status != models.WorldEventActive {
+		t.Fatalf("Expected the global event ended and the festival started, got %s and %s",
+			ps.worldEvents.events[global.ID].Status, ps.worldEvents.events[local.ID].Status)
+	}
+	if modifiers := ps.worldEvents.modifiersAt(location); modifiers.ExpRate() != 1.5 || modifiers.CoinRate() != 2 || modifiers.RareFindBonus != 10 {
+		t.Errorf("Expected the festival modifiers at %s, got %+v", location, modifiers)
+	}
+	if modifiers := ps.worldEvents.modifiersAt(bankLocation); modifiers.CoinRate() != 1 {
+		t.Errorf("Expected the festival not to apply elsewhere, got %+v", modifiers)
+	}
+
+	ps.mutex.Unlock()
+
+	_, endedErr := ps.CancelWorldEvent(global.ID)
+	_, missingErr := ps.CancelWorldEvent("missing")
+	cancelled, cancelErr := ps.CancelWorldEvent(local.ID)
+	if !errors.Is(endedErr, ErrWorldEventClosed) || !errors.Is(missingErr, ErrWorldEventNotFound) {
+		t.Errorf("Expected closed and not found errors, got %v and %v", endedErr, missingErr)
+	}
+	if cancelErr != nil || cancelled.Status != models.WorldEventCancelled {
+		t.Errorf("Expected the festival cancelled, got %+v (%v)", cancelled, cancelErr)
+	}
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	if modifiers := ps.worldEvents.modifiersAt(location); modifiers.CoinRate() != 1 {
+		t.Errorf("Expected a cancelled event to stop applying, got %+v", modifiers)
+	}
+}
+
+// TestWorldCalendar 日历事件按周排期，已开始尚未结束的一次也会被排上，重复排期不会产生重复事件
+func TestWorldCalendar(t *testing.T) {
+	entry := models.CalendarEntry{ID: "weekend", Name: "周末", Weekday: time.Saturday, Hour: 0, Duration: 48 * time.Hour}
+
+	wednesday := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	starts := entry.Occurrences(wednesday, wednesday.Add(7*24*time.Hour))
+	if len(starts) != 1 || !starts[0].Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected one weekend starting on Saturday, got %v", starts)
+	}
+	sunday := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	if starts := entry.Occurrences(sunday, sunday.Add(time.Hour)); len(starts) != 1 || !starts[0].Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the running weekend to be found, got %v", starts)
+	}
+
+	ps := newTestPetService(t)
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.worldEvents.calendar = []models.CalendarEntry{entry}
+	ps.worldEvents.events = make(map[string]*models.WorldEvent)
+	ps.expandCalendar(sunday)
+	ps.expandCalendar(sunday)
+	if len(ps.worldEvents.events) != 2 {
+		t.Fatalf("Expected this and next weekend scheduled once each, got %d", len(ps.worldEvents.events))
+	}
+	ps.advanceWorldEvents(sunday)
+	current := ps.worldEvents.events[calendarEventID(entry, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))]
+	if current == nil || current.Status != models.WorldEventActive {
+		t.Errorf("Expected the running weekend to be active, got %+v", current)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"miningpet/internal/models"
+)
+
+// TestWorldResources 开采按拥挤程度和矿脉存量减产并消耗矿脉，资源按经过的时间再生且不超过上限
+func TestWorldResources(t *testing.T) {
+	ps := newTestPetService(t)
+	miner := newTestPet(t, ps, "miner", models.PersonalityGreedy)
+	rival := newTestPet(t, ps, "rival", models.PersonalityGreedy)
+
+	const location = "北方森林"
+	profile := models.LocationProfiles[location]
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	resource := ps.world.resources[location]
+	miner.Location = location
+	if amount := ps.mineOre(miner, 10); amount != 10 || resource.Ore != profile.MaxOre-10 {
+		t.Errorf("Expected full yield from a rich vein, got %d (ore %d)", amount, resource.Ore)
+	}
+
+	// 两只宠物同时开采时每只只有 1/1.3
+	rival.Location = location
+	if amount := ps.mineOre(miner, 13); amount != 10 {
+		t.Errorf("Expected crowded yield 10, got %d", amount)
+	}
+
+	// 接近枯竭时按最低比例产出，且不超过剩余存量
+	rival.Location = bankLocation
+	resource.Ore = 3
+	if amount := ps.mineOre(miner, 100); amount != 3 || resource.Ore != 0 {
+		t.Errorf("Expected the last 3 ore mined, got %d (ore %d)", amount, resource.Ore)
+	}
+	if amount := ps.mineOre(miner, 100); amount != 0 {
+		t.Errorf("Expected nothing from an empty vein, got %d", amount)
+	}
+
+	resource.Treasure = 0
+	if ps.takeTreasure(miner) {
+		t.Error("Expected no treasure from an emptied location")
+	}
+
+	now := time.Now()
+	resource.OreRegenAt = now.Add(-5 * time.Minute)
+	resource.TreasureRegenAt = now.Add(-time.Hour)
+	ps.world.regenerate(now)
+	if resource.Ore != 5*profile.OreRegen || resource.Treasure != profile.TreasureRegen {
+		t.Errorf("Expected ore %d and treasure %d after regeneration, got %d and %d",
+			5*profile.OreRegen, profile.TreasureRegen, resource.Ore, resource.Treasure)
+	}
+
+	resource.OreRegenAt = now.Add(-24 * time.Hour)
+	resource.TreasureRegenAt = now.Add(-24 * time.Hour)
+	ps.world.regenerate(now)
+	if resource.Ore != profile.MaxOre || resource.Treasure != profile.MaxTreasure {
+		t.Errorf("Expected resources capped at the maximum, got ore %d treasure %d", resource.Ore, resource.Treasure)
+	}
+	if !ps.world.dirty[location] {
+		t.Error("Expected regenerated location to be saved")
+	}
+}
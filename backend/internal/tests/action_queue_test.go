@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"testing"
+
+	"miningpet/internal/services"
+)
+
+// TestParseActionPlan 计划文本按箭头拆分为依次执行的步骤
+func TestParseActionPlan(t *testing.T) {
+	steps, err := services.ParseActionPlan("explore 北方森林 → rest → feed 30 -> socialize → craft healing_potion")
+	if err != nil {
+		t.Fatalf("Failed to parse plan: %v", err)
+	}
+	commands := []string{"explore", "rest", "feed", "socialize", "craft"}
+	if len(steps) != len(commands) {
+		t.Fatalf("Expected %d steps, got %d", len(commands), len(steps))
+	}
+	for i, step := range steps {
+		if step.Command != commands[i] || step.ID == "" {
+			t.Errorf("Unexpected step %d: %+v", i, step)
+		}
+	}
+	if steps[0].Params["location"] != "北方森林" {
+		t.Errorf("Unexpected explore params: %v", steps[0].Params)
+	}
+	if len(steps[1].Params) != 0 {
+		t.Errorf("Expected rest without params, got %v", steps[1].Params)
+	}
+	if steps[2].Params["amount"] != float64(30) {
+		t.Errorf("Unexpected feed params: %v", steps[2].Params)
+	}
+	if steps[4].Params["recipe"] != "healing_potion" {
+		t.Errorf("Unexpected craft params: %v", steps[4].Params)
+	}
+}
+
+// TestParseActionPlanRejectsInvalidSteps 只能排入允许的指令，参数必须合法
+func TestParseActionPlanRejectsInvalidSteps(t *testing.T) {
+	invalid := []string{
+		"",
+		" → ",
+		"addcoins 1000",
+		"rest → deposit 50",
+		"explore 月球",
+		"feed -5",
+		"rest soon",
+		"socialize now",
+		"craft unknown_recipe",
+	}
+	for _, plan := range invalid {
+		if _, err := services.ParseActionPlan(plan); err == nil {
+			t.Errorf("Expected %q to be rejected", plan)
+		}
+	}
+}
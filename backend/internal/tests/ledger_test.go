@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"testing"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"miningpet/internal/services"
+)
+
+// newIsolatedPetService 在临时目录中初始化数据库并创建服务，避免污染仓库中的测试数据，
+// 测试结束时先停止服务再关闭数据库
+func newIsolatedPetService(t *testing.T) *services.PetService {
+	if err := database.InitializeAt(t.TempDir()); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	petService := services.NewPetService()
+	t.Cleanup(petService.Stop)
+	return petService
+}
+
+// TestLedgerReconciliation 金币变化都应记账，且账本余额与宠物金币一致
+func TestLedgerReconciliation(t *testing.T) {
+	petService := newIsolatedPetService(t)
+
+	// 贪婪性格的宠物初始带有50金币
+	pet, err := petService.CreatePetWithTraits("ledger", models.TraitsFor(models.PersonalityGreedy))
+	if err != nil {
+		t.Fatalf("Failed to create pet: %v", err)
+	}
+
+	if _, err := petService.ExecuteCommand(pet.ID, "addcoins", map[string]interface{}{"amount": float64(100)}); err != nil {
+		t.Fatalf("Failed to add coins: %v", err)
+	}
+	if err := petService.FeedPet(pet.ID, 20); err != nil {
+		t.Fatalf("Failed to feed pet: %v", err)
+	}
+
+	// 立即刷新批量写入，不等待定时器
+	database.FlushBatchManagers()
+
+	report, err := petService.ReconcileLedger()
+	if err != nil {
+		t.Fatalf("Failed to reconcile ledger: %v", err)
+	}
+	if len(report.Mismatches) != 0 {
+		t.Errorf("Expected no ledger mismatches, got %+v", report.Mismatches)
+	}
+
+	ledger, err := petService.GetPetLedger(pet.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get ledger: %v", err)
+	}
+	if total := ledger["total"].(int64); total != 3 {
+		t.Errorf("Expected 3 ledger entries, got %d", total)
+	}
+	if coins := ledger["coins"].(int); coins != 140 {
+		t.Errorf("Expected 140 coins, got %d", coins)
+	}
+}
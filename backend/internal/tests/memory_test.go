@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"testing"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"miningpet/internal/services"
+)
+
+// TestMigrateLegacyMemories 旧版文字记忆迁移为 legacy 记忆并保持原来的顺序，重复迁移不会产生重复记忆
+func TestMigrateLegacyMemories(t *testing.T) {
+	petService := newIsolatedPetService(t)
+	pet, err := petService.CreatePetWithTraits("veteran", models.TraitsFor(models.PersonalityCurious))
+	if err != nil {
+		t.Fatalf("Failed to create pet: %v", err)
+	}
+	petService.Stop()
+	database.FlushBatchManagers()
+
+	legacy := []string{"在北方森林打败了史莱姆", "认识了新朋友小白"}
+	if err := database.DB.Exec("UPDATE pets SET memory = ? WHERE id = ?", `["在北方森林打败了史莱姆","认识了新朋友小白"]`, pet.ID).Error; err != nil {
+		t.Fatalf("Failed to write legacy memory: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := database.MigrateLegacyMemories(); err != nil {
+			t.Fatalf("Failed to migrate legacy memories: %v", err)
+		}
+	}
+
+	restarted := services.NewPetService()
+	t.Cleanup(restarted.Stop)
+	memories, err := restarted.QueryMemories(pet.ID, services.MemoryFilter{Kind: string(models.MemoryLegacy)})
+	if err != nil {
+		t.Fatalf("Failed to query memories: %v", err)
+	}
+	// 查询结果最近的在前
+	if len(memories) != len(legacy) || memories[0].Summary != legacy[1] || memories[1].Summary != legacy[0] {
+		t.Fatalf("Expected legacy memories %v, got %+v", legacy, memories)
+	}
+
+	reloaded, _ := restarted.GetPet(pet.ID)
+	if len(reloaded.Memories) != len(legacy) || reloaded.Memories[0].Summary != legacy[0] {
+		t.Errorf("Expected the pet to remember %v after restart, got %+v", legacy, reloaded.Memories)
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"miningpet/internal/models"
+	"miningpet/internal/services"
+)
+
+// fixedNarrator 返回固定叙述的后备叙述器
+type fixedNarrator string
+
+func (n fixedNarrator) Narrate(narrative services.Narrative) string {
+	return string(n)
+}
+
+var battleNarrative = services.Narrative{
+	Kind:        services.NarrateBattleWon,
+	PetName:     "Thunder",
+	Personality: models.PersonalityBrave,
+	Enemy:       "哥布林",
+	Experience:  20,
+	Coins:       15,
+}
+
+// TestTemplateNarrator 模板叙述代入全部事件数据，不留下未展开的符号
+func TestTemplateNarrator(t *testing.T) {
+	narrator := services.NewTemplateNarrator()
+	for i := 0; i < 20; i++ {
+		text := narrator.Narrate(battleNarrative)
+		if !strings.Contains(text, "哥布林") || !strings.Contains(text, "+20") || !strings.Contains(text, "+15") {
+			t.Errorf("Narration is missing event data: %s", text)
+		}
+		if strings.ContainsAny(text, "{}") {
+			t.Errorf("Narration has unexpanded symbols: %s", text)
+		}
+	}
+}
+
+// TestTemplateNarratorKinds 各类事件在每种性格下都有完整的叙述，并保留事件中的数字
+func TestTemplateNarratorKinds(t *testing.T) {
+	narrator := services.NewTemplateNarrator()
+	kinds := []services.NarrativeKind{
+		services.NarrateMeal, services.NarrateBankDeposit, services.NarrateInterest, services.NarrateLoanOverdue,
+		services.NarrateAuctionSold, services.NarrateBuyGear, services.NarrateCrafted, services.NarrateBossHit,
+		services.NarrateBossTopReward, services.NarrateInterrupted, services.NarrateDigest,
+	}
+	personalities := []models.PetPersonality{
+		models.PersonalityBrave, models.PersonalityGreedy, models.PersonalityFriendly,
+		models.PersonalityCautious, models.PersonalityCurious,
+	}
+	for _, kind := range kinds {
+		for _, personality := range personalities {
+			for i := 0; i < 10; i++ {
+				text := narrator.Narrate(services.Narrative{
+					Kind: kind, Personality: personality, Location: "起始村庄", Enemy: "巨龙", Item: "铁剑",
+					Counterpart: "Luna", Cause: "主人召回", Activity: "探索中", Outcome: "空手而归", Elapsed: "2小时0分钟", Summary: "探索3次",
+					Coins: 37, Hunger: 41, Balance: 53, Fee: 2, Percent: 25, Damage: 19, Taken: 11, Rank: 1, Experience: 29,
+					Quantity: 3, Attack: 5, Defense: 4, BossHP: 71, BossMaxHP: 300,
+				})
+				if text == string(kind) || strings.ContainsAny(text, "{}") {
+					t.Errorf("Incomplete %s narration for %s: %q", kind, personality, text)
+				}
+			}
+		}
+	}
+}
+
+// TestHTTPNarrator 外部叙述服务的结果按事件数据缓存
+func TestHTTPNarrator(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var body struct {
+			Prompt string             `json:"prompt"`
+			Event  services.Narrative `json:"event"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Event.Enemy != "哥布林" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"text": "一拳打飞了哥布林\n多余的第二行"})
+	}))
+	defer server.Close()
+
+	narrator := services.NewHTTPNarrator(server.URL, time.Second, fixedNarrator("模板叙述"))
+	for i := 0; i < 3; i++ {
+		if text := narrator.Narrate(battleNarrative); text != "一拳打飞了哥布林" {
+			t.Errorf("Expected generated narration, got %q", text)
+		}
+	}
+	if count := atomic.LoadInt32(&requests); count != 1 {
+		t.Errorf("Expected 1 request to narrator service, got %d", count)
+	}
+}
+
+// TestHTTPNarratorFallback 外部服务出错或超时时退回模板叙述
+func TestHTTPNarratorFallback(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]string{"text": "来得太迟的叙述"})
+	}))
+	defer slow.Close()
+
+	for _, url := range []string{failing.URL, slow.URL} {
+		narrator := services.NewHTTPNarrator(url, 50*time.Millisecond, fixedNarrator("模板叙述"))
+		if text := narrator.Narrate(battleNarrative); text != "模板叙述" {
+			t.Errorf("Expected fallback narration from %s, got %q", url, text)
+		}
+	}
+}
+
+// TestHTTPNarratorDraft 草稿不等待外部服务，改写成功后同样的事件直接使用缓存的叙述
+func TestHTTPNarratorDraft(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"text": "一拳打飞了哥布林"})
+	}))
+	defer server.Close()
+
+	narrator := services.NewHTTPNarrator(server.URL, time.Second, fixedNarrator("模板叙述"))
+	if text, ready := narrator.Draft(battleNarrative); text != "模板叙述" || ready {
+		t.Fatalf("Expected template draft before rewriting, got %q (ready=%v)", text, ready)
+	}
+	if text, ok := narrator.Rewrite(battleNarrative, "模板叙述"); !ok || text != "一拳打飞了哥布林" {
+		t.Fatalf("Expected rewritten narration, got %q (ok=%v)", text, ok)
+	}
+	if text, ready := narrator.Draft(battleNarrative); text != "一拳打飞了哥布林" || !ready {
+		t.Errorf("Expected cached narration as draft, got %q (ready=%v)", text, ready)
+	}
+}
+
+// TestHTTPNarratorKeepsNumbers 改动了事件数字的叙述被丢弃，但不会暂停外部服务
+func TestHTTPNarratorKeepsNumbers(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		json.NewEncoder(w).Encode(map[string]string{"text": "一拳打飞了哥布林，经验+20，金币+50"})
+	}))
+	defer server.Close()
+
+	draft := fixedNarrator("击败了哥布林！获得经验+20，金币+15")
+	narrator := services.NewHTTPNarrator(server.URL, time.Second, draft)
+	for i := 0; i < 2; i++ {
+		if text := narrator.Narrate(battleNarrative); text != string(draft) {
+			t.Errorf("Expected template narration when numbers change, got %q", text)
+		}
+	}
+	if count := atomic.LoadInt32(&requests); count != 2 {
+		t.Errorf("Expected narrator service to stay available, got %d requests", count)
+	}
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"testing"
+
+	"miningpet/internal/services"
+)
+
+// TestParseRule 合法的规则解析为条件和指令
+func TestParseRule(t *testing.T) {
+	rule, err := services.ParseRule("if health < 40% and status == 等待中 then rest 30")
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+	if len(rule.Conditions) != 2 {
+		t.Fatalf("Expected 2 conditions, got %d", len(rule.Conditions))
+	}
+	health := rule.Conditions[0]
+	if health.Field != "health" || health.Op != "<" || health.Value != 40 || !health.Percent {
+		t.Errorf("Unexpected health condition: %+v", health)
+	}
+	if status := rule.Conditions[1]; status.Field != "status" || status.Text != "等待中" {
+		t.Errorf("Unexpected status condition: %+v", status)
+	}
+	if rule.Command != "rest" || rule.Params["duration"] != float64(30) {
+		t.Errorf("Unexpected action: %s %v", rule.Command, rule.Params)
+	}
+
+	rule, err = services.ParseRule("IF coins>1000 THEN deposit 500")
+	if err != nil {
+		t.Fatalf("Failed to parse compact rule: %v", err)
+	}
+	if c := rule.Conditions[0]; c.Field != "coins" || c.Op != ">" || c.Value != 1000 {
+		t.Errorf("Unexpected coins condition: %+v", c)
+	}
+
+	if rule, err = services.ParseRule("if event battle then rest"); err != nil || rule.Conditions[0].Text != "battle" {
+		t.Errorf("Failed to parse event rule: %+v, %v", rule, err)
+	}
+}
+
+// TestParseRuleRejectsUnsafeInput 规则只能引用允许的字段和指令
+func TestParseRuleRejectsUnsafeInput(t *testing.T) {
+	invalid := []string{
+		"",
+		"health < 40 then rest",
+		"if health < 40 rest",
+		"if health < 40 then",
+		"if password == x then rest",
+		"if coins < 10% then feed",
+		"if health ~ 40 then rest",
+		"if health < -1 then rest",
+		"if coins > 10 then addcoins 1000",
+		"if coins > 10 then deposit",
+		"if health < 40 then socialize now",
+		"if event unknown then rest",
+		"if energy > 50 then explore 月球",
+		"if status < 等待中 then rest",
+	}
+	for _, source := range invalid {
+		if _, err := services.ParseRule(source); err == nil {
+			t.Errorf("Expected %q to be rejected", source)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"miningpet/internal/database"
+	"miningpet/internal/models"
+	"miningpet/internal/services"
+)
+
+// TestWalletTransfer 转账收取手续费，重复提交同一转账不会重复扣款，且钱包与账本一致
+func TestWalletTransfer(t *testing.T) {
+	petService := newIsolatedPetService(t)
+	for _, owner := range []string{"alice", "bob"} {
+		if _, err := petService.CreatePetWithTraits(owner, models.TraitsFor(models.PersonalityGreedy)); err != nil {
+			t.Fatalf("Failed to create pet for %s: %v", owner, err)
+		}
+	}
+	alice, _ := petService.GetPetByOwner("alice")
+	if _, err := petService.ExecuteCommand(alice.ID, "addcoins", map[string]interface{}{"amount": float64(150)}); err != nil {
+		t.Fatalf("Failed to add coins: %v", err)
+	}
+	if _, err := petService.DepositToWallet("alice", 200); err != nil {
+		t.Fatalf("Failed to deposit to wallet: %v", err)
+	}
+
+	req := services.TransferRequest{TransferID: "transfer-1", FromOwner: "alice", ToOwner: "bob", Amount: 100}
+	transfer, replayed, err := petService.TransferCoins(req)
+	if err != nil {
+		t.Fatalf("Failed to transfer: %v", err)
+	}
+	if replayed || transfer.Fee != 2 {
+		t.Errorf("Expected new transfer with fee 2, got replayed=%v fee=%d", replayed, transfer.Fee)
+	}
+
+	// 客户端重试同一转账
+	if _, replayed, err := petService.TransferCoins(req); err != nil || !replayed {
+		t.Errorf("Expected replayed transfer, got replayed=%v err=%v", replayed, err)
+	}
+	conflict := req
+	conflict.Amount = 50
+	if _, _, err := petService.TransferCoins(conflict); !errors.Is(err, services.ErrTransferConflict) {
+		t.Errorf("Expected transfer conflict, got %v", err)
+	}
+	conflict = req
+	conflict.Memo = "午饭钱"
+	if _, _, err := petService.TransferCoins(conflict); !errors.Is(err, services.ErrTransferConflict) {
+		t.Errorf("Expected transfer conflict for a different memo, got %v", err)
+	}
+	// 余额98不够转98再付2金币手续费
+	if _, _, err := petService.TransferCoins(services.TransferRequest{TransferID: "transfer-2", FromOwner: "alice", ToOwner: "bob", Amount: 98}); err == nil {
+		t.Error("Expected insufficient balance error")
+	}
+
+	for owner, expected := range map[string]int{"alice": 98, "bob": 100} {
+		wallet, err := petService.GetWallet(owner)
+		if err != nil {
+			t.Fatalf("Failed to get wallet for %s: %v", owner, err)
+		}
+		if wallet.Balance != expected {
+			t.Errorf("Expected %s wallet balance %d, got %d", owner, expected, wallet.Balance)
+		}
+	}
+
+	database.FlushBatchManagers()
+	report, err := petService.ReconcileLedger()
+	if err != nil {
+		t.Fatalf("Failed to reconcile ledger: %v", err)
+	}
+	if len(report.Mismatches) != 0 || len(report.WalletMismatches) != 0 {
+		t.Errorf("Expected no ledger mismatches, got %+v %+v", report.Mismatches, report.WalletMismatches)
+	}
+}
@@ -47,6 +47,7 @@ func NewHub(petService *services.PetService) *Hub {
 
 func (h *Hub) Run() {
 	go h.listenToEvents()
+	go h.listenToNotifications()
 	
 	for {
 		select {
@@ -92,6 +93,24 @@ func (h *Hub) listenToEvents() {
 	}
 }
 
+func (h *Hub) listenToNotifications() {
+	notificationCh := h.petService.GetNotificationChannel()
+	for notification := range notificationCh {
+		message := Message{
+			Type: notification.Type,
+			Data: notification,
+		}
+		
+		data, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("Error marshaling notification: %v", err)
+			continue
+		}
+		
+		h.broadcast <- data
+	}
+}
+
 func (h *Hub) HandleWebSocket(c *gin.Context) {
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
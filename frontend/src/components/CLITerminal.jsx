@@ -261,10 +261,10 @@ const CLITerminal = ({ selectedPet, onCommand }) => {
     } else {
       addToHistory('system', '  朋友: 暂无');
     }
-    if (selectedPet.memory && selectedPet.memory.length > 0) {
+    if (selectedPet.memories && selectedPet.memories.length > 0) {
       addToHistory('system', '  最近记忆:');
-      selectedPet.memory.slice(-3).forEach(memory => {
-        addToHistory('system', `    - ${memory}`);
+      selectedPet.memories.slice(-3).forEach(memory => {
+        addToHistory('system', `    - ${memory.summary}`);
       });
     }
   };
@@ -34,13 +34,15 @@ export const useWebSocket = () => {
             const message = JSON.parse(event.data);
             if (message.type === 'event') {
               setEvents(prevEvents => {
-                // 前端去重检查 - 防止重复事件ID
-                const eventExists = prevEvents.some(existingEvent => 
+                // 已有的事件ID是同一事件的更新（如改写后的叙述），原地替换而不是重复追加
+                const index = prevEvents.findIndex(existingEvent => 
                   existingEvent.id === message.data.id
                 );
                 
-                if (eventExists) {
-                  return prevEvents;
+                if (index !== -1) {
+                  const updatedEvents = [...prevEvents];
+                  updatedEvents[index] = message.data;
+                  return updatedEvents;
                 }
                 
                 const newEvents = [...prevEvents, message.data];